@@ -7,9 +7,14 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/acai-travel/tech-challenge/internal/config"
 	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/google/uuid"
+	"github.com/twitchtv/twirp"
 )
 
 func main() {
@@ -28,12 +33,14 @@ func main() {
 		os.Exit(-1)
 	}
 
-	url := "http://localhost:8080"
-	if v := os.Getenv("API_URL"); v != "" {
-		url = v
+	// Only the CLI settings matter here, so the server's secrets are not required.
+	cfg := config.Defaults()
+	if err := cfg.ApplyEnv(); err != nil {
+		fmt.Printf("Error: invalid configuration: %v\n", err)
+		os.Exit(1)
 	}
 
-	cli := pb.NewChatServiceJSONClient(url, http.DefaultClient)
+	cli := pb.NewChatServiceJSONClient(cfg.CLI.APIURL, http.DefaultClient)
 	ctx := context.Background()
 
 	switch os.Args[1] {
@@ -41,7 +48,10 @@ func main() {
 		fmt.Println("Press CMD+C to exit.")
 		fmt.Println()
 
-		cid := ""
+		// lastReply is the ID of the reply /good and /bad rate.
+		cid, lastReply := "", ""
+		// suggestions are the follow-ups to lastReply, sent by typing their number.
+		var suggestions []string
 		if len(os.Args) >= 3 {
 			cid = os.Args[2]
 			resp, err := cli.DescribeConversation(ctx, &pb.DescribeConversationRequest{ConversationId: cid})
@@ -57,7 +67,11 @@ func main() {
 			fmt.Println("")
 			for _, msg := range resp.GetConversation().GetMessages() {
 				fmt.Printf("%s, %s:\n%s\n\n", msg.GetRole(), msg.GetTimestamp().AsTime().Format(time.TimeOnly), msg.GetContent())
+				if msg.GetRole() == pb.Conversation_ASSISTANT {
+					lastReply, suggestions = msg.GetId(), msg.GetSuggestions()
+				}
 			}
+			printSuggestions(suggestions)
 		} else {
 			fmt.Println("Starting a new conversation, type your message below.")
 			fmt.Println()
@@ -75,9 +89,32 @@ func main() {
 
 			fmt.Println()
 
+			if s, ok := pickSuggestion(string(line), suggestions); ok {
+				fmt.Printf("%s\n\n", s)
+				line = []byte(s)
+			}
+
+			if req, ok := parseFeedback(string(line)); ok {
+				if lastReply == "" {
+					fmt.Printf("There is no reply to rate yet.\n\n")
+					continue
+				}
+				req.ConversationId, req.MessageId = cid, lastReply
+				if _, err := cli.SubmitFeedback(ctx, req); err != nil {
+					fmt.Printf("Error submitting feedback: %v\n\n", err)
+					continue
+				}
+				fmt.Printf("Thanks for the feedback!\n\n")
+				continue
+			}
+
 			if cid == "" {
-				out, err := cli.StartConversation(ctx, &pb.StartConversationRequest{
-					Message: string(line),
+				req := &pb.StartConversationRequest{
+					Message:        string(line),
+					IdempotencyKey: uuid.NewString(),
+				}
+				out, err := retry(func() (*pb.StartConversationResponse, error) {
+					return cli.StartConversation(ctx, req)
 				})
 
 				if err != nil {
@@ -90,14 +127,19 @@ func main() {
 				fmt.Println("Title:", out.GetTitle())
 				fmt.Println()
 
-				cid = out.GetConversationId()
+				cid, lastReply, suggestions = out.GetConversationId(), out.GetMessageId(), out.GetSuggestions()
 				fmt.Printf("ASSISTANT:\n%s\n\n", out.GetReply())
+				printSuggestions(suggestions)
 				continue
 			}
 
-			out, err := cli.ContinueConversation(ctx, &pb.ContinueConversationRequest{
+			req := &pb.ContinueConversationRequest{
 				ConversationId: cid,
 				Message:        string(line),
+				IdempotencyKey: uuid.NewString(),
+			}
+			out, err := retry(func() (*pb.ContinueConversationResponse, error) {
+				return cli.ContinueConversation(ctx, req)
 			})
 
 			if err != nil {
@@ -105,7 +147,9 @@ func main() {
 				os.Exit(1)
 			}
 
+			lastReply, suggestions = out.GetMessageId(), out.GetSuggestions()
 			fmt.Printf("ASSISTANT:\n%s\n\n", out.GetReply())
+			printSuggestions(suggestions)
 		}
 
 	case "list":
@@ -148,3 +192,80 @@ func main() {
 		}
 	}
 }
+
+// parseFeedback parses "/good [comment]" and "/bad [reason] [comment]", the
+// reason being one of the Feedback.Reason values, e.g. "/bad inaccurate".
+func parseFeedback(line string) (*pb.SubmitFeedbackRequest, bool) {
+	command, rest, _ := strings.Cut(strings.TrimSpace(line), " ")
+
+	req := &pb.SubmitFeedbackRequest{}
+	switch command {
+	case "/good":
+		req.Rating = pb.Feedback_GOOD
+	case "/bad":
+		req.Rating = pb.Feedback_BAD
+	default:
+		return nil, false
+	}
+
+	rest = strings.TrimSpace(rest)
+	word, comment, _ := strings.Cut(rest, " ")
+	if reason, ok := pb.Feedback_Reason_value[strings.ToUpper(word)]; ok && reason != 0 {
+		req.Reason = pb.Feedback_Reason(reason)
+		rest = strings.TrimSpace(comment)
+	}
+	req.Comment = rest
+	return req, true
+}
+
+// retry calls fn again when it fails with a transient error. Requests carry an
+// idempotency key, so a retry never sends the same message twice.
+func retry[T any](fn func() (T, error)) (T, error) {
+	const attempts = 3
+
+	for attempt := 1; ; attempt++ {
+		out, err := fn()
+
+		twerr, ok := err.(twirp.Error)
+		if err == nil || attempt == attempts || !ok {
+			return out, err
+		}
+
+		wait := time.Duration(attempt) * time.Second
+		switch twerr.Code() {
+		case twirp.Internal, twirp.Unavailable, twirp.DeadlineExceeded:
+		case twirp.ResourceExhausted:
+			if ms, err := strconv.Atoi(twerr.Meta("retry_after_ms")); err == nil {
+				wait = time.Duration(ms) * time.Millisecond
+			}
+		default:
+			return out, err
+		}
+		if wait > 30*time.Second {
+			return out, err
+		}
+
+		fmt.Printf("Request failed (%v), retrying in %s...\n", err, wait.Round(time.Millisecond))
+		time.Sleep(wait)
+	}
+}
+
+func printSuggestions(suggestions []string) {
+	if len(suggestions) == 0 {
+		return
+	}
+	fmt.Println("Type a number to ask:")
+	for i, s := range suggestions {
+		fmt.Printf("  %d. %s\n", i+1, s)
+	}
+	fmt.Println()
+}
+
+// pickSuggestion returns the suggestion numbered line, if any.
+func pickSuggestion(line string, suggestions []string) (string, bool) {
+	n, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil || n < 1 || n > len(suggestions) {
+		return "", false
+	}
+	return suggestions[n-1], true
+}
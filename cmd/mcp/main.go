@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/config"
+	"github.com/acai-travel/tech-challenge/internal/httpx"
+	"github.com/acai-travel/tech-challenge/internal/tools"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func main() {
+	transport := flag.String("transport", "stdio", "MCP transport to serve: stdio or http")
+	addr := flag.String("addr", ":8081", "listen address for the http transport")
+	flag.Parse()
+
+	// Only the tools settings matter here, so the OpenAI key is not required.
+	cfg := config.Defaults()
+	if err := cfg.ApplyEnv(); err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+
+	reg := tools.NewRegistry(
+		tools.WeatherTool{APIKey: string(cfg.Tools.WeatherAPIKey)},
+		tools.TodayTool{},
+		tools.CalendarTool{URL: cfg.Tools.HolidayCalendar},
+		tools.StockTool{Token: string(cfg.Tools.FinnhubToken)},
+	)
+	reg.Timeout = time.Duration(cfg.Tools.Timeout)
+
+	server := tools.NewMCPServer(reg, "v1.0.0")
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch *transport {
+	case "stdio":
+		// stdout carries the protocol, keep logs on stderr.
+		slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, nil)))
+		if err := server.Run(ctx, &mcp.StdioTransport{}); err != nil && ctx.Err() == nil {
+			log.Fatal(err)
+		}
+
+	case "http":
+		handler := http.NewServeMux()
+		handler.Handle("/mcp", httpx.Recovery()(httpx.Logger()(
+			mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil),
+		)))
+
+		srv := &http.Server{Addr: *addr, Handler: handler}
+		go func() {
+			<-ctx.Done()
+			_ = srv.Shutdown(context.Background())
+		}()
+
+		slog.Info("Starting the MCP server...", "addr", *addr, "path", "/mcp")
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+
+	default:
+		log.Fatalf("unknown transport %q, expected stdio or http", *transport)
+	}
+}
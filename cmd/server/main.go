@@ -2,39 +2,195 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"log/slog"
+	"maps"
 	"net/http"
+	"net/netip"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
 
+	"github.com/acai-travel/tech-challenge/internal/admin"
 	"github.com/acai-travel/tech-challenge/internal/chat"
 	"github.com/acai-travel/tech-challenge/internal/chat/assistant"
 	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/config"
+	"github.com/acai-travel/tech-challenge/internal/events"
+	"github.com/acai-travel/tech-challenge/internal/health"
 	"github.com/acai-travel/tech-challenge/internal/httpx"
+	"github.com/acai-travel/tech-challenge/internal/logx"
 	"github.com/acai-travel/tech-challenge/internal/mongox"
 	"github.com/acai-travel/tech-challenge/internal/pb"
 	"github.com/acai-travel/tech-challenge/internal/telemetry"
+	"github.com/acai-travel/tech-challenge/internal/tools"
+	"github.com/acai-travel/tech-challenge/internal/webhook"
 	"github.com/gorilla/mux"
+	"github.com/openai/openai-go/v2/option"
 	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 func main() {
-	mongo := mongox.MustConnect()
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "path to a JSON configuration file")
+	printConfig := flag.Bool("print-config", false, "print the effective configuration, secrets redacted, and exit")
+	flag.Parse()
+
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+	if *printConfig {
+		fmt.Println(cfg.Redacted())
+		return
+	}
+
+	slog.SetDefault(logx.New(os.Stderr, cfg.Log.Format, cfg.Log.LogLevel()))
+	slog.Debug("Configuration loaded", "config", cfg.Redacted())
+
+	mongo := mongox.MustConnect(cfg.Mongo)
 
 	repo := model.New(mongo)
-	assist := assistant.New()
+	if err := repo.EnsureIndexes(context.Background()); err != nil {
+		slog.Error("Failed to create MongoDB indexes", "error", err)
+	}
+
+	reg := tools.NewRegistry(
+		tools.TodayTool{},
+		tools.CalendarTool{URL: cfg.Tools.HolidayCalendar},
+	)
+	reg.Timeout = time.Duration(cfg.Tools.Timeout)
+
+	// Tools whose secret is missing are left out rather than failing every call.
+	if key := string(cfg.Tools.WeatherAPIKey); key != "" {
+		_ = reg.Register(tools.WeatherTool{APIKey: key})
+	} else {
+		slog.Warn("WEATHER_API_KEY not set, get_weather is disabled")
+	}
+	if token := string(cfg.Tools.FinnhubToken); token != "" {
+		_ = reg.Register(tools.StockTool{Token: token})
+	} else {
+		slog.Warn("FINNHUB_TOKEN not set, get_stock_quote is disabled")
+	}
+
+	// Declarative REST tools, see README.
+	if path := cfg.Tools.HTTPToolsFile; path != "" {
+		declared, err := tools.LoadHTTPTools(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, t := range declared {
+			if err := reg.Register(t); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+
+	// External tools served over the Model Context Protocol, see README.
+	if path := cfg.Tools.MCPServersFile; path != "" {
+		servers, err := tools.LoadMCPServers(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := reg.AddMCPServers(context.Background(), servers); err != nil {
+			slog.Error("Failed to load some MCP tools", "error", err)
+		}
+	}
+
+	opts := []option.RequestOption{option.WithAPIKey(string(cfg.OpenAI.APIKey))}
+	if cfg.OpenAI.BaseURL != "" {
+		opts = append(opts, option.WithBaseURL(cfg.OpenAI.BaseURL))
+	}
+	assist := assistant.New(cfg.Assistant, reg, opts...)
+	assist.Personas = repo
 
 	server := chat.NewServer(repo, assist)
-	shutdown, err := telemetry.Init(context.Background())
+	server.Workers = cfg.Assistant.Workers
+	server.MaxQueued = cfg.Assistant.MaxQueued
+	server.AllowedModels = append([]string{cfg.Assistant.ReplyModel}, cfg.Assistant.AllowedModels...)
+	server.RetitleAfter = cfg.Assistant.RetitleAfter
+
+	// Webhooks notifying other systems of conversation events, see README.
+	var subscriptions []webhook.Subscription
+	if path := cfg.Webhooks.SubscriptionsFile; path != "" {
+		subscriptions, err = webhook.LoadSubscriptions(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	webhooks := webhook.NewDispatcher(repo, subscriptions...)
+	webhooks.MaxAttempts = cfg.Webhooks.MaxAttempts
+	webhooks.Backoff = time.Duration(cfg.Webhooks.Backoff)
+	webhooks.MaxBackoff = time.Duration(cfg.Webhooks.MaxBackoff)
+	webhooks.Timeout = time.Duration(cfg.Webhooks.Timeout)
+
+	// Changes to conversations are relayed from the outbox to the sinks. The bus
+	// is where in-process consumers subscribe.
+	bus := events.NewBus()
+	var sinks []events.Sink
+	for _, name := range cfg.Events.Sinks {
+		switch name {
+		case "log":
+			sinks = append(sinks, events.LogSink{})
+		case "webhook":
+			sinks = append(sinks, events.WebhookSink{Webhooks: webhooks})
+		case "bus":
+			sinks = append(sinks, events.BusSink{Publisher: bus})
+		}
+	}
+	relay := events.NewRelay(repo, sinks...)
+	relay.Interval = time.Duration(cfg.Events.PollInterval)
+	tel, err := telemetry.Init(context.Background(), otelConfig(cfg.Telemetry))
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer shutdown(context.Background())
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Dependency checks. Upstreams of OpenAI and the tools are always reported in
+	// /status, but only gate readiness when configured to.
+	checkUpstreams := cfg.Health.CheckUpstreams
+	checks := []health.Check{
+		{Name: "mongodb", Required: true, Run: func(ctx context.Context) error {
+			return mongo.Client().Ping(ctx, readpref.Primary())
+		}},
+		{Name: "openai", Required: checkUpstreams, Run: assist.Ping},
+	}
+	upstreams := reg.Upstreams()
+	for _, name := range slices.Sorted(maps.Keys(upstreams)) {
+		checks = append(checks, health.Check{Name: name, Required: checkUpstreams, Run: upstreams[name]})
+	}
+
+	var toolNames []string
+	for _, t := range reg.Tools() {
+		toolNames = append(toolNames, t.Name())
+	}
+
+	checker := health.NewChecker(health.Info{Version: cfg.Telemetry.ServiceVersion, Tools: toolNames}, checks...)
+	checker.Start(ctx)
+
+	// The relay outlives the server, to relay the changes of the last requests.
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	defer stopRelay()
+	relay.Start(relayCtx)
+
+	// Validated with the configuration.
+	var trustedProxies []netip.Prefix
+	for _, p := range cfg.Server.TrustedProxies {
+		trustedProxies = append(trustedProxies, netip.MustParsePrefix(p))
+	}
 
 	// Configure handler
 	handler := mux.NewRouter()
 	handler.Use(
+		httpx.RequestID(),
+		httpx.Identity(trustedProxies...),
 		httpx.Logger(),
 		httpx.Recovery(),
 	)
@@ -43,7 +199,50 @@ func main() {
 		_, _ = fmt.Fprint(w, "Hi, my name is Clippy!")
 	})
 
-	handler.PathPrefix("/twirp/").Handler(pb.NewChatServiceServer(server, twirp.WithServerJSONSkipDefaults(true)))
+	handler.HandleFunc("/healthz", checker.Healthz)
+	handler.HandleFunc("/readyz", checker.Readyz)
+	handler.HandleFunc("/status", checker.Status)
+
+	if tel.MetricsHandler != nil {
+		handler.Handle("/metrics", tel.MetricsHandler)
+	}
+
+	// WatchConversation streams changes as server-sent events, see README.
+	var watch http.Handler = server.Watch(ctx)
+	if l := cfg.Limits; l.RequestsPerSecond > 0 {
+		watch = httpx.RateLimit(l.RequestsPerSecond, l.Burst)(watch)
+	}
+	handler.Handle("/conversations/{id}/watch", watch).Methods(http.MethodGet)
+
+	// The admin API is only served when a token is configured.
+	if token := string(cfg.Admin.Token); token != "" {
+		adminServer := admin.NewServer(webhooks, repo)
+		adminAPI := pb.NewAdminServiceServer(adminServer,
+			twirp.WithServerJSONSkipDefaults(true),
+			twirp.WithServerHooks(twirp.ChainHooks(httpx.TwirpHooks(), telemetry.TwirpHooks())),
+		)
+		handler.PathPrefix(adminAPI.PathPrefix()).Handler(httpx.BearerToken(token)(adminAPI))
+		handler.Handle("/admin/feedback.jsonl", httpx.BearerToken(token)(adminServer.ExportFeedback())).Methods(http.MethodGet)
+	}
+
+	var api http.Handler = pb.NewChatServiceServer(server,
+		twirp.WithServerJSONSkipDefaults(true),
+		twirp.WithServerHooks(twirp.ChainHooks(httpx.TwirpHooks(), telemetry.TwirpHooks())),
+	)
+
+	// Limits, innermost first: RPCs calling the assistant are queued when too many
+	// are in flight, after checking the client's daily token quota.
+	callsAssistant := httpx.MatchPathSuffix("/StartConversation", "/ContinueConversation", "/RetryMessage", "/RegenerateTitle")
+	if l := cfg.Limits; l.MaxInFlight > 0 {
+		api = httpx.ConcurrencyLimit(l.MaxInFlight, l.MaxQueued, time.Duration(l.QueueTimeout), callsAssistant)(api)
+	}
+	if l := cfg.Limits; l.DailyTokens > 0 {
+		api = httpx.TokenQuota(repo, l.DailyTokens, l.ReservedTokens, callsAssistant)(api)
+	}
+	if l := cfg.Limits; l.RequestsPerSecond > 0 {
+		api = httpx.RateLimit(l.RequestsPerSecond, l.Burst)(api)
+	}
+	handler.PathPrefix("/twirp/").Handler(api)
 	traced := otelhttp.NewHandler(
 		handler,
 		"http.server",
@@ -52,9 +251,90 @@ func main() {
 		}),
 	)
 
-	// Start the server
-	slog.Info("Starting the server...")
-	if err := http.ListenAndServe(":8080", traced); err != nil {
-		panic(err)
+	// On SIGINT or SIGTERM, report the server as not ready first and give load
+	// balancers the drain delay to stop routing to it, then shut it down.
+	serveCtx, shutdown := context.WithCancel(context.Background())
+	defer shutdown()
+	go func() {
+		<-ctx.Done()
+		checker.Drain()
+		slog.Info("Draining before shutting down the server...", "delay", time.Duration(cfg.Server.DrainDelay))
+		time.Sleep(time.Duration(cfg.Server.DrainDelay))
+		shutdown()
+	}()
+
+	// Start the server, until shut down.
+	slog.Info("Starting the server...", "addr", cfg.Server.Addr, "tls", cfg.Server.TLSCertFile != "")
+	serveErr := httpx.Serve(serveCtx, serverConfig(cfg.Server), traced)
+
+	// In-flight requests are done. Give replies generated in the background as
+	// long as requests had, those still running past it are left pending, to be
+	// retried.
+	wctx, cancelWait := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeout))
+	defer cancelWait()
+	if err := server.Wait(wctx); err != nil {
+		slog.Error("Stopped before background replies completed", "error", err)
+	}
+
+	// Events not relayed yet stay in the outbox until the next start.
+	stopRelay()
+	<-relay.Done()
+	if err := webhooks.Close(wctx); err != nil {
+		slog.Error("Stopped before webhooks were delivered, stored as failed", "error", err)
+	}
+
+	// Release everything requests and replies might have used.
+	sctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := reg.Close(); err != nil {
+		slog.Error("Failed to close MCP sessions", "error", err)
+	}
+	if err := tel.Shutdown(sctx); err != nil {
+		slog.Error("Failed to flush telemetry", "error", err)
+	}
+	if err := mongo.Client().Disconnect(sctx); err != nil {
+		slog.Error("Failed to disconnect from MongoDB", "error", err)
+	}
+
+	if serveErr != nil {
+		log.Fatal(serveErr)
+	}
+	slog.Info("Server stopped")
+}
+
+// serverConfig returns the settings of the HTTP server.
+func serverConfig(s config.Server) httpx.ServerConfig {
+	return httpx.ServerConfig{
+		Addr:              s.Addr,
+		ReadHeaderTimeout: time.Duration(s.ReadHeaderTimeout),
+		ReadTimeout:       time.Duration(s.ReadTimeout),
+		WriteTimeout:      time.Duration(s.WriteTimeout),
+		IdleTimeout:       time.Duration(s.IdleTimeout),
+		ShutdownTimeout:   time.Duration(s.ShutdownTimeout),
+		TLSCertFile:       s.TLSCertFile,
+		TLSKeyFile:        s.TLSKeyFile,
+	}
+}
+
+// otelConfig returns the settings of the OpenTelemetry exporters. "console", the
+// name used by the OpenTelemetry specification, is accepted for stdout.
+func otelConfig(t config.Telemetry) telemetry.Config {
+	console := func(e string) string {
+		if e == "console" {
+			return telemetry.ExporterStdout
+		}
+		return e
+	}
+	return telemetry.Config{
+		ServiceName:     t.ServiceName,
+		ServiceVersion:  t.ServiceVersion,
+		Environment:     t.Environment,
+		TracesExporter:  console(t.TracesExporter),
+		MetricsExporter: console(t.MetricsExporter),
+		OTLPProtocol:    t.OTLPProtocol,
+		OTLPEndpoint:    t.OTLPEndpoint,
+		Sampler:         t.Sampler,
+		SampleRatio:     t.SampleRatio,
 	}
 }
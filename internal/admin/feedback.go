@@ -0,0 +1,117 @@
+package admin
+
+import (
+	"bufio"
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/twitchtv/twirp"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func (s *Server) ListFeedback(ctx context.Context, req *pb.ListFeedbackRequest) (*pb.ListFeedbackResponse, error) {
+	limit, err := pageSize(req.GetLimit())
+	if err != nil {
+		return nil, err
+	}
+
+	filter := model.FeedbackFilter{
+		Rating:    model.RatingFromProto(req.GetRating()),
+		Tool:      req.GetTool(),
+		UsedTools: req.UsedTools,
+	}
+	if req.GetSince() != nil {
+		filter.Since = req.GetSince().AsTime()
+	}
+	if req.GetUntil() != nil {
+		filter.Until = req.GetUntil().AsTime()
+	}
+
+	feedback, err := s.store.ListFeedback(ctx, filter, limit)
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	resp := &pb.ListFeedbackResponse{}
+	for _, f := range feedback {
+		resp.Feedback = append(resp.Feedback, f.Proto())
+	}
+	return resp, nil
+}
+
+// ExportFeedback serves the feedback as JSON Lines, oldest first, for offline
+// evaluation. It is filtered like ListFeedback, by the query parameters since
+// and until (RFC 3339), rating (good or bad), tool and used_tools (a boolean).
+func (s *Server) ExportFeedback() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		filter, err := exportFilter(r)
+		if err != nil {
+			_ = twirp.WriteError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/jsonl")
+		out := bufio.NewWriter(w)
+		written := 0
+
+		err = s.store.EachFeedback(ctx, filter, func(f *model.Feedback) error {
+			line, err := protojson.Marshal(f.Proto())
+			if err != nil {
+				return err
+			}
+			n, err := out.Write(append(line, '\n'))
+			written += n
+			return err
+		})
+		if err == nil {
+			err = out.Flush()
+		}
+		if err == nil {
+			return
+		}
+
+		slog.ErrorContext(ctx, "Failed to export feedback", "error", err)
+		// Until the buffer is first flushed, nothing is sent and the failure can
+		// be reported. Past that, the export is cut short.
+		if out.Buffered() == written {
+			_ = twirp.WriteError(w, twirp.InternalErrorWith(err))
+		}
+	})
+}
+
+func exportFilter(r *http.Request) (model.FeedbackFilter, error) {
+	q := r.URL.Query()
+	filter := model.FeedbackFilter{Tool: q.Get("tool")}
+
+	for param, t := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if v := q.Get(param); v != "" {
+			var err error
+			if *t, err = time.Parse(time.RFC3339, v); err != nil {
+				return filter, twirp.InvalidArgumentError(param, "must be an RFC 3339 time")
+			}
+		}
+	}
+
+	switch rating := model.Rating(q.Get("rating")); rating {
+	case "", model.RatingGood, model.RatingBad:
+		filter.Rating = rating
+	default:
+		return filter, twirp.InvalidArgumentError("rating", "must be good or bad")
+	}
+
+	if v := q.Get("used_tools"); v != "" {
+		used, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, twirp.InvalidArgumentError("used_tools", "must be true or false")
+		}
+		filter.UsedTools = &used
+	}
+	return filter, nil
+}
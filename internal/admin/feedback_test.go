@@ -0,0 +1,108 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/google/go-cmp/cmp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+type fakeFeedback struct {
+	Store
+
+	filter   model.FeedbackFilter
+	feedback []*model.Feedback
+	err      error
+}
+
+func (f *fakeFeedback) ListFeedback(_ context.Context, filter model.FeedbackFilter, _ int) ([]*model.Feedback, error) {
+	f.filter = filter
+	return f.feedback, nil
+}
+
+func (f *fakeFeedback) EachFeedback(_ context.Context, filter model.FeedbackFilter, fn func(*model.Feedback) error) error {
+	f.filter = filter
+	if f.err != nil {
+		return f.err
+	}
+	for _, fb := range f.feedback {
+		if err := fn(fb); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestServer_ExportFeedback(t *testing.T) {
+	store := &fakeFeedback{feedback: []*model.Feedback{
+		{MessageID: primitive.NewObjectID(), Rating: model.RatingGood, Reply: "Sunny"},
+		{MessageID: primitive.NewObjectID(), Rating: model.RatingBad, Reason: model.ReasonInaccurate, Tools: []string{"get_weather"}},
+	}}
+	export := NewServer(nil, store).ExportFeedback()
+
+	t.Run("one feedback per line", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		export.ServeHTTP(rec, httptest.NewRequest(http.MethodGet,
+			"/admin/feedback.jsonl?since=2025-08-01T00:00:00Z&rating=bad&tool=get_weather&used_tools=true", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status: got %d, want %d", rec.Code, http.StatusOK)
+		}
+
+		lines := strings.Split(strings.TrimSuffix(rec.Body.String(), "\n"), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("lines: got %d, want 2:\n%s", len(lines), rec.Body)
+		}
+		var got pb.Feedback
+		if err := protojson.Unmarshal([]byte(lines[1]), &got); err != nil {
+			t.Fatalf("line 2: %v", err)
+		}
+		if got.GetRating() != pb.Feedback_BAD || got.GetReason() != pb.Feedback_INACCURATE {
+			t.Fatalf("line 2: got %v", &got)
+		}
+
+		used := true
+		want := model.FeedbackFilter{
+			Since:     time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC),
+			Rating:    model.RatingBad,
+			Tool:      "get_weather",
+			UsedTools: &used,
+		}
+		if diff := cmp.Diff(store.filter, want); diff != "" {
+			t.Fatalf("filter mismatch (-got +want):\n%s", diff)
+		}
+	})
+
+	t.Run("failure before the first line", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		NewServer(nil, &fakeFeedback{err: errors.New("database down")}).ExportFeedback().
+			ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/feedback.jsonl", nil))
+
+		if rec.Code != http.StatusInternalServerError {
+			t.Fatalf("status: got %d, want %d", rec.Code, http.StatusInternalServerError)
+		}
+		if got, want := rec.Header().Get("Content-Type"), "application/json"; got != want {
+			t.Fatalf("content type: got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		for _, query := range []string{"since=yesterday", "rating=meh", "used_tools=maybe"} {
+			rec := httptest.NewRecorder()
+			export.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/feedback.jsonl?"+query, nil))
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("%s: got status %d, want %d", query, rec.Code, http.StatusBadRequest)
+			}
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package admin
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/twitchtv/twirp"
+)
+
+var personaName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+func (s *Server) ListPersonas(ctx context.Context, req *pb.ListPersonasRequest) (*pb.ListPersonasResponse, error) {
+	personas, err := s.store.ListPersonas(ctx)
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	resp := &pb.ListPersonasResponse{}
+	for _, p := range personas {
+		resp.Personas = append(resp.Personas, p.Proto())
+	}
+	return resp, nil
+}
+
+func (s *Server) CreatePersona(ctx context.Context, req *pb.CreatePersonaRequest) (*pb.CreatePersonaResponse, error) {
+	p, err := personaFromProto(req.GetPersona())
+	if err != nil {
+		return nil, err
+	}
+	p.CreatedAt = p.UpdatedAt
+
+	if err := s.store.CreatePersona(ctx, p); err != nil {
+		return nil, internal(err)
+	}
+	return &pb.CreatePersonaResponse{Persona: p.Proto()}, nil
+}
+
+func (s *Server) UpdatePersona(ctx context.Context, req *pb.UpdatePersonaRequest) (*pb.UpdatePersonaResponse, error) {
+	p, err := personaFromProto(req.GetPersona())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.UpdatePersona(ctx, p); err != nil {
+		return nil, internal(err)
+	}
+	return &pb.UpdatePersonaResponse{Persona: p.Proto()}, nil
+}
+
+func (s *Server) DeletePersona(ctx context.Context, req *pb.DeletePersonaRequest) (*pb.DeletePersonaResponse, error) {
+	if req.GetName() == "" {
+		return nil, twirp.RequiredArgumentError("name")
+	}
+
+	if err := s.store.DeletePersona(ctx, req.GetName()); err != nil {
+		return nil, internal(err)
+	}
+	return &pb.DeletePersonaResponse{}, nil
+}
+
+func personaFromProto(p *pb.Persona) (*model.Persona, error) {
+	switch {
+	case p == nil:
+		return nil, twirp.RequiredArgumentError("persona")
+	case !personaName.MatchString(p.GetName()):
+		return nil, twirp.InvalidArgumentError("persona.name", "must be lowercase letters, digits, dashes and underscores")
+	case strings.TrimSpace(p.GetSystemPrompt()) == "":
+		return nil, twirp.RequiredArgumentError("persona.system_prompt")
+	case p.Temperature != nil && (p.GetTemperature() < 0 || p.GetTemperature() > 2):
+		return nil, twirp.InvalidArgumentError("persona.temperature", "must be between 0 and 2")
+	}
+
+	return &model.Persona{
+		Name:         p.GetName(),
+		Description:  p.GetDescription(),
+		SystemPrompt: p.GetSystemPrompt(),
+		Model:        p.GetModel(),
+		Temperature:  p.Temperature,
+		Tools:        p.GetTools(),
+		UpdatedAt:    time.Now(),
+	}, nil
+}
+
+// internal wraps err as an internal error, unless it is a Twirp error already.
+func internal(err error) error {
+	if _, ok := err.(twirp.Error); ok {
+		return err
+	}
+	return twirp.InternalErrorWith(err)
+}
@@ -0,0 +1,100 @@
+// Package admin implements the AdminService, operating the service itself
+// rather than conversations.
+package admin
+
+import (
+	"context"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/acai-travel/tech-challenge/internal/webhook"
+	"github.com/twitchtv/twirp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var _ pb.AdminService = (*Server)(nil)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+// Store reads the feedback given on replies and manages personas.
+type Store interface {
+	ListFeedback(ctx context.Context, filter model.FeedbackFilter, limit int) ([]*model.Feedback, error)
+	EachFeedback(ctx context.Context, filter model.FeedbackFilter, fn func(*model.Feedback) error) error
+
+	ListPersonas(ctx context.Context) ([]*model.Persona, error)
+	CreatePersona(ctx context.Context, p *model.Persona) error
+	UpdatePersona(ctx context.Context, p *model.Persona) error
+	DeletePersona(ctx context.Context, name string) error
+}
+
+type Server struct {
+	webhooks *webhook.Dispatcher
+	store    Store
+}
+
+func NewServer(webhooks *webhook.Dispatcher, store Store) *Server {
+	return &Server{webhooks: webhooks, store: store}
+}
+
+func (s *Server) ListWebhookSubscriptions(ctx context.Context, req *pb.ListWebhookSubscriptionsRequest) (*pb.ListWebhookSubscriptionsResponse, error) {
+	resp := &pb.ListWebhookSubscriptionsResponse{}
+	for _, sub := range s.webhooks.Subscriptions() {
+		out := &pb.WebhookSubscription{Id: sub.ID, Url: sub.URL}
+		for _, e := range sub.Events {
+			out.Events = append(out.Events, string(e))
+		}
+		resp.Subscriptions = append(resp.Subscriptions, out)
+	}
+	return resp, nil
+}
+
+func (s *Server) ListFailedDeliveries(ctx context.Context, req *pb.ListFailedDeliveriesRequest) (*pb.ListFailedDeliveriesResponse, error) {
+	limit, err := pageSize(req.GetLimit())
+	if err != nil {
+		return nil, err
+	}
+
+	failed, err := s.webhooks.FailedDeliveries(ctx, req.GetSubscriptionId(), limit)
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	resp := &pb.ListFailedDeliveriesResponse{}
+	for _, d := range failed {
+		resp.Deliveries = append(resp.Deliveries, &pb.FailedDelivery{
+			Id:             d.ID.Hex(),
+			SubscriptionId: d.SubscriptionID,
+			EventId:        d.EventID,
+			EventType:      d.EventType,
+			Attempts:       int32(d.Attempts),
+			LastError:      d.LastError,
+			Timestamp:      timestamppb.New(d.UpdatedAt),
+		})
+	}
+	return resp, nil
+}
+
+func (s *Server) ReplayFailedDelivery(ctx context.Context, req *pb.ReplayFailedDeliveryRequest) (*pb.ReplayFailedDeliveryResponse, error) {
+	if req.GetId() == "" {
+		return nil, twirp.RequiredArgumentError("id")
+	}
+
+	if err := s.webhooks.Replay(ctx, req.GetId()); err != nil {
+		return nil, err
+	}
+	return &pb.ReplayFailedDeliveryResponse{}, nil
+}
+
+func pageSize(limit int32) (int, error) {
+	switch {
+	case limit == 0:
+		return defaultLimit, nil
+	case limit < 0 || limit > maxLimit:
+		return 0, twirp.InvalidArgumentError("limit", "must be between 1 and 100")
+	default:
+		return int(limit), nil
+	}
+}
@@ -4,20 +4,50 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
 
 	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/config"
+	"github.com/acai-travel/tech-challenge/internal/logx"
 	"github.com/acai-travel/tech-challenge/internal/tools"
 	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+	"github.com/openai/openai-go/v2/packages/param"
+	"github.com/twitchtv/twirp"
 )
 
+// defaultSystemPrompt instructs the model in conversations without a persona.
+const defaultSystemPrompt = "You are a helpful, concise AI assistant. Provide accurate, safe, and clear responses."
+
+// PersonaStore resolves the persona of a conversation.
+type PersonaStore interface {
+	GetPersona(ctx context.Context, name string) (*model.Persona, error)
+}
+
 type Assistant struct {
-	cli openai.Client
+	cli   openai.Client
+	cfg   config.Assistant
+	tools *tools.Registry
+
+	// Personas resolves the personas conversations are started with. Replies
+	// use the defaults when nil.
+	Personas PersonaStore
 }
 
-func New() *Assistant {
-	return &Assistant{cli: openai.NewClient()}
+// New creates an assistant using the given models and tools. Request options are
+// passed to the OpenAI client, e.g. option.WithAPIKey, or option.WithHTTPClient
+// and option.WithBaseURL in tests.
+func New(cfg config.Assistant, reg *tools.Registry, opts ...option.RequestOption) *Assistant {
+	return &Assistant{cli: openai.NewClient(opts...), cfg: cfg, tools: reg}
+}
+
+// Ping checks that the OpenAI API is reachable and the API key accepted.
+func (a *Assistant) Ping(ctx context.Context) error {
+	_, err := a.cli.Models.Get(ctx, a.cfg.ReplyModel)
+	return err
 }
 
 func (a *Assistant) Title(ctx context.Context, conv *model.Conversation) (string, error) {
@@ -25,7 +55,8 @@ func (a *Assistant) Title(ctx context.Context, conv *model.Conversation) (string
 		return "An empty conversation", nil
 	}
 
-	slog.InfoContext(ctx, "Generating title for conversation", "conversation_id", conv.ID)
+	ctx = logx.WithConversationID(ctx, conv.ID.Hex())
+	slog.InfoContext(ctx, "Generating title for conversation")
 
 	msgs := make([]openai.ChatCompletionMessageParamUnion, 0, 2)
 
@@ -34,15 +65,58 @@ func (a *Assistant) Title(ctx context.Context, conv *model.Conversation) (string
 	msgs = append(msgs, openai.SystemMessage("Generate a concise, descriptive title for the conversation based on the user message. The title should be a single line, no more than 80 characters, and should not include any special characters or emojis."))
 	msgs = append(msgs, openai.UserMessage(conv.Messages[0].Content))
 
-	resp, err := a.cli.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
-		Model:    openai.ChatModelO1,
-		Messages: msgs,
-	})
+	params := openai.ChatCompletionNewParams{Model: a.cfg.TitleModel, Messages: msgs}
+	configure(&params, defaults(a.cfg.TitleParams))
+
+	resp, err := a.complete(ctx, "title", conv, 0, params)
 
 	if err != nil {
 		return "", err
 	}
 
+	return titleFrom(resp)
+}
+
+// retitleContext is how many of the last messages titles are revised from.
+const retitleContext = 10
+
+// Retitle titles the conversation from its latest messages, as its topic may
+// have drifted from the first one. The current title, if any, is returned
+// unchanged while it still fits.
+func (a *Assistant) Retitle(ctx context.Context, conv *model.Conversation) (string, error) {
+	if len(conv.Messages) == 0 {
+		return "An empty conversation", nil
+	}
+
+	ctx = logx.WithConversationID(ctx, conv.ID.Hex())
+	slog.InfoContext(ctx, "Revising title of conversation")
+
+	prompt := "Generate a concise, descriptive title for the conversation transcribed by the user message, based on what it is mostly about. The title should be a single line, no more than 80 characters, and should not include any special characters or emojis."
+	if conv.Title != "" {
+		prompt += fmt.Sprintf(" The conversation is currently titled %q: answer with that title unchanged if it still fits.", conv.Title)
+	}
+
+	var transcript strings.Builder
+	for _, m := range conv.Messages[max(0, len(conv.Messages)-retitleContext):] {
+		fmt.Fprintf(&transcript, "%s: %s\n\n", m.Role, m.Content)
+	}
+
+	params := openai.ChatCompletionNewParams{
+		Model:    a.cfg.TitleModel,
+		Messages: []openai.ChatCompletionMessageParamUnion{openai.SystemMessage(prompt), openai.UserMessage(transcript.String())},
+	}
+	configure(&params, defaults(a.cfg.TitleParams))
+
+	resp, err := a.complete(ctx, "retitle", conv, 0, params)
+	if err != nil {
+		return "", err
+	}
+	return titleFrom(resp)
+}
+
+// titleFrom returns the title generated in resp, on a single line of up to 80
+// characters.
+func titleFrom(resp *openai.ChatCompletion) (string, error) {
 	if len(resp.Choices) == 0 || strings.TrimSpace(resp.Choices[0].Message.Content) == "" {
 		return "", errors.New("empty response from OpenAI for title generation")
 	}
@@ -58,15 +132,38 @@ func (a *Assistant) Title(ctx context.Context, conv *model.Conversation) (string
 	return title, nil
 }
 
-func (a *Assistant) Reply(ctx context.Context, conv *model.Conversation) (string, error) {
+// Reply generates the next message of the conversation. The parameters of the
+// persona of the conversation, then gen if not nil, override the defaults.
+func (a *Assistant) Reply(ctx context.Context, conv *model.Conversation, gen *model.Generation) (string, error) {
 	if len(conv.Messages) == 0 {
 		return "", errors.New("conversation has no messages")
 	}
 
-	slog.InfoContext(ctx, "Generating reply for conversation", "conversation_id", conv.ID)
+	ctx = logx.WithConversationID(ctx, conv.ID.Hex())
+	slog.InfoContext(ctx, "Generating reply for conversation")
+
+	params := openai.ChatCompletionNewParams{Model: a.cfg.ReplyModel}
+	layers := []model.Generation{defaults(a.cfg.ReplyParams)}
+	prompt := defaultSystemPrompt
+	var allowed []string
+
+	p, err := a.persona(ctx, conv)
+	if err != nil {
+		return "", err
+	}
+	if p != nil {
+		prompt = p.SystemPrompt
+		layers = append(layers, model.Generation{Model: p.Model, Temperature: p.Temperature})
+		allowed = p.Tools
+	}
+	if gen != nil {
+		layers = append(layers, *gen)
+	}
+	configure(&params, layers...)
+	params.Tools = a.tools.ToolsForOpenAI(allowed...)
 
 	msgs := []openai.ChatCompletionMessageParamUnion{
-		openai.SystemMessage("You are a helpful, concise AI assistant. Provide accurate, safe, and clear responses."),
+		openai.SystemMessage(prompt),
 	}
 
 	for _, m := range conv.Messages {
@@ -78,19 +175,9 @@ func (a *Assistant) Reply(ctx context.Context, conv *model.Conversation) (string
 		}
 	}
 
-	reg := tools.NewRegistry(
-		tools.WeatherTool{},
-		tools.TodayTool{},
-		tools.CalendarTool{},
-		tools.StockTool{},
-	)
-
-	for i := 0; i < 15; i++ {
-		resp, err := a.cli.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
-			Model:    openai.ChatModelGPT4_1,
-			Messages: msgs,
-			Tools:    reg.ToolsForOpenAI(),
-		})
+	for i := 0; i < a.cfg.MaxToolIterations; i++ {
+		params.Messages = msgs
+		resp, err := a.complete(ctx, "reply", conv, i, params)
 
 		if err != nil {
 			return "", err
@@ -106,8 +193,10 @@ func (a *Assistant) Reply(ctx context.Context, conv *model.Conversation) (string
 			for _, call := range message.ToolCalls {
 				slog.InfoContext(ctx, "Tool call received", "name", call.Function.Name, "args", call.Function.Arguments)
 
-				out, err := reg.Dispatch(ctx, call.Function.Name, json.RawMessage(call.Function.Arguments))
-				if err != nil {
+				var out string
+				if len(allowed) > 0 && !slices.Contains(allowed, call.Function.Name) {
+					out = fmt.Sprintf("tool %s is not available", call.Function.Name)
+				} else if out, err = a.tools.Dispatch(ctx, call.Function.Name, json.RawMessage(call.Function.Arguments)); err != nil {
 					out = err.Error()
 				}
 				msgs = append(msgs, openai.ToolMessage(out, call.ID))
@@ -121,3 +210,57 @@ func (a *Assistant) Reply(ctx context.Context, conv *model.Conversation) (string
 
 	return "", errors.New("too many tool calls, unable to generate reply")
 }
+
+// configure sets the parameters of a completion that the layers set, each
+// overriding the previous ones. Those the resulting model rejects are dropped,
+// as the defaults apply to reasoning and other models alike.
+func configure(params *openai.ChatCompletionNewParams, layers ...model.Generation) {
+	for _, g := range layers {
+		if g.Model != "" {
+			params.Model = g.Model
+		}
+		if g.Temperature != nil {
+			params.Temperature = openai.Float(*g.Temperature)
+		}
+		if g.MaxOutputTokens > 0 {
+			params.MaxCompletionTokens = openai.Int(g.MaxOutputTokens)
+		}
+		if g.ReasoningEffort != "" {
+			params.ReasoningEffort = openai.ReasoningEffort(g.ReasoningEffort)
+		}
+	}
+
+	if model.ReasoningModel(params.Model) {
+		params.Temperature = param.Opt[float64]{}
+	} else {
+		params.ReasoningEffort = ""
+	}
+}
+
+func defaults(g config.Generation) model.Generation {
+	return model.Generation{
+		Temperature:     g.Temperature,
+		MaxOutputTokens: g.MaxOutputTokens,
+		ReasoningEffort: g.ReasoningEffort,
+	}
+}
+
+// persona returns the persona of the conversation, or nil for the defaults.
+// Replies are refused rather than falling back to the defaults when it cannot
+// be loaded, including when it was deleted since, as that would lift the tool
+// restrictions of the persona.
+func (a *Assistant) persona(ctx context.Context, conv *model.Conversation) (*model.Persona, error) {
+	if conv.Persona == "" || a.Personas == nil {
+		return nil, nil
+	}
+
+	p, err := a.Personas.GetPersona(ctx, conv.Persona)
+	if te, ok := err.(twirp.Error); ok && te.Code() == twirp.NotFound {
+		slog.WarnContext(ctx, "Persona not found, refusing to reply", "persona", conv.Persona)
+		return nil, twirp.NewError(twirp.FailedPrecondition, fmt.Sprintf("persona %q no longer exists", conv.Persona))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load persona %q: %w", conv.Persona, err)
+	}
+	return p, nil
+}
@@ -0,0 +1,459 @@
+package assistant
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	chattesting "github.com/acai-travel/tech-challenge/internal/chat/testing"
+	"github.com/acai-travel/tech-challenge/internal/config"
+	"github.com/acai-travel/tech-challenge/internal/recorder"
+	"github.com/acai-travel/tech-challenge/internal/tools"
+	"github.com/google/go-cmp/cmp"
+	"github.com/openai/openai-go/v2/option"
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tests replay cassettes from testdata/cassettes. To re-record them against the
+// real APIs, run with RECORD_CASSETTES=1 and OPENAI_API_KEY/WEATHER_API_KEY set.
+
+func newTestAssistant(t *testing.T, rec *recorder.Recorder) *Assistant {
+	if os.Getenv("OPENAI_API_KEY") == "" {
+		t.Setenv("OPENAI_API_KEY", "test")
+	}
+
+	reg := tools.NewRegistry(
+		tools.WeatherTool{APIKey: os.Getenv("WEATHER_API_KEY"), HTTPClient: rec.Client()},
+		tools.TodayTool{},
+	)
+
+	return New(config.Defaults().Assistant, reg, option.WithHTTPClient(rec.Client()))
+}
+
+func conversation(msg string) *model.Conversation {
+	return &model.Conversation{
+		ID: primitive.NewObjectID(),
+		Messages: []*model.Message{{
+			ID:      primitive.NewObjectID(),
+			Role:    model.RoleUser,
+			Content: msg,
+		}},
+	}
+}
+
+func TestAssistant_Title(t *testing.T) {
+	a := newTestAssistant(t, recorder.ForTest(t, "title"))
+
+	title, err := a.Title(context.Background(), conversation("What is the weather like in Barcelona?"))
+	if err != nil {
+		t.Fatalf("Title error: %v", err)
+	}
+
+	if got, want := title, "Weather in Barcelona"; got != want {
+		t.Fatalf("title: got %q, want %q", got, want)
+	}
+}
+
+func TestAssistant_Reply_ToolLoop(t *testing.T) {
+	a := newTestAssistant(t, recorder.ForTest(t, "reply_weather"))
+
+	reply, err := a.Reply(context.Background(), conversation("What is the weather like in Barcelona?"), nil)
+	if err != nil {
+		t.Fatalf("Reply error: %v", err)
+	}
+
+	if !strings.Contains(reply, "Barcelona") {
+		t.Fatalf("reply should mention Barcelona, got %q", reply)
+	}
+}
+
+type fakePersonas map[string]*model.Persona
+
+func (f fakePersonas) GetPersona(_ context.Context, name string) (*model.Persona, error) {
+	if p, ok := f[name]; ok {
+		return p, nil
+	}
+	return nil, twirp.NotFoundError("persona not found")
+}
+
+// failingPersonas fails to resolve any persona, as when the database is down.
+type failingPersonas struct{}
+
+func (failingPersonas) GetPersona(context.Context, string) (*model.Persona, error) {
+	return nil, errors.New("database down")
+}
+
+func TestAssistant_Reply_FakeOpenAI(t *testing.T) {
+	ctx := context.Background()
+	reg := tools.NewRegistry(tools.TodayTool{})
+
+	t.Run("tool results are sent back to the model", func(t *testing.T) {
+		ai := chattesting.NewFakeOpenAI(t).
+			CallTool("get_today_date", nil).
+			Reply("Today is Wednesday")
+
+		reply, err := New(config.Defaults().Assistant, reg, ai.Options()...).Reply(ctx, conversation("What day is today?"), nil)
+		if err != nil {
+			t.Fatalf("Reply error: %v", err)
+		}
+		if got, want := reply, "Today is Wednesday"; got != want {
+			t.Fatalf("reply: got %q, want %q", got, want)
+		}
+
+		reqs := ai.Requests()
+		if len(reqs) != 2 {
+			t.Fatalf("expected 2 requests, got %d", len(reqs))
+		}
+		if got, want := reqs[0].ToolNames(), []string{"get_today_date"}; !slices.Equal(got, want) {
+			t.Fatalf("tools: got %v, want %v", got, want)
+		}
+		last := reqs[1].Messages[len(reqs[1].Messages)-1]
+		if _, err := time.Parse(time.RFC3339, last.Content()); last.Role != "tool" || err != nil {
+			t.Fatalf("expected tool message with RFC3339 date, got %s %q", last.Role, last.Content())
+		}
+	})
+
+	t.Run("unknown tool is reported to the model", func(t *testing.T) {
+		ai := chattesting.NewFakeOpenAI(t).
+			CallTool("get_lottery_numbers", "{}").
+			Reply("Sorry, I can't do that")
+
+		if _, err := New(config.Defaults().Assistant, reg, ai.Options()...).Reply(ctx, conversation("Lottery numbers?"), nil); err != nil {
+			t.Fatalf("Reply error: %v", err)
+		}
+
+		msgs := ai.Requests()[1].Messages
+		if got, want := msgs[len(msgs)-1].Content(), "unknown tool: get_lottery_numbers"; got != want {
+			t.Fatalf("tool message: got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("persona replaces the defaults", func(t *testing.T) {
+		reg := tools.NewRegistry(tools.TodayTool{}, tools.StockTool{})
+		temperature := 0.2
+		personas := fakePersonas{"travel-agent": {
+			Name:         "travel-agent",
+			SystemPrompt: "You are a travel agent.",
+			Model:        "gpt-4o-mini",
+			Temperature:  &temperature,
+			Tools:        []string{"get_today_date"},
+		}}
+
+		ai := chattesting.NewFakeOpenAI(t).
+			CallTool("get_stock_quote", `{"symbol":"AAPL"}`).
+			Reply("I can only help with travels")
+
+		a := New(config.Defaults().Assistant, reg, ai.Options()...)
+		a.Personas = personas
+		conv := conversation("How is Apple doing?")
+		conv.Persona = "travel-agent"
+		if _, err := a.Reply(ctx, conv, nil); err != nil {
+			t.Fatalf("Reply error: %v", err)
+		}
+
+		req := ai.Requests()[0]
+		if got, want := req.Messages[0].Content(), "You are a travel agent."; got != want {
+			t.Fatalf("system prompt: got %q, want %q", got, want)
+		}
+		if got, want := req.Model, "gpt-4o-mini"; got != want {
+			t.Fatalf("model: got %q, want %q", got, want)
+		}
+		if req.Temperature == nil || *req.Temperature != temperature {
+			t.Fatalf("temperature: got %v, want %v", req.Temperature, temperature)
+		}
+		if got, want := req.ToolNames(), []string{"get_today_date"}; !slices.Equal(got, want) {
+			t.Fatalf("tools: got %v, want %v", got, want)
+		}
+		msgs := ai.Requests()[1].Messages
+		if got, want := msgs[len(msgs)-1].Content(), "tool get_stock_quote is not available"; got != want {
+			t.Fatalf("tool message: got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("request parameters override the persona and defaults, unless unsupported", func(t *testing.T) {
+		cfg := config.Defaults().Assistant
+		defaultTemperature, personaTemperature := 0.5, 0.2
+		cfg.ReplyParams = config.Generation{Temperature: &defaultTemperature, MaxOutputTokens: 500}
+		personas := fakePersonas{"concise": {Name: "concise", SystemPrompt: "Be brief.", Model: "gpt-4.1-mini", Temperature: &personaTemperature}}
+
+		ai := chattesting.NewFakeOpenAI(t).Reply("Hi")
+
+		a := New(cfg, reg, ai.Options()...)
+		a.Personas = personas
+		conv := conversation("Hi")
+		conv.Persona = "concise"
+		if _, err := a.Reply(ctx, conv, &model.Generation{Model: "o4-mini", MaxOutputTokens: 100, ReasoningEffort: "low"}); err != nil {
+			t.Fatalf("Reply error: %v", err)
+		}
+
+		req := ai.Requests()[0]
+		got := model.Generation{Model: req.Model, Temperature: req.Temperature, MaxOutputTokens: req.MaxCompletionTokens, ReasoningEffort: req.ReasoningEffort}
+		want := model.Generation{Model: "o4-mini", MaxOutputTokens: 100, ReasoningEffort: "low"}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Fatalf("parameters mismatch (-got +want):\n%s", diff)
+		}
+	})
+
+	t.Run("reasoning effort is dropped for other models", func(t *testing.T) {
+		cfg := config.Defaults().Assistant
+		cfg.ReplyModel = "gpt-4.1"
+		cfg.ReplyParams = config.Generation{ReasoningEffort: "high"}
+
+		ai := chattesting.NewFakeOpenAI(t).Reply("Hi")
+		if _, err := New(cfg, reg, ai.Options()...).Reply(ctx, conversation("Hi"), nil); err != nil {
+			t.Fatalf("Reply error: %v", err)
+		}
+
+		if got := ai.Requests()[0].ReasoningEffort; got != "" {
+			t.Fatalf("reasoning effort: got %q, want none", got)
+		}
+	})
+
+	t.Run("deleted persona refuses to reply", func(t *testing.T) {
+		ai := chattesting.NewFakeOpenAI(t)
+
+		a := New(config.Defaults().Assistant, reg, ai.Options()...)
+		a.Personas = fakePersonas{}
+		conv := conversation("Hi")
+		conv.Persona = "gone"
+		_, err := a.Reply(ctx, conv, nil)
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.FailedPrecondition {
+			t.Fatalf("deleted persona: got %v, want FailedPrecondition", err)
+		}
+		if n := len(ai.Requests()); n != 0 {
+			t.Fatalf("expected no requests, got %d", n)
+		}
+	})
+
+	t.Run("persona errors are returned", func(t *testing.T) {
+		ai := chattesting.NewFakeOpenAI(t)
+
+		a := New(config.Defaults().Assistant, reg, ai.Options()...)
+		a.Personas = failingPersonas{}
+		conv := conversation("Hi")
+		conv.Persona = "trader"
+		if _, err := a.Reply(ctx, conv, nil); err == nil || !strings.Contains(err.Error(), "database down") {
+			t.Fatalf("expected persona error, got %v", err)
+		}
+		if n := len(ai.Requests()); n != 0 {
+			t.Fatalf("expected no requests, got %d", n)
+		}
+	})
+
+	t.Run("gives up after too many tool calls", func(t *testing.T) {
+		ai := chattesting.NewFakeOpenAI(t)
+		for range 15 {
+			ai.CallTool("get_today_date", nil)
+		}
+
+		_, err := New(config.Defaults().Assistant, reg, ai.Options()...).Reply(ctx, conversation("Loop forever"), nil)
+		if err == nil || !strings.Contains(err.Error(), "too many tool calls") {
+			t.Fatalf("expected too many tool calls error, got %v", err)
+		}
+		if got := len(ai.Requests()); got != 15 {
+			t.Fatalf("expected 15 requests, got %d", got)
+		}
+	})
+
+	t.Run("API errors are returned", func(t *testing.T) {
+		ai := chattesting.NewFakeOpenAI(t).Fail(http.StatusTooManyRequests, "rate limited")
+
+		if _, err := New(config.Defaults().Assistant, reg, ai.Options()...).Reply(ctx, conversation("Hi"), nil); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+
+	t.Run("slow responses honor the context deadline", func(t *testing.T) {
+		ai := chattesting.NewFakeOpenAI(t).Reply("too late").After(time.Second)
+
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		if _, err := New(config.Defaults().Assistant, reg, ai.Options()...).Reply(ctx, conversation("Hi"), nil); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected deadline exceeded, got %v", err)
+		}
+	})
+}
+
+func TestAssistant_Title_PostProcessing(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		reply string
+		want  string
+	}{
+		{name: "quotes and newlines", reply: "\"Weather\nin Barcelona\"\n", want: "Weather in Barcelona"},
+		{name: "leading dash", reply: "- Stock price of AAPL", want: "Stock price of AAPL"},
+		{name: "truncated to 80 characters", reply: strings.Repeat("a", 100), want: strings.Repeat("a", 80)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ai := chattesting.NewFakeOpenAI(t).Reply(tt.reply)
+
+			title, err := New(config.Defaults().Assistant, tools.NewRegistry(), ai.Options()...).Title(ctx, conversation("Hi"))
+			if err != nil {
+				t.Fatalf("Title error: %v", err)
+			}
+			if title != tt.want {
+				t.Fatalf("title: got %q, want %q", title, tt.want)
+			}
+
+			msgs := ai.Requests()[0].Messages
+			if len(msgs) != 2 || msgs[0].Role != "system" || msgs[1].Content() != "Hi" {
+				t.Fatalf("unexpected title prompt: %+v", msgs)
+			}
+		})
+	}
+
+	t.Run("empty title is an error", func(t *testing.T) {
+		ai := chattesting.NewFakeOpenAI(t).Reply("   ")
+
+		if _, err := New(config.Defaults().Assistant, tools.NewRegistry(), ai.Options()...).Title(ctx, conversation("Hi")); err == nil {
+			t.Fatal("expected error for empty title")
+		}
+	})
+}
+
+func TestAssistant_Retitle(t *testing.T) {
+	ctx := context.Background()
+
+	conv := conversation("hi")
+	conv.Messages = append(conv.Messages,
+		&model.Message{Role: model.RoleAssistant, Content: "Hello! How can I help?"},
+		&model.Message{Role: model.RoleUser, Content: "Which days are holidays in Rome?"},
+	)
+
+	t.Run("keeps the current title while it fits", func(t *testing.T) {
+		ai := chattesting.NewFakeOpenAI(t).Reply("\"Holidays in Rome\"")
+
+		conv := *conv
+		conv.Title = "Greeting"
+		title, err := New(config.Defaults().Assistant, tools.NewRegistry(), ai.Options()...).Retitle(ctx, &conv)
+		if err != nil {
+			t.Fatalf("Retitle error: %v", err)
+		}
+		if got, want := title, "Holidays in Rome"; got != want {
+			t.Fatalf("title: got %q, want %q", got, want)
+		}
+
+		msgs := ai.Requests()[0].Messages
+		if !strings.Contains(msgs[0].Content(), `currently titled "Greeting"`) {
+			t.Fatalf("prompt should offer the current title: %q", msgs[0].Content())
+		}
+		if transcript := msgs[1].Content(); !strings.Contains(transcript, "user: hi") || !strings.Contains(transcript, "user: Which days are holidays in Rome?") {
+			t.Fatalf("unexpected transcript: %q", transcript)
+		}
+	})
+
+	t.Run("untitled conversations get a new title", func(t *testing.T) {
+		ai := chattesting.NewFakeOpenAI(t).Reply("Holidays in Rome")
+
+		if _, err := New(config.Defaults().Assistant, tools.NewRegistry(), ai.Options()...).Retitle(ctx, conv); err != nil {
+			t.Fatalf("Retitle error: %v", err)
+		}
+		if prompt := ai.Requests()[0].Messages[0].Content(); strings.Contains(prompt, "currently titled") {
+			t.Fatalf("prompt should not offer a title: %q", prompt)
+		}
+	})
+}
+
+func TestAssistant_Suggest(t *testing.T) {
+	ctx := context.Background()
+	reg := tools.NewRegistry(tools.TodayTool{}, tools.StockTool{})
+
+	t.Run("prompts with the tools and strips list markers", func(t *testing.T) {
+		ai := chattesting.NewFakeOpenAI(t).Reply("1. What's the date today?\n\n- \"How is AAPL doing?\"\n2024 holidays in Spain?\n4) A\n5) B")
+
+		a := New(config.Defaults().Assistant, reg, ai.Options()...)
+		a.Personas = fakePersonas{"trader": {Name: "trader", SystemPrompt: "You trade.", Tools: []string{"get_stock_quote"}}}
+		conv := conversation("Hi")
+		conv.Persona = "trader"
+		got, err := a.Suggest(ctx, conv)
+		if err != nil {
+			t.Fatalf("Suggest error: %v", err)
+		}
+
+		want := []string{"What's the date today?", "How is AAPL doing?", "2024 holidays in Spain?", "A"}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Fatalf("suggestions mismatch (-got +want):\n%s", diff)
+		}
+
+		req := ai.Requests()[0]
+		if got, want := req.Model, config.Defaults().Assistant.SuggestionModel; got != want {
+			t.Fatalf("model: got %q, want %q", got, want)
+		}
+		if prompt := req.Messages[0].Content(); !strings.Contains(prompt, "get_stock_quote") || strings.Contains(prompt, "get_today_date") {
+			t.Fatalf("prompt should only list the persona's tools: %q", prompt)
+		}
+		if got := req.ToolNames(); len(got) != 0 {
+			t.Fatalf("tools: got %v, want none", got)
+		}
+	})
+
+	t.Run("too few suggestions is an error", func(t *testing.T) {
+		ai := chattesting.NewFakeOpenAI(t).Reply("Just one?")
+
+		if _, err := New(config.Defaults().Assistant, reg, ai.Options()...).Suggest(ctx, conversation("Hi")); err == nil {
+			t.Fatal("expected error for a single suggestion")
+		}
+	})
+
+	t.Run("persona errors are returned", func(t *testing.T) {
+		ai := chattesting.NewFakeOpenAI(t)
+
+		a := New(config.Defaults().Assistant, reg, ai.Options()...)
+		a.Personas = failingPersonas{}
+		conv := conversation("Hi")
+		conv.Persona = "trader"
+		if _, err := a.Suggest(ctx, conv); err == nil {
+			t.Fatal("expected persona error")
+		}
+		if n := len(ai.Requests()); n != 0 {
+			t.Fatalf("expected no requests, got %d", n)
+		}
+	})
+
+	t.Run("disabled without a suggestion model", func(t *testing.T) {
+		cfg := config.Defaults().Assistant
+		cfg.SuggestionModel = ""
+		ai := chattesting.NewFakeOpenAI(t)
+
+		got, err := New(cfg, reg, ai.Options()...).Suggest(ctx, conversation("Hi"))
+		if err != nil || got != nil {
+			t.Fatalf("Suggest: got %v, %v, want no suggestions", got, err)
+		}
+		if n := len(ai.Requests()); n != 0 {
+			t.Fatalf("expected no requests, got %d", n)
+		}
+	})
+}
+
+func TestEstimateCost(t *testing.T) {
+	tests := []struct {
+		model string
+		want  float64
+		ok    bool
+	}{
+		{model: "gpt-4.1-2025-04-14", want: 0.002 + 0.008, ok: true},
+		{model: "gpt-4.1-mini-2025-04-14", want: 0.0004 + 0.0016, ok: true},
+		{model: "o1-2024-12-17", want: 0.015 + 0.06, ok: true},
+		{model: "some-other-model", ok: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := estimateCost(tt.model, 1000, 1000)
+		if ok != tt.ok || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("estimateCost(%q): got (%v, %v), want (%v, %v)", tt.model, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package assistant
+
+import "strings"
+
+// price is the cost in USD per million tokens.
+type price struct {
+	Input  float64
+	Output float64
+}
+
+// prices lists public OpenAI list prices. Response models carry a snapshot
+// suffix (e.g. gpt-4.1-2025-04-14), so lookups match on the longest prefix.
+var prices = map[string]price{
+	"gpt-4.1":      {Input: 2.00, Output: 8.00},
+	"gpt-4.1-mini": {Input: 0.40, Output: 1.60},
+	"gpt-4.1-nano": {Input: 0.10, Output: 0.40},
+	"gpt-4o":       {Input: 2.50, Output: 10.00},
+	"gpt-4o-mini":  {Input: 0.15, Output: 0.60},
+	"o1":           {Input: 15.00, Output: 60.00},
+	"o1-mini":      {Input: 1.10, Output: 4.40},
+	"o3-mini":      {Input: 1.10, Output: 4.40},
+}
+
+// estimateCost returns the estimated cost in USD of a completion, and false
+// when the model is not in the price table.
+func estimateCost(model string, promptTokens, completionTokens int64) (float64, bool) {
+	var (
+		best  string
+		found bool
+	)
+	for name := range prices {
+		if strings.HasPrefix(model, name) && len(name) > len(best) {
+			best, found = name, true
+		}
+	}
+	if !found {
+		return 0, false
+	}
+
+	p := prices[best]
+	return (float64(promptTokens)*p.Input + float64(completionTokens)*p.Output) / 1_000_000, true
+}
@@ -0,0 +1,104 @@
+package assistant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/logx"
+	"github.com/openai/openai-go/v2"
+)
+
+const (
+	minSuggestions = 2
+	maxSuggestions = 4
+
+	// suggestionContext is how many of the last messages suggestions are based
+	// on.
+	suggestionContext = 6
+)
+
+// listMarker matches the bullet or number a suggestion may start with.
+var listMarker = regexp.MustCompile(`^(?:[-*•]|\d+[.)])\s+`)
+
+// Suggest proposes two to four prompts the user could follow the conversation
+// up with, favoring what the tools available to it can answer. It does not
+// wait for the reply to the last message, so that both can be generated at
+// once. No suggestions are made without a suggestion model.
+func (a *Assistant) Suggest(ctx context.Context, conv *model.Conversation) ([]string, error) {
+	if len(conv.Messages) == 0 || a.cfg.SuggestionModel == "" {
+		return nil, nil
+	}
+
+	ctx = logx.WithConversationID(ctx, conv.ID.Hex())
+	slog.InfoContext(ctx, "Generating follow-up suggestions for conversation")
+
+	p, err := a.persona(ctx, conv)
+	if err != nil {
+		return nil, err
+	}
+	var allowed []string
+	if p != nil {
+		allowed = p.Tools
+	}
+
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "Suggest %d to %d short follow-up questions the user could ask next in this conversation. "+
+		"Favor questions you can answer with your tools, such as:\n", minSuggestions, maxSuggestions)
+	for _, t := range a.tools.Tools() {
+		if len(allowed) > 0 && !slices.Contains(allowed, t.Name()) {
+			continue
+		}
+		fmt.Fprintf(&prompt, "- %s: %s\n", t.Name(), t.Schema().Description.Value)
+	}
+	prompt.WriteString("Write the questions as the user would, one per line, without numbering or any other text.")
+
+	msgs := []openai.ChatCompletionMessageParamUnion{openai.SystemMessage(prompt.String())}
+	for _, m := range conv.Messages[max(0, len(conv.Messages)-suggestionContext):] {
+		switch m.Role {
+		case model.RoleUser:
+			msgs = append(msgs, openai.UserMessage(m.Content))
+		case model.RoleAssistant:
+			msgs = append(msgs, openai.AssistantMessage(m.Content))
+		}
+	}
+
+	resp, err := a.complete(ctx, "suggest", conv, 0, openai.ChatCompletionNewParams{
+		Model:    a.cfg.SuggestionModel,
+		Messages: msgs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, errors.New("no choices returned by OpenAI")
+	}
+
+	suggestions := parseSuggestions(resp.Choices[0].Message.Content)
+	if len(suggestions) < minSuggestions {
+		return nil, fmt.Errorf("expected at least %d suggestions, got %d", minSuggestions, len(suggestions))
+	}
+	return suggestions, nil
+}
+
+// parseSuggestions returns up to maxSuggestions lines of content, stripped of
+// any list marker the model added anyway.
+func parseSuggestions(content string) []string {
+	var out []string
+	for _, line := range strings.Split(content, "\n") {
+		line = listMarker.ReplaceAllString(strings.TrimSpace(line), "")
+		line = strings.Trim(strings.TrimSpace(line), `"`)
+		if line == "" {
+			continue
+		}
+		if out = append(out, line); len(out) == maxSuggestions {
+			break
+		}
+	}
+	return out
+}
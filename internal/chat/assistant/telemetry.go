@@ -0,0 +1,90 @@
+package assistant
+
+import (
+	"context"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/usage"
+	"github.com/openai/openai-go/v2"
+	"github.com/twitchtv/twirp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/acai-travel/tech-challenge/internal/chat/assistant"
+
+var (
+	tracer = otel.Tracer(instrumentationName)
+	meter  = otel.Meter(instrumentationName)
+
+	completionDuration, _ = meter.Float64Histogram("llm.completion.duration",
+		metric.WithDescription("Duration of chat completion calls"),
+		metric.WithUnit("s"))
+	tokenUsage, _ = meter.Int64Counter("llm.tokens",
+		metric.WithDescription("Tokens consumed by chat completion calls"),
+		metric.WithUnit("{token}"))
+	estimatedCost, _ = meter.Float64Counter("llm.cost",
+		metric.WithDescription("Estimated cost of chat completion calls, based on list prices"),
+		metric.WithUnit("USD"))
+)
+
+// complete calls the Chat Completions API, recording a span, latency, token
+// usage and estimated cost. Operation is "title" or "reply"; iteration is the
+// position of the call within the tool loop.
+func (a *Assistant) complete(ctx context.Context, operation string, conv *model.Conversation, iteration int, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+	rpc, _ := twirp.MethodName(ctx)
+	attrs := []attribute.KeyValue{
+		attribute.String("gen_ai.operation.name", operation),
+		attribute.String("gen_ai.request.model", params.Model),
+		attribute.String("rpc.method", rpc),
+	}
+
+	// The conversation is only recorded on the span, as metric attributes
+	// create a time series per value.
+	ctx, span := tracer.Start(ctx, "chat.completions "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+		trace.WithAttributes(
+			attribute.String("conversation.id", conv.ID.Hex()),
+			attribute.Int("llm.iteration", iteration),
+		),
+	)
+	defer span.End()
+
+	start := time.Now()
+	resp, err := a.cli.Chat.Completions.New(ctx, params)
+	elapsed := time.Since(start).Seconds()
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		completionDuration.Record(ctx, elapsed, metric.WithAttributes(append(attrs, attribute.Bool("error", true))...))
+		return nil, err
+	}
+
+	completionDuration.Record(ctx, elapsed, metric.WithAttributes(append(attrs, attribute.Bool("error", false))...))
+
+	used := resp.Usage
+	span.SetAttributes(
+		attribute.String("gen_ai.response.model", resp.Model),
+		attribute.Int64("gen_ai.usage.input_tokens", used.PromptTokens),
+		attribute.Int64("gen_ai.usage.output_tokens", used.CompletionTokens),
+	)
+
+	// Charged to the client's daily quota, see httpx.TokenQuota.
+	usage.AddTokens(ctx, used.TotalTokens)
+
+	tokenUsage.Add(ctx, used.PromptTokens, metric.WithAttributes(append(attrs, attribute.String("gen_ai.token.type", "input"))...))
+	tokenUsage.Add(ctx, used.CompletionTokens, metric.WithAttributes(append(attrs, attribute.String("gen_ai.token.type", "output"))...))
+
+	if cost, ok := estimateCost(resp.Model, used.PromptTokens, used.CompletionTokens); ok {
+		span.SetAttributes(attribute.Float64("llm.cost.usd", cost))
+		estimatedCost.Add(ctx, cost, metric.WithAttributes(attrs...))
+	}
+
+	return resp, nil
+}
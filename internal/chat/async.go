@@ -0,0 +1,277 @@
+package chat
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/logx"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/acai-travel/tech-challenge/internal/tools"
+	"github.com/acai-travel/tech-challenge/internal/usage"
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultWorkers   = 4
+	defaultMaxQueued = 100
+
+	// replyTimeout bounds a reply generated in the background.
+	replyTimeout = 5 * time.Minute
+	// staleAfter is how long a reply may stay pending before it is considered
+	// abandoned, e.g. by a server that stopped, and can be retried.
+	staleAfter = 10 * time.Minute
+)
+
+var (
+	errReplyPending = twirp.NewError(twirp.FailedPrecondition, "a reply is pending, wait for it to complete")
+	errQueueFull    = twirp.NewError(twirp.ResourceExhausted, "too many replies are being generated, retry later")
+)
+
+// asyncWorkers runs the jobs of the background on a fixed number of workers.
+// Places in the queue are reserved before the job is known, so that a request
+// is rejected before it stores a reply that would never be generated.
+type asyncWorkers struct {
+	once   sync.Once
+	places chan struct{}
+	queue  chan func()
+	jobs   sync.WaitGroup
+}
+
+// place is a place in the queue of background jobs. It is given back by
+// release unless a job was run in it.
+type place struct {
+	pool *asyncWorkers
+	used bool
+}
+
+func (p *place) release() {
+	if !p.used {
+		p.used = true
+		<-p.pool.places
+	}
+}
+
+func pendingReply() *model.Message {
+	return &model.Message{
+		ID:        primitive.NewObjectID(),
+		Role:      model.RoleAssistant,
+		Status:    model.StatusPending,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// reserve takes a place in the queue of background jobs, failing with a
+// ResourceExhausted error when MaxQueued jobs already wait for a worker. The
+// place must be released if no job is run in it.
+func (s *Server) reserve() (*place, error) {
+	s.pool.once.Do(func() {
+		workers := cmp.Or(s.Workers, defaultWorkers)
+		size := workers + cmp.Or(s.MaxQueued, defaultMaxQueued)
+		s.pool.places = make(chan struct{}, size)
+		s.pool.queue = make(chan func(), size)
+		for range workers {
+			go func() {
+				for job := range s.pool.queue {
+					job()
+				}
+			}()
+		}
+	})
+
+	select {
+	case s.pool.places <- struct{}{}:
+		return &place{pool: &s.pool}, nil
+	default:
+		return nil, errQueueFull
+	}
+}
+
+// generate fills the pending reply to conv in the background, once a worker is
+// available. The conversation is titled too when retitle is set.
+func (s *Server) generate(ctx context.Context, p *place, conv *model.Conversation, pending *model.Message, retitle bool) {
+	s.background(ctx, p, func(ctx context.Context) {
+		s.reply(ctx, conv, pending, retitle)
+	})
+}
+
+// background runs fn in the place reserved, once a worker is available, within
+// replyTimeout. The job outlives the request, but keeps its trace and log
+// attributes, and holds its token usage open until done so that the tokens it
+// consumes are charged against the request's reservation.
+func (s *Server) background(ctx context.Context, p *place, fn func(ctx context.Context)) {
+	ctx = context.WithoutCancel(ctx)
+	release := usage.Hold(ctx)
+
+	p.used = true
+	s.pool.jobs.Add(1)
+	// The queue has room for every place, so this never blocks.
+	s.pool.queue <- func() {
+		defer s.pool.jobs.Done()
+		defer func() { <-s.pool.places }()
+		defer release()
+
+		ctx, cancel := context.WithTimeout(ctx, replyTimeout)
+		defer cancel()
+
+		fn(ctx)
+	}
+}
+
+func (s *Server) reply(ctx context.Context, conv *model.Conversation, pending *model.Message, retitle bool) {
+	history := conv.History()
+
+	var titled sync.WaitGroup
+	if retitle {
+		titled.Add(1)
+		go func() {
+			defer titled.Done()
+
+			title, err := s.assist.Title(ctx, history)
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to generate conversation title", "error", err)
+				return
+			}
+			if strings.TrimSpace(title) == "" {
+				return
+			}
+			if err := s.repo.SetTitle(context.WithoutCancel(ctx), conv.ID, title); err != nil {
+				slog.ErrorContext(ctx, "Failed to store conversation title", "error", err)
+			}
+		}()
+	}
+
+	suggested := make(chan []string, 1)
+	go func() { suggested <- s.suggest(ctx, history) }()
+
+	replyCtx, used := tools.TrackUsage(ctx)
+	reply, err := s.assist.Reply(replyCtx, history, pending.Generation)
+	titled.Wait()
+	suggestions := <-suggested
+
+	pending.Tools = used()
+	pending.UpdatedAt = time.Now()
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to generate conversation reply", "error", err)
+		pending.Status = model.StatusFailed
+		pending.Error = err.Error()
+	} else {
+		pending.Status = model.StatusComplete
+		pending.Content = reply
+		pending.Suggestions = suggestions
+		history.Messages = append(history.Messages, pending)
+	}
+
+	// Store the outcome even past the timeout, rather than leave it pending.
+	ok, err := s.repo.CompleteMessage(context.WithoutCancel(ctx), conv.ID, pending)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to store reply", "error", err, "message_id", pending.ID.Hex())
+	} else if !ok {
+		slog.WarnContext(ctx, "Reply was no longer pending, discarded", "message_id", pending.ID.Hex())
+	} else if pending.Status == model.StatusComplete {
+		s.revise(ctx, history)
+	}
+}
+
+// Wait blocks until the replies being generated in the background are done, or
+// ctx is. Replies left pending can be retried with RetryMessage once stale.
+func (s *Server) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.pool.jobs.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Server) GetMessageStatus(ctx context.Context, req *pb.GetMessageStatusRequest) (*pb.GetMessageStatusResponse, error) {
+	if req.GetConversationId() == "" {
+		return nil, twirp.RequiredArgumentError("conversation_id")
+	}
+	if req.GetMessageId() == "" {
+		return nil, twirp.RequiredArgumentError("message_id")
+	}
+
+	ctx = logx.WithConversationID(ctx, req.GetConversationId())
+
+	conversation, err := s.repo.DescribeConversation(ctx, req.GetConversationId())
+	if err != nil {
+		return nil, err
+	}
+
+	m := conversation.Message(req.GetMessageId())
+	if m == nil {
+		return nil, twirp.NotFoundError("message not found")
+	}
+
+	return &pb.GetMessageStatusResponse{Message: m.Proto()}, nil
+}
+
+func (s *Server) RetryMessage(ctx context.Context, req *pb.RetryMessageRequest) (*pb.RetryMessageResponse, error) {
+	if req.GetConversationId() == "" {
+		return nil, twirp.RequiredArgumentError("conversation_id")
+	}
+	if req.GetMessageId() == "" {
+		return nil, twirp.RequiredArgumentError("message_id")
+	}
+
+	ctx = logx.WithConversationID(ctx, req.GetConversationId())
+
+	conversation, err := s.repo.DescribeConversation(ctx, req.GetConversationId())
+	if err != nil {
+		return nil, err
+	}
+
+	m := conversation.Message(req.GetMessageId())
+	if m == nil {
+		return nil, twirp.NotFoundError("message not found")
+	}
+	if last := len(conversation.Messages) - 1; conversation.Messages[last] != m || m.Role != model.RoleAssistant {
+		return nil, twirp.NewError(twirp.FailedPrecondition, "only the last reply can be retried")
+	}
+	if m.Complete() {
+		return nil, twirp.NewError(twirp.FailedPrecondition, "the reply did not fail")
+	}
+
+	p, err := s.reserve()
+	if err != nil {
+		return nil, err
+	}
+	defer p.release()
+
+	retry := *m
+	retry.Status = model.StatusPending
+	retry.Error = ""
+	retry.UpdatedAt = time.Now()
+
+	ok, err := s.repo.RetryMessage(ctx, conversation.ID, &retry, time.Now().Add(-staleAfter))
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+	if !ok {
+		return nil, twirp.NewError(twirp.FailedPrecondition, "the reply is still being generated")
+	}
+
+	// The title is only generated along with the first reply.
+	conversation.Messages = conversation.Messages[:len(conversation.Messages)-1]
+	retitle := len(conversation.Messages) == 1 && conversation.Title == untitled
+
+	resp := &pb.RetryMessageResponse{Message: retry.Proto()}
+
+	slog.InfoContext(ctx, "Retrying reply", "message_id", retry.ID.Hex())
+	s.generate(ctx, p, conversation, &retry, retitle)
+
+	return resp, nil
+}
@@ -0,0 +1,74 @@
+package chat
+
+import (
+	"context"
+	"time"
+	"unicode/utf8"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/logx"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/twitchtv/twirp"
+)
+
+const maxFeedbackComment = 2000
+
+func (s *Server) SubmitFeedback(ctx context.Context, req *pb.SubmitFeedbackRequest) (*pb.SubmitFeedbackResponse, error) {
+	if req.GetConversationId() == "" {
+		return nil, twirp.RequiredArgumentError("conversation_id")
+	}
+	if req.GetMessageId() == "" {
+		return nil, twirp.RequiredArgumentError("message_id")
+	}
+
+	rating := model.RatingFromProto(req.GetRating())
+	if rating == "" {
+		return nil, twirp.RequiredArgumentError("rating")
+	}
+	reason := model.ReasonFromProto(req.GetReason())
+	if reason == "" && req.GetReason() != pb.Feedback_NO_REASON {
+		return nil, twirp.InvalidArgumentError("reason", "is not a known reason")
+	}
+	if utf8.RuneCountInString(req.GetComment()) > maxFeedbackComment {
+		return nil, twirp.InvalidArgumentError("comment", "must be at most 2000 characters")
+	}
+
+	ctx = logx.WithConversationID(ctx, req.GetConversationId())
+
+	conversation, err := s.repo.DescribeConversation(ctx, req.GetConversationId())
+	if err != nil {
+		return nil, err
+	}
+
+	m := conversation.Message(req.GetMessageId())
+	if m == nil {
+		return nil, twirp.NotFoundError("message not found")
+	}
+	if m.Role != model.RoleAssistant || !m.Complete() {
+		return nil, twirp.NewError(twirp.FailedPrecondition, "only replies of the assistant can be rated")
+	}
+
+	feedback := &model.Feedback{
+		MessageID:      m.ID,
+		ConversationID: conversation.ID,
+		Rating:         rating,
+		Reason:         reason,
+		Comment:        req.GetComment(),
+		Tools:          m.Tools,
+		Reply:          m.Content,
+		UpdatedAt:      time.Now(),
+	}
+
+	// The question is the user message the reply follows.
+	for i, msg := range conversation.Messages {
+		if msg == m && i > 0 {
+			feedback.Question = conversation.Messages[i-1].Content
+		}
+	}
+
+	if err := s.repo.SaveFeedback(ctx, feedback); err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	return &pb.SubmitFeedbackResponse{}, nil
+}
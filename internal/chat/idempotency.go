@@ -0,0 +1,100 @@
+package chat
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/httpx"
+	"github.com/twitchtv/twirp"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// idempotencyLease bounds how long an attempt holds a key before a retry may
+	// take over, e.g. after a crash. A reply can take several model round-trips.
+	idempotencyLease = 3 * time.Minute
+	// idempotencyPoll is how often a retry checks on the attempt in flight.
+	idempotencyPoll = 250 * time.Millisecond
+
+	maxIdempotencyKey = 128
+)
+
+// idempotent runs handle at most once per client and idempotency key. A retry
+// with the same key gets the stored response, or waits for the attempt in
+// flight to finish. A key reused for a different request is rejected. Without a
+// key, handle always runs.
+func idempotent[T proto.Message](ctx context.Context, repo *model.Repository, method, key string, req proto.Message, handle func() (T, error)) (T, error) {
+	var zero T
+	if key == "" {
+		return handle()
+	}
+	if len(key) > maxIdempotencyKey {
+		return zero, twirp.InvalidArgumentError("idempotency_key", "must be at most 128 characters")
+	}
+
+	// Keys are only unique per client, see httpx.ClientFromContext.
+	id := httpx.ClientFromContext(ctx) + "/" + method + "/" + key
+	fp := fingerprint(req)
+
+	for {
+		rec, err := repo.ClaimIdempotencyKey(ctx, id, fp, idempotencyLease)
+		if err != nil {
+			return zero, twirp.InternalErrorWith(err)
+		}
+		if rec == nil {
+			break // ours to handle
+		}
+		if rec.Fingerprint != fp {
+			return zero, twirp.InvalidArgumentError("idempotency_key", "was already used for a different request")
+		}
+		if rec.Done {
+			resp := zero.ProtoReflect().New().Interface().(T)
+			if err := proto.Unmarshal(rec.Response, resp); err != nil {
+				return zero, twirp.InternalErrorWith(err)
+			}
+			slog.InfoContext(ctx, "Replaying response for idempotency key", "idempotency_key", key)
+			return resp, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		case <-time.After(idempotencyPoll):
+		}
+	}
+
+	// The outcome must be recorded even if the client went away meanwhile.
+	resp, err := handle()
+	if err != nil {
+		if rerr := repo.ReleaseIdempotencyKey(context.WithoutCancel(ctx), id); rerr != nil {
+			slog.ErrorContext(ctx, "Failed to release idempotency key", "error", rerr)
+		}
+		return zero, err
+	}
+
+	b, err := proto.Marshal(resp)
+	if err == nil {
+		err = repo.CompleteIdempotencyKey(context.WithoutCancel(ctx), id, b)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to store response for idempotency key", "error", err)
+	}
+
+	return resp, nil
+}
+
+// fingerprint identifies the content of a request, ignoring its idempotency key.
+func fingerprint(req proto.Message) string {
+	m := proto.Clone(req).ProtoReflect()
+	if fd := m.Descriptor().Fields().ByName("idempotency_key"); fd != nil {
+		m.Clear(fd)
+	}
+
+	b, _ := proto.MarshalOptions{Deterministic: true}.Marshal(m.Interface())
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
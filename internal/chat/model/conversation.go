@@ -14,6 +14,10 @@ type Conversation struct {
 	CreatedAt time.Time          `bson:"created_at"`
 	UpdatedAt time.Time          `bson:"updated_at"`
 	Messages  []*Message         `bson:"messages"`
+	Persona   string             `bson:"persona,omitempty"`
+	// Renamed is set when the user chose the title, which is then no longer
+	// revised automatically.
+	Renamed bool `bson:"renamed,omitempty"`
 }
 
 func (c *Conversation) Proto() *pb.Conversation {
@@ -21,6 +25,8 @@ func (c *Conversation) Proto() *pb.Conversation {
 		Id:        c.ID.Hex(),
 		Title:     c.Title,
 		Timestamp: timestamppb.New(c.UpdatedAt),
+		Persona:   c.Persona,
+		Renamed:   c.Renamed,
 	}
 
 	for _, m := range c.Messages {
@@ -29,3 +35,36 @@ func (c *Conversation) Proto() *pb.Conversation {
 
 	return proto
 }
+
+// Message returns the message with the given ID, or nil.
+func (c *Conversation) Message(id string) *Message {
+	for _, m := range c.Messages {
+		if m.ID.Hex() == id {
+			return m
+		}
+	}
+	return nil
+}
+
+// Pending reports whether a reply is being generated for the conversation.
+func (c *Conversation) Pending() bool {
+	for _, m := range c.Messages {
+		if m.Status == StatusPending {
+			return true
+		}
+	}
+	return false
+}
+
+// History returns a copy of the conversation holding only complete messages,
+// as the assistant should see it.
+func (c *Conversation) History() *Conversation {
+	h := *c
+	h.Messages = nil
+	for _, m := range c.Messages {
+		if m.Complete() {
+			h.Messages = append(h.Messages, m)
+		}
+	}
+	return &h
+}
@@ -0,0 +1,212 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const feedbackCollection = "feedback"
+
+type Rating string
+
+const (
+	RatingGood Rating = "good"
+	RatingBad  Rating = "bad"
+)
+
+func (r Rating) Proto() pb.Feedback_Rating {
+	switch r {
+	case RatingGood:
+		return pb.Feedback_GOOD
+	case RatingBad:
+		return pb.Feedback_BAD
+	default:
+		return 0
+	}
+}
+
+// RatingFromProto returns the rating, or an empty one when unrated.
+func RatingFromProto(r pb.Feedback_Rating) Rating {
+	switch r {
+	case pb.Feedback_GOOD:
+		return RatingGood
+	case pb.Feedback_BAD:
+		return RatingBad
+	default:
+		return ""
+	}
+}
+
+// Reason categorizes what was good or bad about a reply.
+type Reason string
+
+const (
+	ReasonInaccurate Reason = "inaccurate"
+	ReasonUnhelpful  Reason = "unhelpful"
+	ReasonIncomplete Reason = "incomplete"
+	ReasonTooVerbose Reason = "too_verbose"
+	ReasonUnsafe     Reason = "unsafe"
+	ReasonOther      Reason = "other"
+)
+
+var reasons = map[Reason]pb.Feedback_Reason{
+	ReasonInaccurate: pb.Feedback_INACCURATE,
+	ReasonUnhelpful:  pb.Feedback_UNHELPFUL,
+	ReasonIncomplete: pb.Feedback_INCOMPLETE,
+	ReasonTooVerbose: pb.Feedback_TOO_VERBOSE,
+	ReasonUnsafe:     pb.Feedback_UNSAFE,
+	ReasonOther:      pb.Feedback_OTHER,
+}
+
+func (r Reason) Proto() pb.Feedback_Reason {
+	return reasons[r]
+}
+
+// ReasonFromProto returns the reason, or an empty one when there is none.
+func ReasonFromProto(r pb.Feedback_Reason) Reason {
+	for reason, p := range reasons {
+		if p == r {
+			return reason
+		}
+	}
+	return ""
+}
+
+// Feedback is the rating of a reply, keyed by the message holding it. The reply,
+// the question it answers and the tools it called are copied, for feedback to
+// be evaluated on its own.
+type Feedback struct {
+	MessageID      primitive.ObjectID `bson:"_id"`
+	ConversationID primitive.ObjectID `bson:"conversation_id"`
+	Rating         Rating             `bson:"rating"`
+	Reason         Reason             `bson:"reason,omitempty"`
+	Comment        string             `bson:"comment,omitempty"`
+	Tools          []string           `bson:"tools,omitempty"`
+	Question       string             `bson:"question"`
+	Reply          string             `bson:"reply"`
+	CreatedAt      time.Time          `bson:"created_at"`
+	UpdatedAt      time.Time          `bson:"updated_at"`
+}
+
+func (f *Feedback) Proto() *pb.Feedback {
+	return &pb.Feedback{
+		ConversationId: f.ConversationID.Hex(),
+		MessageId:      f.MessageID.Hex(),
+		Rating:         f.Rating.Proto(),
+		Reason:         f.Reason.Proto(),
+		Comment:        f.Comment,
+		Tools:          f.Tools,
+		Question:       f.Question,
+		Reply:          f.Reply,
+		Timestamp:      timestamppb.New(f.UpdatedAt),
+	}
+}
+
+// FeedbackFilter selects feedback, its zero value selects all of it.
+type FeedbackFilter struct {
+	// Since and Until bound when the feedback was last submitted, if set.
+	Since, Until time.Time
+	Rating       Rating
+	// Tool selects feedback on replies that called it, if set.
+	Tool string
+	// UsedTools selects feedback on replies that called tools, or that did not,
+	// if set.
+	UsedTools *bool
+}
+
+func (f FeedbackFilter) query() bson.M {
+	q := bson.M{}
+
+	at := bson.M{}
+	if !f.Since.IsZero() {
+		at["$gte"] = f.Since
+	}
+	if !f.Until.IsZero() {
+		at["$lt"] = f.Until
+	}
+	if len(at) > 0 {
+		q["updated_at"] = at
+	}
+
+	if f.Rating != "" {
+		q["rating"] = f.Rating
+	}
+
+	if f.Tool != "" {
+		q["tools"] = f.Tool
+	}
+	if f.UsedTools != nil {
+		q["tools.0"] = bson.M{"$exists": *f.UsedTools}
+	}
+	return q
+}
+
+// SaveFeedback stores feedback on a reply, replacing any given before.
+func (r *Repository) SaveFeedback(ctx context.Context, f *Feedback) error {
+	_, err := r.conn.Collection(feedbackCollection).UpdateOne(ctx,
+		bson.M{"_id": f.MessageID},
+		bson.M{
+			"$set": bson.M{
+				"conversation_id": f.ConversationID,
+				"rating":          f.Rating,
+				"reason":          f.Reason,
+				"comment":         f.Comment,
+				"tools":           f.Tools,
+				"question":        f.Question,
+				"reply":           f.Reply,
+				"updated_at":      f.UpdatedAt,
+			},
+			"$setOnInsert": bson.M{"created_at": f.UpdatedAt},
+		},
+		options.Update().SetUpsert(true))
+	return err
+}
+
+// ListFeedback returns the most recent feedback matching the filter.
+func (r *Repository) ListFeedback(ctx context.Context, filter FeedbackFilter, limit int) ([]*Feedback, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "updated_at", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.conn.Collection(feedbackCollection).Find(ctx, filter.query(), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*Feedback
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// EachFeedback calls fn with all the feedback matching the filter, oldest first,
+// until fn returns an error.
+func (r *Repository) EachFeedback(ctx context.Context, filter FeedbackFilter, fn func(*Feedback) error) error {
+	opts := options.Find().SetSort(bson.D{{Key: "updated_at", Value: 1}})
+
+	cursor, err := r.conn.Collection(feedbackCollection).Find(ctx, filter.query(), opts)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = cursor.Close(ctx)
+	}()
+
+	for cursor.Next(ctx) {
+		var f Feedback
+		if err := cursor.Decode(&f); err != nil {
+			return err
+		}
+		if err := fn(&f); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const idempotencyCollection = "idempotency_keys"
+
+// IdempotencyRetention is how long a completed request can be replayed.
+const IdempotencyRetention = 24 * time.Hour
+
+// maxIdempotencyClaims bounds the attempts to claim a key that keeps being
+// released meanwhile.
+const maxIdempotencyClaims = 3
+
+// IdempotencyRecord tracks a request made with an idempotency key. While the
+// request is handled, Response is empty and the record is leased until
+// LeasedUntil, after which another attempt may take it over.
+type IdempotencyRecord struct {
+	ID          string    `bson:"_id"`
+	Fingerprint string    `bson:"fingerprint"`
+	Done        bool      `bson:"done"`
+	Response    []byte    `bson:"response,omitempty"`
+	LeasedUntil time.Time `bson:"leased_until"`
+	CreatedAt   time.Time `bson:"created_at"`
+	ExpiresAt   time.Time `bson:"expires_at"`
+}
+
+// ClaimIdempotencyKey records that a request with the given key is starting.
+// It returns nil when the caller now owns the key and must handle the request,
+// or the existing record when another attempt got there first. An attempt whose
+// lease expired, e.g. because its process died, is taken over.
+func (r *Repository) ClaimIdempotencyKey(ctx context.Context, id, fingerprint string, lease time.Duration) (*IdempotencyRecord, error) {
+	// The record may expire or be released between the attempts to insert and
+	// to read it, in which case claiming is tried again.
+	for range maxIdempotencyClaims {
+		now := time.Now()
+		claim := bson.M{
+			"fingerprint":  fingerprint,
+			"done":         false,
+			"leased_until": now.Add(lease),
+			"created_at":   now,
+			"expires_at":   now.Add(IdempotencyRetention),
+		}
+
+		// Insert the record, or take over an abandoned attempt.
+		_, err := r.conn.Collection(idempotencyCollection).UpdateOne(ctx,
+			bson.M{"_id": id, "done": false, "leased_until": bson.M{"$lt": now}},
+			bson.M{"$set": claim},
+			options.Update().SetUpsert(true))
+		if err == nil {
+			return nil, nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return nil, err
+		}
+
+		rec, err := r.GetIdempotencyKey(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if rec != nil {
+			return rec, nil
+		}
+	}
+	return nil, fmt.Errorf("idempotency key %q changed hands %d times while claiming it", id, maxIdempotencyClaims)
+}
+
+// GetIdempotencyKey returns the record for the key, or nil if there is none.
+func (r *Repository) GetIdempotencyKey(ctx context.Context, id string) (*IdempotencyRecord, error) {
+	var rec IdempotencyRecord
+	err := r.conn.Collection(idempotencyCollection).FindOne(ctx, bson.M{"_id": id}).Decode(&rec)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// CompleteIdempotencyKey stores the response to replay for the key.
+func (r *Repository) CompleteIdempotencyKey(ctx context.Context, id string, response []byte) error {
+	_, err := r.conn.Collection(idempotencyCollection).UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"done": true, "response": response}})
+	return err
+}
+
+// ReleaseIdempotencyKey forgets a key whose request failed, so it can be retried.
+func (r *Repository) ReleaseIdempotencyKey(ctx context.Context, id string) error {
+	_, err := r.conn.Collection(idempotencyCollection).DeleteOne(ctx, bson.M{"_id": id, "done": false})
+	return err
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"strings"
 	"time"
 
 	"github.com/acai-travel/tech-challenge/internal/pb"
@@ -12,8 +13,16 @@ type Message struct {
 	ID        primitive.ObjectID `bson:"_id"`
 	Role      Role               `bson:"role"`
 	Content   string             `bson:"content"`
+	Status    Status             `bson:"status,omitempty"`
+	Error     string             `bson:"error,omitempty"`
+	Tools     []string           `bson:"tools,omitempty"`
 	CreatedAt time.Time          `bson:"created_at"`
 	UpdatedAt time.Time          `bson:"updated_at"`
+
+	// Generation holds the parameters a reply was requested with, if any.
+	Generation *Generation `bson:"generation,omitempty"`
+	// Suggestions are prompts the user may follow a reply up with.
+	Suggestions []string `bson:"suggestions,omitempty"`
 }
 
 func (m *Message) Proto() *pb.Conversation_Message {
@@ -22,5 +31,35 @@ func (m *Message) Proto() *pb.Conversation_Message {
 		Role:      m.Role.Proto(),
 		Content:   m.Content,
 		Timestamp: timestamppb.New(m.CreatedAt),
+		Status:    m.Status.Proto(),
+		Error:     m.Error,
+		Tools:     m.Tools,
+
+		Suggestions: m.Suggestions,
+	}
+}
+
+// Complete reports whether the message holds content, as opposed to a reply
+// still pending or that failed.
+func (m *Message) Complete() bool {
+	return m.Status == "" || m.Status == StatusComplete
+}
+
+// Generation overrides the defaults of the assistant for a reply, zero values
+// keeping them.
+type Generation struct {
+	Model           string   `bson:"model,omitempty"`
+	Temperature     *float64 `bson:"temperature,omitempty"`
+	MaxOutputTokens int64    `bson:"max_output_tokens,omitempty"`
+	ReasoningEffort string   `bson:"reasoning_effort,omitempty"`
+}
+
+// ReasoningModel reports whether the named OpenAI model reasons: the o-series
+// and GPT-5 but for its chat variant. Reasoning models take a reasoning effort
+// but reject a temperature, and other models the opposite.
+func ReasoningModel(name string) bool {
+	if strings.HasPrefix(name, "gpt-5") {
+		return !strings.Contains(name, "-chat")
 	}
+	return len(name) > 1 && name[0] == 'o' && name[1] >= '0' && name[1] <= '9'
 }
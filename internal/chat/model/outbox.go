@@ -0,0 +1,155 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	outboxCollection = "outbox"
+
+	// outboxRetention is how long dispatched events are kept, e.g. to investigate
+	// a sink that missed some.
+	outboxRetention = 24 * time.Hour
+)
+
+// EventType is the kind of change recorded by an Event.
+type EventType string
+
+const (
+	EventConversationStarted EventType = "conversation_started"
+	EventMessageAppended     EventType = "message_appended"
+	EventMessageUpdated      EventType = "message_updated"
+	EventTitleChanged        EventType = "title_changed"
+	EventConversationDeleted EventType = "conversation_deleted"
+)
+
+// Event records a change to a conversation. Events are written to the outbox in
+// the same transaction as the change, then dispatched to the sinks interested
+// in them. Conversation is set for EventConversationStarted, Message for
+// message events and Title for EventTitleChanged.
+type Event struct {
+	ID             primitive.ObjectID `bson:"_id"`
+	Type           EventType          `bson:"type"`
+	ConversationID primitive.ObjectID `bson:"conversation_id"`
+	Conversation   *Conversation      `bson:"conversation,omitempty"`
+	Message        *Message           `bson:"message,omitempty"`
+	Title          string             `bson:"title,omitempty"`
+	CreatedAt      time.Time          `bson:"created_at"`
+
+	Dispatched  bool      `bson:"dispatched"`
+	LeasedUntil time.Time `bson:"leased_until"`
+	LeaseClaim  string    `bson:"lease_claim,omitempty"`
+	ExpiresAt   time.Time `bson:"expires_at,omitempty"`
+}
+
+func newEvent(typ EventType, conversationID primitive.ObjectID) *Event {
+	return &Event{
+		ID:             primitive.NewObjectID(),
+		Type:           typ,
+		ConversationID: conversationID,
+		CreatedAt:      time.Now(),
+	}
+}
+
+// transact runs fn in a transaction, writing the events it returns to the outbox
+// along with its changes.
+func (r *Repository) transact(ctx context.Context, fn func(ctx mongo.SessionContext) ([]*Event, error)) error {
+	sess, err := r.conn.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer sess.EndSession(ctx)
+
+	_, err = sess.WithTransaction(ctx, func(ctx mongo.SessionContext) (any, error) {
+		events, err := fn(ctx)
+		if err != nil || len(events) == 0 {
+			return nil, err
+		}
+
+		docs := make([]any, len(events))
+		for i, e := range events {
+			docs[i] = e
+		}
+		_, err = r.conn.Collection(outboxCollection).InsertMany(ctx, docs)
+		return nil, err
+	})
+	return err
+}
+
+// ClaimEvents leases up to limit undispatched events, oldest first. Events whose
+// lease expired without being dispatched, e.g. because a sink failed, are
+// claimed again.
+func (r *Repository) ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]*Event, error) {
+	now := time.Now()
+	claimable := bson.M{"dispatched": false, "leased_until": bson.M{"$lt": now}}
+	coll := r.conn.Collection(outboxCollection)
+
+	cursor, err := coll.Find(ctx, claimable, options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var candidates []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &candidates); err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]primitive.ObjectID, len(candidates))
+	for i, c := range candidates {
+		ids[i] = c.ID
+	}
+
+	// Another dispatcher may have claimed some of them meanwhile, only those
+	// leased by this claim are returned.
+	claim := primitive.NewObjectID().Hex()
+	claimable["_id"] = bson.M{"$in": ids}
+	_, err = coll.UpdateMany(ctx, claimable, bson.M{"$set": bson.M{
+		"leased_until": now.Add(lease),
+		"lease_claim":  claim,
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err = coll.Find(ctx,
+		bson.M{"_id": bson.M{"$in": ids}, "lease_claim": claim},
+		options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	var events []*Event
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// ExtendLease pushes the lease of the events of a claim that are not dispatched
+// yet back to lease from now.
+func (r *Repository) ExtendLease(ctx context.Context, claim string, lease time.Duration) error {
+	_, err := r.conn.Collection(outboxCollection).UpdateMany(ctx,
+		bson.M{"lease_claim": claim, "dispatched": false},
+		bson.M{"$set": bson.M{"leased_until": time.Now().Add(lease)}})
+	return err
+}
+
+// MarkDispatched records that the event reached every sink.
+func (r *Repository) MarkDispatched(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.conn.Collection(outboxCollection).UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"dispatched": true, "expires_at": time.Now().Add(outboxRetention)}})
+	return err
+}
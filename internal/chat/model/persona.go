@@ -0,0 +1,117 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const personaCollection = "personas"
+
+// Persona shapes the replies of the assistant in the conversations started
+// with it. Zero values fall back to the assistant's defaults, and every tool is
+// allowed when Tools is empty.
+type Persona struct {
+	Name         string    `bson:"_id"`
+	Description  string    `bson:"description,omitempty"`
+	SystemPrompt string    `bson:"system_prompt"`
+	Model        string    `bson:"model,omitempty"`
+	Temperature  *float64  `bson:"temperature,omitempty"`
+	Tools        []string  `bson:"tools,omitempty"`
+	CreatedAt    time.Time `bson:"created_at"`
+	UpdatedAt    time.Time `bson:"updated_at"`
+}
+
+func (p *Persona) Proto() *pb.Persona {
+	return &pb.Persona{
+		Name:         p.Name,
+		Description:  p.Description,
+		SystemPrompt: p.SystemPrompt,
+		Model:        p.Model,
+		Temperature:  p.Temperature,
+		Tools:        p.Tools,
+		Timestamp:    timestamppb.New(p.UpdatedAt),
+	}
+}
+
+func (r *Repository) CreatePersona(ctx context.Context, p *Persona) error {
+	_, err := r.conn.Collection(personaCollection).InsertOne(ctx, p)
+	if mongo.IsDuplicateKeyError(err) {
+		return twirp.NewError(twirp.AlreadyExists, "persona already exists")
+	}
+	return err
+}
+
+func (r *Repository) GetPersona(ctx context.Context, name string) (*Persona, error) {
+	var p Persona
+	err := r.conn.Collection(personaCollection).FindOne(ctx, bson.M{"_id": name}).Decode(&p)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, twirp.NotFoundError("persona not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ListPersonas returns every persona, sorted by name.
+func (r *Repository) ListPersonas(ctx context.Context) ([]*Persona, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+
+	cursor, err := r.conn.Collection(personaCollection).Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*Persona
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// UpdatePersona replaces the persona, keeping when it was created.
+func (r *Repository) UpdatePersona(ctx context.Context, p *Persona) error {
+	var stored Persona
+	err := r.conn.Collection(personaCollection).FindOneAndUpdate(ctx,
+		bson.M{"_id": p.Name},
+		bson.M{
+			"$set": bson.M{
+				"description":   p.Description,
+				"system_prompt": p.SystemPrompt,
+				"model":         p.Model,
+				"temperature":   p.Temperature,
+				"tools":         p.Tools,
+				"updated_at":    p.UpdatedAt,
+			},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&stored)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return twirp.NotFoundError("persona not found")
+	}
+	if err != nil {
+		return err
+	}
+	p.CreatedAt = stored.CreatedAt
+	return nil
+}
+
+// DeletePersona removes the persona. Conversations started with it carry on
+// with the assistant's defaults.
+func (r *Repository) DeletePersona(ctx context.Context, name string) error {
+	res, err := r.conn.Collection(personaCollection).DeleteOne(ctx, bson.M{"_id": name})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return twirp.NotFoundError("persona not found")
+	}
+	return nil
+}
@@ -3,6 +3,8 @@ package model
 import (
 	"context"
 	"errors"
+	"maps"
+	"time"
 
 	"github.com/twitchtv/twirp"
 	"go.mongodb.org/mongo-driver/bson"
@@ -16,7 +18,8 @@ const (
 )
 
 type Repository struct {
-	conn *mongo.Database
+	conn     *mongo.Database
+	watchers watchers
 }
 
 func New(conn *mongo.Database) *Repository {
@@ -25,9 +28,24 @@ func New(conn *mongo.Database) *Repository {
 	}
 }
 
+// Writes to conversations go through transact, recording the corresponding
+// events in the outbox. They set updated_at to the time of the write, on the
+// conversation and the messages written, which syncs rely on.
+
 func (r *Repository) CreateConversation(ctx context.Context, c *Conversation) error {
-	_, err := r.conn.Collection(conversationCollection).InsertOne(ctx, c)
-	return err
+	return r.transact(ctx, func(ctx mongo.SessionContext) ([]*Event, error) {
+		c.UpdatedAt = time.Now()
+		for _, m := range c.Messages {
+			m.UpdatedAt = c.UpdatedAt
+		}
+		if _, err := r.conn.Collection(conversationCollection).InsertOne(ctx, c); err != nil {
+			return nil, err
+		}
+
+		e := newEvent(EventConversationStarted, c.ID)
+		e.Conversation = c
+		return []*Event{e}, nil
+	})
 }
 
 func (r *Repository) DescribeConversation(ctx context.Context, id string) (*Conversation, error) {
@@ -84,23 +102,180 @@ func (r *Repository) ListConversations(ctx context.Context) ([]*Conversation, er
 	return items, nil
 }
 
-func (r *Repository) UpdateConversation(ctx context.Context, c *Conversation) error {
-	_, err := r.conn.Collection(conversationCollection).UpdateOne(ctx,
-		map[string]any{"_id": c.ID},
-		map[string]any{"$set": c})
+// ErrReplyPending is returned when appending to a conversation whose last reply
+// is still being generated.
+var ErrReplyPending = errors.New("a reply is pending")
 
-	if errors.Is(err, mongo.ErrNoDocuments) {
-		return twirp.NotFoundError("conversation not found")
+// AppendMessages adds messages to the conversation, unless a reply is pending.
+func (r *Repository) AppendMessages(ctx context.Context, id primitive.ObjectID, msgs ...*Message) error {
+	var matched bool
+	err := r.transact(ctx, func(ctx mongo.SessionContext) ([]*Event, error) {
+		now := time.Now()
+		for _, m := range msgs {
+			m.UpdatedAt = now
+		}
+
+		res, err := r.conn.Collection(conversationCollection).UpdateOne(ctx,
+			bson.M{"_id": id, "messages.status": bson.M{"$ne": StatusPending}},
+			bson.M{
+				"$push": bson.M{"messages": bson.M{"$each": msgs}},
+				"$set":  bson.M{"updated_at": now},
+			})
+		if err != nil {
+			return nil, err
+		}
+		if matched = res.MatchedCount > 0; !matched {
+			return nil, nil
+		}
+
+		events := make([]*Event, len(msgs))
+		for i, m := range msgs {
+			events[i] = newEvent(EventMessageAppended, id)
+			events[i].Message = m
+		}
+		return events, nil
+	})
+	if err != nil {
+		return err
 	}
+	if !matched {
+		if _, err := r.DescribeConversation(ctx, id.Hex()); err != nil {
+			return err
+		}
+		return ErrReplyPending
+	}
+	return nil
+}
+
+// SetTitle titles the conversation, unless the user renamed it.
+func (r *Repository) SetTitle(ctx context.Context, id primitive.ObjectID, title string) error {
+	return r.setTitle(ctx, bson.M{"_id": id, "renamed": bson.M{"$ne": true}}, title, false)
+}
+
+// RenameConversation titles the conversation, as chosen by the user when
+// renamed is set. Otherwise the title is revised automatically again.
+func (r *Repository) RenameConversation(ctx context.Context, id primitive.ObjectID, title string, renamed bool) error {
+	return r.setTitle(ctx, bson.M{"_id": id}, title, renamed)
+}
+
+func (r *Repository) setTitle(ctx context.Context, filter bson.M, title string, renamed bool) error {
+	filter["$or"] = bson.A{
+		bson.M{"subject": bson.M{"$ne": title}},
+		bson.M{"renamed": bson.M{"$ne": renamed}},
+	}
+
+	return r.transact(ctx, func(ctx mongo.SessionContext) ([]*Event, error) {
+		var prev Conversation
+		err := r.conn.Collection(conversationCollection).FindOneAndUpdate(ctx, filter,
+			bson.M{"$set": bson.M{"subject": title, "renamed": renamed, "updated_at": time.Now()}},
+			options.FindOneAndUpdate().SetProjection(bson.M{"subject": 1})).Decode(&prev)
+		if errors.Is(err, mongo.ErrNoDocuments) || prev.Title == title {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		e := newEvent(EventTitleChanged, prev.ID)
+		e.Title = title
+		return []*Event{e}, nil
+	})
+}
+
+// CompleteMessage stores the outcome of a pending reply. It reports false when
+// the message is no longer pending, e.g. because a retry already completed it.
+func (r *Repository) CompleteMessage(ctx context.Context, id primitive.ObjectID, m *Message) (bool, error) {
+	return r.replaceMessage(ctx, id, m, bson.M{"status": StatusPending})
+}
 
-	return err
+// RetryMessage marks a failed reply as pending again, or a pending one last
+// updated before stale, i.e. abandoned by its worker. It reports false when the
+// message is in neither state.
+func (r *Repository) RetryMessage(ctx context.Context, id primitive.ObjectID, m *Message, stale time.Time) (bool, error) {
+	return r.replaceMessage(ctx, id, m, bson.M{"$or": bson.A{
+		bson.M{"status": StatusFailed},
+		bson.M{"status": StatusPending, "updated_at": bson.M{"$lt": stale}},
+	}})
+}
+
+// replaceMessage replaces message m of the conversation if the stored message
+// matches cond.
+func (r *Repository) replaceMessage(ctx context.Context, id primitive.ObjectID, m *Message, cond bson.M) (bool, error) {
+	match := bson.M{"_id": m.ID}
+	maps.Copy(match, cond)
+
+	var matched bool
+	err := r.transact(ctx, func(ctx mongo.SessionContext) ([]*Event, error) {
+		m.UpdatedAt = time.Now()
+		res, err := r.conn.Collection(conversationCollection).UpdateOne(ctx,
+			bson.M{"_id": id, "messages": bson.M{"$elemMatch": match}},
+			bson.M{"$set": bson.M{"messages.$": m, "updated_at": m.UpdatedAt}})
+		if err != nil {
+			return nil, err
+		}
+		if matched = res.MatchedCount > 0; !matched {
+			return nil, nil
+		}
+
+		e := newEvent(EventMessageUpdated, id)
+		e.Message = m
+		return []*Event{e}, nil
+	})
+	return matched, err
 }
 
 func (r *Repository) DeleteConversation(ctx context.Context, id string) error {
-	_, err := r.conn.Collection(conversationCollection).DeleteOne(ctx, map[string]any{"_id": id})
-	if errors.Is(err, mongo.ErrNoDocuments) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return twirp.NotFoundError("invalid conversation ID")
+	}
+
+	var deleted bool
+	err = r.transact(ctx, func(ctx mongo.SessionContext) ([]*Event, error) {
+		res, err := r.conn.Collection(conversationCollection).DeleteOne(ctx, bson.M{"_id": oid})
+		if err != nil {
+			return nil, err
+		}
+		if deleted = res.DeletedCount > 0; !deleted {
+			return nil, nil
+		}
+		if err := r.addTombstone(ctx, oid); err != nil {
+			return nil, err
+		}
+		return []*Event{newEvent(EventConversationDeleted, oid)}, nil
+	})
+	if err != nil {
+		return err
+	}
+	if !deleted {
 		return twirp.NotFoundError("conversation not found")
 	}
+	return nil
+}
 
-	return err
+// EnsureIndexes creates the indexes the repository relies on, including the TTL
+// indexes expiring short-lived records. It is safe to call on every startup.
+func (r *Repository) EnsureIndexes(ctx context.Context) error {
+	indexes := map[string]bson.D{
+		outboxCollection:       {{Key: "dispatched", Value: 1}, {Key: "_id", Value: 1}},
+		conversationCollection: {{Key: "updated_at", Value: 1}, {Key: "_id", Value: 1}},
+		tombstoneCollection:    {{Key: "deleted_at", Value: 1}},
+		feedbackCollection:     {{Key: "updated_at", Value: 1}},
+	}
+	for coll, keys := range indexes {
+		if _, err := r.conn.Collection(coll).Indexes().CreateOne(ctx, mongo.IndexModel{Keys: keys}); err != nil {
+			return err
+		}
+	}
+
+	for _, coll := range []string{tokenUsageCollection, idempotencyCollection, failedDeliveryCollection, outboxCollection, tombstoneCollection} {
+		_, err := r.conn.Collection(coll).Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,24 @@
+package model
+
+import "github.com/acai-travel/tech-challenge/internal/pb"
+
+// Status tracks replies generated in the background. Messages stored before
+// async replies existed have no status and are complete.
+type Status string
+
+const (
+	StatusComplete Status = "complete"
+	StatusPending  Status = "pending"
+	StatusFailed   Status = "failed"
+)
+
+func (s Status) Proto() pb.Conversation_Status {
+	switch s {
+	case StatusPending:
+		return pb.Conversation_PENDING
+	case StatusFailed:
+		return pb.Conversation_FAILED
+	default:
+		return pb.Conversation_COMPLETE
+	}
+}
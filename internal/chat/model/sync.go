@@ -0,0 +1,98 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	tombstoneCollection = "conversation_tombstones"
+
+	// TombstoneRetention is how long deletions are remembered, for clients to
+	// sync them. Clients that last synced earlier have to sync everything again.
+	TombstoneRetention = 30 * 24 * time.Hour
+)
+
+// Tombstone records the deletion of a conversation.
+type Tombstone struct {
+	ConversationID primitive.ObjectID `bson:"_id"`
+	DeletedAt      time.Time          `bson:"deleted_at"`
+	ExpiresAt      time.Time          `bson:"expires_at"`
+}
+
+// SyncPosition is where a sync stopped: conversations are returned in the order
+// of their last update, then of their ID. A zero ID stands past every
+// conversation updated at UpdatedAt.
+type SyncPosition struct {
+	UpdatedAt time.Time
+	ID        primitive.ObjectID
+}
+
+// ChangedConversations returns up to limit conversations updated after from,
+// and not after until.
+func (r *Repository) ChangedConversations(ctx context.Context, from SyncPosition, until time.Time, limit int) ([]*Conversation, error) {
+	filter := bson.M{"updated_at": bson.M{"$lte": until}}
+	switch {
+	case from.UpdatedAt.IsZero():
+	case from.ID.IsZero():
+		filter["updated_at"] = bson.M{"$gt": from.UpdatedAt, "$lte": until}
+	default:
+		filter["$or"] = bson.A{
+			bson.M{"updated_at": bson.M{"$gt": from.UpdatedAt}},
+			bson.M{"updated_at": from.UpdatedAt, "_id": bson.M{"$gt": from.ID}},
+		}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "updated_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.conn.Collection(conversationCollection).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*Conversation
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// DeletedConversations returns the IDs of the conversations deleted after since,
+// and not after until.
+func (r *Repository) DeletedConversations(ctx context.Context, since, until time.Time) ([]primitive.ObjectID, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "deleted_at", Value: 1}})
+
+	cursor, err := r.conn.Collection(tombstoneCollection).Find(ctx,
+		bson.M{"deleted_at": bson.M{"$gt": since, "$lte": until}}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var tombstones []*Tombstone
+	if err := cursor.All(ctx, &tombstones); err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, len(tombstones))
+	for i, t := range tombstones {
+		ids[i] = t.ConversationID
+	}
+	return ids, nil
+}
+
+func (r *Repository) addTombstone(ctx mongo.SessionContext, id primitive.ObjectID) error {
+	now := time.Now()
+	_, err := r.conn.Collection(tombstoneCollection).InsertOne(ctx, &Tombstone{
+		ConversationID: id,
+		DeletedAt:      now,
+		ExpiresAt:      now.Add(TombstoneRetention),
+	})
+	return err
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	tokenUsageCollection = "token_usage"
+
+	// tokenUsageRetention is how long daily usage records are kept.
+	tokenUsageRetention = 7 * 24 * time.Hour
+)
+
+type tokenUsage struct {
+	ID        string    `bson:"_id"`
+	Client    string    `bson:"client"`
+	Day       time.Time `bson:"day"`
+	Tokens    int64     `bson:"tokens"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+func tokenUsageID(client string, day time.Time) string {
+	return client + "/" + day.Format("2006-01-02")
+}
+
+// ReserveTokens charges tokens to client for the given day, unless its usage
+// would exceed limit. The guard and the increment are a single update, so
+// concurrent reservations cannot overshoot the limit.
+func (r *Repository) ReserveTokens(ctx context.Context, client string, day time.Time, tokens, limit int64) (bool, error) {
+	if tokens > limit {
+		return false, nil
+	}
+	// When over the limit, the filter does not match the existing record and
+	// the upsert fails to insert another with the same ID.
+	err := r.chargeTokens(ctx, bson.M{"_id": tokenUsageID(client, day), "tokens": bson.M{"$lte": limit - tokens}}, client, day, tokens)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// AddTokenUsage charges tokens to client for the given day, or refunds them
+// when negative.
+func (r *Repository) AddTokenUsage(ctx context.Context, client string, day time.Time, tokens int64) error {
+	return r.chargeTokens(ctx, bson.M{"_id": tokenUsageID(client, day)}, client, day, tokens)
+}
+
+func (r *Repository) chargeTokens(ctx context.Context, filter bson.M, client string, day time.Time, tokens int64) error {
+	_, err := r.conn.Collection(tokenUsageCollection).UpdateOne(ctx,
+		filter,
+		bson.M{
+			"$inc": bson.M{"tokens": tokens},
+			"$setOnInsert": bson.M{
+				"client":     client,
+				"day":        day,
+				"expires_at": day.Add(tokenUsageRetention),
+			},
+		},
+		options.Update().SetUpsert(true))
+	return err
+}
@@ -0,0 +1,262 @@
+package model
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// WatchPollInterval is how often WatchConversation reads the conversation where
+// change streams are unsupported.
+var WatchPollInterval = 2 * time.Second
+
+// WatchConversation sends the conversation on the returned channel each time it
+// changes, until ctx is done or the conversation is deleted, then closes it. A
+// watcher slower than the changes only receives the latest. Watchers share a
+// single change stream, and fall back to polling where change streams are not
+// supported, e.g. on a standalone server.
+func (r *Repository) WatchConversation(ctx context.Context, id string) (<-chan *Conversation, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, twirp.NotFoundError("invalid conversation ID")
+	}
+
+	w, err := r.watchers.add(ctx, r.conn.Collection(conversationCollection), oid)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		slog.WarnContext(ctx, "Change streams unavailable, polling the conversation instead", "error", err)
+		return r.pollConversation(ctx, id), nil
+	}
+
+	context.AfterFunc(ctx, func() { r.watchers.remove(oid, w) })
+	return w, nil
+}
+
+// watchers fans the changes to conversations out to their watchers from one
+// change stream, opened with the first watcher and closed after the last. The
+// stream only matches the conversations watched, and is restarted when they
+// change: the new stream is opened before the previous one is closed, so that
+// no change is missed, and the changes both streams deliver are published
+// once.
+type watchers struct {
+	mu     sync.Mutex
+	coll   *mongo.Collection
+	subs   map[primitive.ObjectID]map[chan *Conversation]struct{}
+	seen   map[primitive.ObjectID]primitive.Timestamp
+	cancel context.CancelFunc
+}
+
+func (ws *watchers) add(ctx context.Context, coll *mongo.Collection, id primitive.ObjectID) (chan *Conversation, error) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if _, ok := ws.subs[id]; !ok {
+		ws.coll = coll
+		if err := ws.watch(ctx, append(ws.ids(), id)); err != nil {
+			return nil, err
+		}
+		if ws.subs == nil {
+			ws.subs = map[primitive.ObjectID]map[chan *Conversation]struct{}{}
+			ws.seen = map[primitive.ObjectID]primitive.Timestamp{}
+		}
+		ws.subs[id] = map[chan *Conversation]struct{}{}
+	}
+
+	w := make(chan *Conversation, 1)
+	ws.subs[id][w] = struct{}{}
+	return w, nil
+}
+
+// remove closes w, unless the conversation was deleted or the stream failed
+// meanwhile, and closes the stream if no watcher is left.
+func (ws *watchers) remove(id primitive.ObjectID, w chan *Conversation) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if _, ok := ws.subs[id][w]; !ok {
+		return
+	}
+	close(w)
+	delete(ws.subs[id], w)
+	if len(ws.subs[id]) > 0 {
+		return
+	}
+	ws.forget(id)
+
+	if len(ws.subs) == 0 {
+		ws.stop()
+		return
+	}
+	// The current stream keeps matching the conversation otherwise, which is
+	// only wasteful.
+	if err := ws.watch(context.Background(), ws.ids()); err != nil {
+		slog.Error("Failed to restart conversation change stream", "error", err)
+	}
+}
+
+// ids returns the conversations watched. It is called with mu held.
+func (ws *watchers) ids() []primitive.ObjectID {
+	ids := make([]primitive.ObjectID, 0, len(ws.subs)+1)
+	for id := range ws.subs {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// forget drops the conversation id once it has no watcher left. It is called
+// with mu held.
+func (ws *watchers) forget(id primitive.ObjectID) {
+	delete(ws.subs, id)
+	delete(ws.seen, id)
+}
+
+// watch replaces the stream with one for the changes to the conversations ids.
+// The current stream is kept if it cannot be opened. It is called with mu held.
+func (ws *watchers) watch(ctx context.Context, ids []primitive.ObjectID) error {
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.M{
+		"operationType":   bson.M{"$in": bson.A{"update", "replace", "delete"}},
+		"documentKey._id": bson.M{"$in": ids},
+	}}}}
+	stream, err := ws.coll.Watch(ctx, pipeline, options.ChangeStream().SetFullDocument(options.UpdateLookup))
+	if err != nil {
+		return err
+	}
+
+	if ws.cancel != nil {
+		ws.cancel()
+	}
+	var streamCtx context.Context
+	streamCtx, ws.cancel = context.WithCancel(context.Background())
+	go ws.run(streamCtx, stream)
+	return nil
+}
+
+// stop closes the stream and the watchers left. It is called with mu held.
+func (ws *watchers) stop() {
+	for _, subs := range ws.subs {
+		for w := range subs {
+			close(w)
+		}
+	}
+	ws.subs = nil
+	ws.seen = nil
+	if ws.cancel != nil {
+		ws.cancel()
+		ws.cancel = nil
+	}
+}
+
+func (ws *watchers) run(ctx context.Context, stream *mongo.ChangeStream) {
+	defer func() { _ = stream.Close(context.WithoutCancel(ctx)) }()
+
+	for stream.Next(ctx) {
+		var change struct {
+			OperationType string              `bson:"operationType"`
+			ClusterTime   primitive.Timestamp `bson:"clusterTime"`
+			DocumentKey   struct {
+				ID primitive.ObjectID `bson:"_id"`
+			} `bson:"documentKey"`
+			FullDocument *Conversation `bson:"fullDocument"`
+		}
+		if err := stream.Decode(&change); err != nil {
+			slog.ErrorContext(ctx, "Failed to decode conversation change", "error", err)
+			continue
+		}
+		ws.publish(change.OperationType, change.ClusterTime, change.DocumentKey.ID, change.FullDocument)
+	}
+
+	// Watchers are closed when the stream fails, so that clients reconnect.
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if ctx.Err() == nil {
+		slog.ErrorContext(ctx, "Conversation change stream failed", "error", stream.Err())
+		ws.stop()
+	}
+}
+
+// publish sends conv to the watchers of the conversation id, replacing the
+// change they did not receive yet if any, or closes them once it is deleted.
+// Changes older than the last one published, e.g. delivered again by the
+// stream being replaced, are dropped.
+func (ws *watchers) publish(op string, at primitive.Timestamp, id primitive.ObjectID, conv *Conversation) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if _, ok := ws.subs[id]; !ok {
+		return
+	}
+	if last, ok := ws.seen[id]; ok && !at.After(last) {
+		return
+	}
+	ws.seen[id] = at
+
+	switch {
+	case op == "delete":
+		for w := range ws.subs[id] {
+			close(w)
+		}
+		ws.forget(id)
+		if len(ws.subs) == 0 {
+			ws.stop()
+		}
+		return
+	case conv == nil:
+		return // deleted before it was looked up
+	}
+
+	for w := range ws.subs[id] {
+		select {
+		case <-w:
+		default:
+		}
+		w <- conv
+	}
+}
+
+func (r *Repository) pollConversation(ctx context.Context, id string) <-chan *Conversation {
+	ch := make(chan *Conversation)
+	go func() {
+		defer close(ch)
+
+		t := time.NewTicker(WatchPollInterval)
+		defer t.Stop()
+
+		var last time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+
+			c, err := r.DescribeConversation(ctx, id)
+			if te, ok := err.(twirp.Error); ok && te.Code() == twirp.NotFound {
+				return
+			}
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to poll conversation", "error", err)
+				continue
+			}
+			if !c.UpdatedAt.After(last) {
+				continue
+			}
+			last = c.UpdatedAt
+
+			select {
+			case ch <- c:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,111 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	failedDeliveryCollection = "webhook_failures"
+
+	// failedDeliveryRetention is how long a failed webhook delivery can be
+	// replayed.
+	failedDeliveryRetention = 30 * 24 * time.Hour
+)
+
+// FailedDelivery is a webhook event that could not be delivered to a
+// subscription, kept so that it can be replayed. It is retried in the background
+// until RetryingUntil, which is pushed back before each attempt and cleared once
+// the retries gave up.
+type FailedDelivery struct {
+	ID             primitive.ObjectID `bson:"_id"`
+	SubscriptionID string             `bson:"subscription_id"`
+	EventID        string             `bson:"event_id"`
+	EventType      string             `bson:"event_type"`
+	Payload        []byte             `bson:"payload"`
+	Attempts       int                `bson:"attempts"`
+	LastError      string             `bson:"last_error"`
+	RetryingUntil  time.Time          `bson:"retrying_until,omitempty"`
+	CreatedAt      time.Time          `bson:"created_at"`
+	UpdatedAt      time.Time          `bson:"updated_at"`
+	ExpiresAt      time.Time          `bson:"expires_at"`
+}
+
+func (r *Repository) AddFailedDelivery(ctx context.Context, d *FailedDelivery) error {
+	if d.ID.IsZero() {
+		d.ID = primitive.NewObjectID()
+	}
+	d.ExpiresAt = d.UpdatedAt.Add(failedDeliveryRetention)
+
+	_, err := r.conn.Collection(failedDeliveryCollection).InsertOne(ctx, d)
+	return err
+}
+
+func (r *Repository) GetFailedDelivery(ctx context.Context, id string) (*FailedDelivery, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, twirp.NotFoundError("invalid delivery ID")
+	}
+
+	var d FailedDelivery
+	err = r.conn.Collection(failedDeliveryCollection).FindOne(ctx, bson.M{"_id": oid}).Decode(&d)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, twirp.NotFoundError("delivery not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// ListFailedDeliveries returns the most recent failed deliveries no longer being
+// retried, to the given subscription if not empty.
+func (r *Repository) ListFailedDeliveries(ctx context.Context, subscriptionID string, limit int) ([]*FailedDelivery, error) {
+	filter := bson.M{"retrying_until": bson.M{"$not": bson.M{"$gt": time.Now()}}}
+	if subscriptionID != "" {
+		filter["subscription_id"] = subscriptionID
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "updated_at", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.conn.Collection(failedDeliveryCollection).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*FailedDelivery
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// UpdateFailedDelivery records another failed attempt at a delivery, and until
+// when it is retried.
+func (r *Repository) UpdateFailedDelivery(ctx context.Context, d *FailedDelivery) error {
+	d.ExpiresAt = d.UpdatedAt.Add(failedDeliveryRetention)
+
+	_, err := r.conn.Collection(failedDeliveryCollection).UpdateOne(ctx,
+		bson.M{"_id": d.ID},
+		bson.M{"$set": bson.M{
+			"attempts":       d.Attempts,
+			"last_error":     d.LastError,
+			"retrying_until": d.RetryingUntil,
+			"updated_at":     d.UpdatedAt,
+			"expires_at":     d.ExpiresAt,
+		}})
+	return err
+}
+
+func (r *Repository) DeleteFailedDelivery(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.conn.Collection(failedDeliveryCollection).DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
@@ -2,12 +2,16 @@ package chat
 
 import (
 	"context"
+	"errors"
 	"log/slog"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/logx"
 	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/acai-travel/tech-challenge/internal/tools"
 	"github.com/twitchtv/twirp"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"golang.org/x/sync/errgroup"
@@ -17,12 +21,33 @@ var _ pb.ChatService = (*Server)(nil)
 
 type Assistant interface {
 	Title(ctx context.Context, conv *model.Conversation) (string, error)
-	Reply(ctx context.Context, conv *model.Conversation) (string, error)
+	Reply(ctx context.Context, conv *model.Conversation, gen *model.Generation) (string, error)
+	Suggest(ctx context.Context, conv *model.Conversation) ([]string, error)
+	Retitle(ctx context.Context, conv *model.Conversation) (string, error)
 }
 
+// untitled is the title of a conversation until one is generated.
+const untitled = "Untitled conversation"
+
 type Server struct {
 	repo   *model.Repository
 	assist Assistant
+
+	// Workers caps the replies generated concurrently in the background, for
+	// requests made in async mode, and the titles revised. Defaults to 4.
+	Workers int
+	// MaxQueued caps the jobs waiting for a worker, beyond which async requests
+	// are rejected. Defaults to 100.
+	MaxQueued int
+	pool      asyncWorkers
+
+	// AllowedModels are the models requests may ask replies from. Requests
+	// cannot choose the model when empty.
+	AllowedModels []string
+
+	// RetitleAfter are the turns after which the title of a conversation is
+	// revised, unless the user renamed it. Titles are never revised when empty.
+	RetitleAfter []int
 }
 
 func NewServer(repo *model.Repository, assist Assistant) *Server {
@@ -30,9 +55,15 @@ func NewServer(repo *model.Repository, assist Assistant) *Server {
 }
 
 func (s *Server) StartConversation(ctx context.Context, req *pb.StartConversationRequest) (*pb.StartConversationResponse, error) {
+	return idempotent(ctx, s.repo, "StartConversation", req.GetIdempotencyKey(), req, func() (*pb.StartConversationResponse, error) {
+		return s.startConversation(ctx, req)
+	})
+}
+
+func (s *Server) startConversation(ctx context.Context, req *pb.StartConversationRequest) (*pb.StartConversationResponse, error) {
 	conversation := &model.Conversation{
 		ID:        primitive.NewObjectID(),
-		Title:     "Untitled conversation",
+		Title:     untitled,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 		Messages: []*model.Message{{
@@ -48,6 +79,49 @@ func (s *Server) StartConversation(ctx context.Context, req *pb.StartConversatio
 		return nil, twirp.RequiredArgumentError("message")
 	}
 
+	gen, err := s.generation(req.GetOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	if name := req.GetPersona(); name != "" {
+		_, err := s.repo.GetPersona(ctx, name)
+		if te, ok := err.(twirp.Error); ok && te.Code() == twirp.NotFound {
+			return nil, twirp.InvalidArgumentError("persona", "is not a known persona")
+		}
+		if err != nil {
+			return nil, twirp.InternalErrorWith(err)
+		}
+		conversation.Persona = name
+	}
+
+	ctx = logx.WithConversationID(ctx, conversation.ID.Hex())
+
+	// In async mode, the title and reply are generated in the background.
+	if req.GetAsync() {
+		p, err := s.reserve()
+		if err != nil {
+			return nil, err
+		}
+		defer p.release()
+
+		reply := pendingReply()
+		reply.Generation = gen
+		conversation.Messages = append(conversation.Messages, reply)
+
+		if err := s.repo.CreateConversation(ctx, conversation); err != nil {
+			return nil, err
+		}
+		s.generate(ctx, p, conversation, reply, true)
+
+		return &pb.StartConversationResponse{
+			ConversationId: conversation.ID.Hex(),
+			Title:          conversation.Title,
+			MessageId:      reply.ID.Hex(),
+			Status:         pb.Conversation_PENDING,
+		}, nil
+	}
+
 	// Optimize StartConversation performance by running title + reply generation concurrently.
 	//
 	// There are two main strategies to reduce latency here:
@@ -78,8 +152,9 @@ func (s *Server) StartConversation(ctx context.Context, req *pb.StartConversatio
 
 	// generate a reply
 	var reply string
+	replyCtx, used := tools.TrackUsage(errGroupCtx)
 	g.Go(func() error {
-		r, err := s.assist.Reply(errGroupCtx, conversation)
+		r, err := s.assist.Reply(replyCtx, conversation, gen)
 		if err != nil {
 			slog.ErrorContext(errGroupCtx, "Failed to generate conversation reply", "error", err)
 			return err
@@ -88,6 +163,13 @@ func (s *Server) StartConversation(ctx context.Context, req *pb.StartConversatio
 		return nil
 	})
 
+	// suggest follow-ups
+	var suggestions []string
+	g.Go(func() error {
+		suggestions = s.suggest(errGroupCtx, conversation)
+		return nil
+	})
+
 	if err := g.Wait(); err != nil {
 		return nil, err
 	}
@@ -96,15 +178,21 @@ func (s *Server) StartConversation(ctx context.Context, req *pb.StartConversatio
 		conversation.Title = title
 	}
 
-	conversation.Messages = append(conversation.Messages, &model.Message{
+	answer := &model.Message{
 		ID:        primitive.NewObjectID(),
 		Role:      model.RoleAssistant,
 		Content:   reply,
+		Tools:     used(),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
-	})
 
-	if err := s.repo.CreateConversation(ctx, conversation); err != nil {
+		Generation:  gen,
+		Suggestions: suggestions,
+	}
+	conversation.Messages = append(conversation.Messages, answer)
+
+	// Persist even if the client went away meanwhile, the reply is already paid for.
+	if err := s.repo.CreateConversation(context.WithoutCancel(ctx), conversation); err != nil {
 		return nil, err
 	}
 
@@ -112,14 +200,24 @@ func (s *Server) StartConversation(ctx context.Context, req *pb.StartConversatio
 		ConversationId: conversation.ID.Hex(),
 		Title:          conversation.Title,
 		Reply:          reply,
+		MessageId:      answer.ID.Hex(),
+		Suggestions:    suggestions,
 	}, nil
 }
 
 func (s *Server) ContinueConversation(ctx context.Context, req *pb.ContinueConversationRequest) (*pb.ContinueConversationResponse, error) {
+	return idempotent(ctx, s.repo, "ContinueConversation", req.GetIdempotencyKey(), req, func() (*pb.ContinueConversationResponse, error) {
+		return s.continueConversation(ctx, req)
+	})
+}
+
+func (s *Server) continueConversation(ctx context.Context, req *pb.ContinueConversationRequest) (*pb.ContinueConversationResponse, error) {
 	if req.GetConversationId() == "" {
 		return nil, twirp.RequiredArgumentError("conversation_id")
 	}
 
+	ctx = logx.WithConversationID(ctx, req.GetConversationId())
+
 	if strings.TrimSpace(req.GetMessage()) == "" {
 		return nil, twirp.RequiredArgumentError("message")
 	}
@@ -129,33 +227,147 @@ func (s *Server) ContinueConversation(ctx context.Context, req *pb.ContinueConve
 		return nil, err
 	}
 
-	conversation.UpdatedAt = time.Now()
-	conversation.Messages = append(conversation.Messages, &model.Message{
+	if conversation.Pending() {
+		return nil, errReplyPending
+	}
+
+	gen, err := s.generation(req.GetOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	question := &model.Message{
 		ID:        primitive.NewObjectID(),
 		Role:      model.RoleUser,
 		Content:   req.GetMessage(),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
-	})
+	}
+	conversation.Messages = append(conversation.Messages, question)
+
+	// In async mode, the reply is generated in the background.
+	if req.GetAsync() {
+		p, err := s.reserve()
+		if err != nil {
+			return nil, err
+		}
+		defer p.release()
+
+		reply := pendingReply()
+		reply.Generation = gen
+		if err := s.appendMessages(ctx, conversation, question, reply); err != nil {
+			return nil, err
+		}
+		s.generate(ctx, p, conversation, reply, false)
+
+		return &pb.ContinueConversationResponse{
+			MessageId: reply.ID.Hex(),
+			Status:    pb.Conversation_PENDING,
+		}, nil
+	}
 
-	reply, err := s.assist.Reply(ctx, conversation)
+	history := conversation.History()
+	suggested := make(chan []string, 1)
+	go func() { suggested <- s.suggest(ctx, history) }()
+
+	replyCtx, used := tools.TrackUsage(ctx)
+	reply, err := s.assist.Reply(replyCtx, history, gen)
+	suggestions := <-suggested
+	if te, ok := err.(twirp.Error); ok {
+		return nil, te
+	}
 	if err != nil {
 		return nil, twirp.InternalErrorWith(err)
 	}
 
-	conversation.Messages = append(conversation.Messages, &model.Message{
+	answer := &model.Message{
 		ID:        primitive.NewObjectID(),
 		Role:      model.RoleAssistant,
 		Content:   reply,
+		Tools:     used(),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
-	})
 
-	if err := s.repo.UpdateConversation(ctx, conversation); err != nil {
-		return nil, twirp.InternalErrorWith(err)
+		Generation:  gen,
+		Suggestions: suggestions,
+	}
+
+	// Persist even if the client went away meanwhile, the reply is already paid for.
+	if err := s.appendMessages(context.WithoutCancel(ctx), conversation, question, answer); err != nil {
+		return nil, err
 	}
+	history.Messages = append(history.Messages, answer)
+	s.revise(ctx, history)
 
-	return &pb.ContinueConversationResponse{Reply: reply}, nil
+	return &pb.ContinueConversationResponse{Reply: reply, MessageId: answer.ID.Hex(), Suggestions: suggestions}, nil
+}
+
+// suggest returns follow-ups to the reply being generated to conv, none when
+// they fail to generate as they are not worth failing the reply for.
+func (s *Server) suggest(ctx context.Context, conv *model.Conversation) []string {
+	suggestions, err := s.assist.Suggest(ctx, conv)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to suggest follow-ups", "error", err)
+		return nil
+	}
+	return suggestions
+}
+
+// generation validates the parameters requested for a reply, nil when there
+// are none.
+func (s *Server) generation(opts *pb.GenerationOptions) (*model.Generation, error) {
+	if opts == nil {
+		return nil, nil
+	}
+
+	switch {
+	case opts.GetModel() != "" && !slices.Contains(s.AllowedModels, opts.GetModel()):
+		return nil, twirp.InvalidArgumentError("options.model", "is not an allowed model")
+	case opts.Temperature != nil && (opts.GetTemperature() < 0 || opts.GetTemperature() > 2):
+		return nil, twirp.InvalidArgumentError("options.temperature", "must be between 0 and 2")
+	case opts.GetMaxOutputTokens() < 0:
+		return nil, twirp.InvalidArgumentError("options.max_output_tokens", "must not be negative")
+	}
+
+	// Without a model, those unsupported by the one replying are dropped instead.
+	if name := opts.GetModel(); name != "" {
+		reasoning := model.ReasoningModel(name)
+		switch {
+		case reasoning && opts.Temperature != nil:
+			return nil, twirp.InvalidArgumentError("options.temperature", "is not supported by reasoning models")
+		case !reasoning && opts.GetReasoningEffort() != pb.GenerationOptions_DEFAULT_EFFORT:
+			return nil, twirp.InvalidArgumentError("options.reasoning_effort", "is only supported by reasoning models")
+		}
+	}
+
+	gen := &model.Generation{
+		Model:           opts.GetModel(),
+		Temperature:     opts.Temperature,
+		MaxOutputTokens: int64(opts.GetMaxOutputTokens()),
+	}
+	if effort := opts.GetReasoningEffort(); effort != pb.GenerationOptions_DEFAULT_EFFORT {
+		gen.ReasoningEffort = strings.ToLower(effort.String())
+	}
+	if *gen == (model.Generation{}) {
+		return nil, nil
+	}
+	return gen, nil
+}
+
+// appendMessages adds messages to the conversation, unless a reply to it was
+// requested meanwhile and is pending.
+func (s *Server) appendMessages(ctx context.Context, conv *model.Conversation, msgs ...*model.Message) error {
+	err := s.repo.AppendMessages(ctx, conv.ID, msgs...)
+	if errors.Is(err, model.ErrReplyPending) {
+		return errReplyPending
+	}
+	if _, ok := err.(twirp.Error); ok {
+		return err
+	}
+	if err != nil {
+		return twirp.InternalErrorWith(err)
+	}
+	return nil
 }
 
 func (s *Server) ListConversations(ctx context.Context, req *pb.ListConversationsRequest) (*pb.ListConversationsResponse, error) {
@@ -178,6 +390,8 @@ func (s *Server) DescribeConversation(ctx context.Context, req *pb.DescribeConve
 		return nil, twirp.RequiredArgumentError("conversation_id")
 	}
 
+	ctx = logx.WithConversationID(ctx, req.GetConversationId())
+
 	conversation, err := s.repo.DescribeConversation(ctx, req.GetConversationId())
 	if err != nil {
 		return nil, err
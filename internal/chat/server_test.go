@@ -2,12 +2,24 @@ package chat
 
 import (
 	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/acai-travel/tech-challenge/internal/chat/model"
 	. "github.com/acai-travel/tech-challenge/internal/chat/testing"
+	"github.com/acai-travel/tech-challenge/internal/events"
+	"github.com/acai-travel/tech-challenge/internal/httpx"
 	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/acai-travel/tech-challenge/internal/webhook"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/uuid"
 	"github.com/twitchtv/twirp"
 	"google.golang.org/protobuf/testing/protocmp"
 )
@@ -17,6 +29,15 @@ type fakeAssistant struct {
 	reply    string
 	titleErr error
 	replyErr error
+	replies  atomic.Int32
+
+	suggestions []string
+	suggestErr  error
+
+	retitle    string
+	retitleErr error
+	retitled   []*model.Conversation
+	mu         sync.Mutex
 }
 
 func TestServer_DescribeConversation(t *testing.T) {
@@ -52,16 +73,28 @@ func TestServer_DescribeConversation(t *testing.T) {
 func (f *fakeAssistant) Title(ctx context.Context, _ *model.Conversation) (string, error) {
 	return f.title, f.titleErr
 }
-func (f *fakeAssistant) Reply(ctx context.Context, _ *model.Conversation) (string, error) {
+func (f *fakeAssistant) Reply(ctx context.Context, _ *model.Conversation, _ *model.Generation) (string, error) {
+	f.replies.Add(1)
 	return f.reply, f.replyErr
 }
+func (f *fakeAssistant) Suggest(ctx context.Context, _ *model.Conversation) ([]string, error) {
+	return f.suggestions, f.suggestErr
+}
+func (f *fakeAssistant) Retitle(ctx context.Context, conv *model.Conversation) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.retitled = append(f.retitled, conv)
+	return f.retitle, f.retitleErr
+}
 
 func TestServer_StartConversation_Success(t *testing.T) {
 	ctx := context.Background()
 
+	suggestions := []string{"Is tomorrow a holiday?", "What's the forecast for the weekend?"}
 	srv := NewServer(model.New(ConnectMongo()), &fakeAssistant{
-		title: "Weather in Barcelona",
-		reply: "25°C and sunny",
+		title:       "Weather in Barcelona",
+		reply:       "25°C and sunny",
+		suggestions: suggestions,
 	})
 
 	req := &pb.StartConversationRequest{Message: "What's the weather in Barcelona?"}
@@ -79,6 +112,9 @@ func TestServer_StartConversation_Success(t *testing.T) {
 	if got, want := resp.GetReply(), "25°C and sunny"; got != want {
 		t.Fatalf("reply: got %q, want %q", got, want)
 	}
+	if diff := cmp.Diff(resp.GetSuggestions(), suggestions); diff != "" {
+		t.Fatalf("suggestions mismatch (-got +want):\n%s", diff)
+	}
 
 	out, err := srv.DescribeConversation(ctx, &pb.DescribeConversationRequest{
 		ConversationId: resp.GetConversationId(),
@@ -96,4 +132,495 @@ func TestServer_StartConversation_Success(t *testing.T) {
 	if msgs[1].GetRole() != pb.Conversation_ASSISTANT || msgs[1].GetContent() != "25°C and sunny" {
 		t.Fatalf("assistant message mismatch: role=%v content=%q", msgs[1].GetRole(), msgs[1].GetContent())
 	}
+	if diff := cmp.Diff(msgs[1].GetSuggestions(), suggestions); diff != "" {
+		t.Fatalf("saved suggestions mismatch (-got +want):\n%s", diff)
+	}
+}
+
+func TestServer_Idempotency(t *testing.T) {
+	ctx := context.Background()
+
+	assist := &fakeAssistant{title: "Weather in Barcelona", reply: "25°C and sunny"}
+	srv := NewServer(model.New(ConnectMongo()), assist)
+
+	req := &pb.StartConversationRequest{Message: "What's the weather in Barcelona?", IdempotencyKey: uuid.NewString()}
+	first, err := srv.StartConversation(ctx, req)
+	if err != nil {
+		t.Fatalf("StartConversation error: %v", err)
+	}
+
+	t.Run("retry returns the original response", func(t *testing.T) {
+		retried, err := srv.StartConversation(ctx, req)
+		if err != nil {
+			t.Fatalf("StartConversation retry error: %v", err)
+		}
+		if !cmp.Equal(retried, first, protocmp.Transform()) {
+			t.Errorf("retry mismatch (-got +want):\n%s", cmp.Diff(retried, first, protocmp.Transform()))
+		}
+		if got := assist.replies.Load(); got != 1 {
+			t.Fatalf("assistant replies: got %d, want 1", got)
+		}
+	})
+
+	t.Run("continue is not applied twice", func(t *testing.T) {
+		cont := &pb.ContinueConversationRequest{ConversationId: first.GetConversationId(), Message: "And tomorrow?", IdempotencyKey: uuid.NewString()}
+		for range 2 {
+			if _, err := srv.ContinueConversation(ctx, cont); err != nil {
+				t.Fatalf("ContinueConversation error: %v", err)
+			}
+		}
+
+		out, err := srv.DescribeConversation(ctx, &pb.DescribeConversationRequest{ConversationId: first.GetConversationId()})
+		if err != nil {
+			t.Fatalf("DescribeConversation error: %v", err)
+		}
+		if got, want := len(out.GetConversation().GetMessages()), 4; got != want {
+			t.Fatalf("messages: got %d, want %d", got, want)
+		}
+	})
+
+	t.Run("key reused for another request is rejected", func(t *testing.T) {
+		_, err := srv.StartConversation(ctx, &pb.StartConversationRequest{Message: "Something else", IdempotencyKey: req.GetIdempotencyKey()})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.InvalidArgument {
+			t.Fatalf("expected twirp.InvalidArgument error, got %v", err)
+		}
+	})
+
+	t.Run("keys are scoped to the client", func(t *testing.T) {
+		other, err := srv.StartConversation(clientContext("203.0.113.7"), req)
+		if err != nil {
+			t.Fatalf("StartConversation error: %v", err)
+		}
+		if other.GetConversationId() == first.GetConversationId() {
+			t.Fatalf("conversation ID: got %q from another client, want a new conversation", other.GetConversationId())
+		}
+	})
+}
+
+// clientContext returns a request context identifying the caller as client.
+func clientContext(client string) context.Context {
+	var ctx context.Context
+	handler := httpx.Identity()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	}))
+
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.RemoteAddr = net.JoinHostPort(client, "1234")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	return ctx
+}
+
+func TestServer_Async(t *testing.T) {
+	ctx := context.Background()
+	assist := &fakeAssistant{title: "Weather in Barcelona", replyErr: errors.New("model unavailable")}
+	repo := model.New(ConnectMongo())
+	srv := NewServer(repo, assist)
+
+	status := func(convID, msgID string) *pb.Conversation_Message {
+		t.Helper()
+		if err := srv.Wait(ctx); err != nil {
+			t.Fatalf("Wait error: %v", err)
+		}
+		out, err := srv.GetMessageStatus(ctx, &pb.GetMessageStatusRequest{ConversationId: convID, MessageId: msgID})
+		if err != nil {
+			t.Fatalf("GetMessageStatus error: %v", err)
+		}
+		return out.GetMessage()
+	}
+
+	start, err := srv.StartConversation(ctx, &pb.StartConversationRequest{Message: "What's the weather in Barcelona?", Async: true})
+	if err != nil {
+		t.Fatalf("StartConversation error: %v", err)
+	}
+	if got, want := start.GetStatus(), pb.Conversation_PENDING; got != want {
+		t.Fatalf("status: got %v, want %v", got, want)
+	}
+
+	convID := start.GetConversationId()
+	if got, want := status(convID, start.GetMessageId()).GetStatus(), pb.Conversation_FAILED; got != want {
+		t.Fatalf("status after failure: got %v, want %v", got, want)
+	}
+
+	// Unknown messages cannot be retried.
+	if _, err := srv.RetryMessage(ctx, &pb.RetryMessageRequest{ConversationId: convID, MessageId: "08a59244257c872c5943e2a2"}); err == nil {
+		t.Fatal("expected error retrying an unknown message")
+	}
+
+	assist.replyErr = nil
+	assist.reply = "25°C and sunny"
+	if _, err := srv.RetryMessage(ctx, &pb.RetryMessageRequest{ConversationId: convID, MessageId: start.GetMessageId()}); err != nil {
+		t.Fatalf("RetryMessage error: %v", err)
+	}
+
+	msg := status(convID, start.GetMessageId())
+	if got, want := msg.GetStatus(), pb.Conversation_COMPLETE; got != want {
+		t.Fatalf("status after retry: got %v, want %v", got, want)
+	}
+	if got, want := msg.GetContent(), "25°C and sunny"; got != want {
+		t.Fatalf("reply: got %q, want %q", got, want)
+	}
+
+	conv, err := srv.DescribeConversation(ctx, &pb.DescribeConversationRequest{ConversationId: convID})
+	if err != nil {
+		t.Fatalf("DescribeConversation error: %v", err)
+	}
+	if got, want := conv.GetConversation().GetTitle(), "Weather in Barcelona"; got != want {
+		t.Fatalf("title: got %q, want %q", got, want)
+	}
+
+	// Completed replies cannot be retried.
+	_, err = srv.RetryMessage(ctx, &pb.RetryMessageRequest{ConversationId: convID, MessageId: start.GetMessageId()})
+	if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.FailedPrecondition {
+		t.Fatalf("retry completed reply: got %v, want FailedPrecondition", err)
+	}
+
+	// The title and the failed reply are generated concurrently, in any order.
+	got := conversationWebhooks(t, repo, convID)
+	if len(got) > 0 && got[0] != webhook.ConversationCreated {
+		t.Fatalf("first webhook: got %q, want %q", got[0], webhook.ConversationCreated)
+	}
+	want := []webhook.EventType{webhook.ConversationCreated, webhook.TitleChanged, webhook.ReplyFailed, webhook.ReplyCreated}
+	sorted := cmpopts.SortSlices(func(a, b webhook.EventType) bool { return a < b })
+	if !cmp.Equal(got, want, sorted) || got[len(got)-1] != webhook.ReplyCreated {
+		t.Fatalf("webhooks mismatch (-got +want):\n%s", cmp.Diff(got, want))
+	}
+}
+
+// conversationWebhooks relays the outbox and returns the webhook events of the
+// conversation, in order.
+func conversationWebhooks(t *testing.T, repo *model.Repository, convID string) []webhook.EventType {
+	t.Helper()
+
+	sink := &webhookRecorder{conversationID: convID}
+	relay := events.NewRelay(repo, sink)
+	for {
+		n, err := relay.Dispatch(context.Background())
+		if err != nil {
+			t.Fatalf("Dispatch error: %v", err)
+		}
+		if n == 0 {
+			return sink.events
+		}
+	}
+}
+
+type webhookRecorder struct {
+	conversationID string
+	events         []webhook.EventType
+}
+
+func (*webhookRecorder) Name() string { return "recorder" }
+
+func (r *webhookRecorder) Handle(_ context.Context, e events.Event) error {
+	if e.ConversationID != r.conversationID {
+		return nil
+	}
+	wes, err := events.WebhookEvents(e)
+	if err != nil {
+		return err
+	}
+	for _, we := range wes {
+		r.events = append(r.events, we.Type)
+	}
+	return nil
+}
+
+func TestServer_Reserve(t *testing.T) {
+	ctx := context.Background()
+	srv := &Server{Workers: 1, MaxQueued: 1}
+
+	release := make(chan struct{})
+	running, err := srv.reserve()
+	if err != nil {
+		t.Fatalf("reserve error: %v", err)
+	}
+	srv.background(ctx, running, func(context.Context) { <-release })
+
+	queued, err := srv.reserve()
+	if err != nil {
+		t.Fatalf("reserve error: %v", err)
+	}
+	_, err = srv.reserve()
+	if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.ResourceExhausted {
+		t.Fatalf("reserve with a full queue: got %v, want ResourceExhausted", err)
+	}
+
+	// A place given back can be taken again.
+	queued.release()
+	queued, err = srv.reserve()
+	if err != nil {
+		t.Fatalf("reserve after release error: %v", err)
+	}
+	var ran atomic.Bool
+	srv.background(ctx, queued, func(context.Context) { ran.Store(true) })
+
+	close(release)
+	if err := srv.Wait(ctx); err != nil {
+		t.Fatalf("Wait error: %v", err)
+	}
+	if !ran.Load() {
+		t.Fatal("queued job did not run")
+	}
+	if _, err := srv.reserve(); err != nil {
+		t.Fatalf("reserve once idle error: %v", err)
+	}
+}
+
+func TestServer_Retitle(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("titles are revised after a milestone turn", WithFixture(func(t *testing.T, f *Fixture) {
+		assist := &fakeAssistant{reply: "Sure", retitle: "Holidays in Rome"}
+		srv := NewServer(f.Repository, assist)
+		srv.RetitleAfter = []int{2}
+		c := f.CreateConversation()
+
+		title := func() string {
+			t.Helper()
+			if err := srv.Wait(ctx); err != nil {
+				t.Fatalf("Wait error: %v", err)
+			}
+			out, err := srv.DescribeConversation(ctx, &pb.DescribeConversationRequest{ConversationId: c.ID.Hex()})
+			if err != nil {
+				t.Fatalf("DescribeConversation error: %v", err)
+			}
+			return out.GetConversation().GetTitle()
+		}
+
+		for _, msg := range []string{"Plan a trip to Rome", "Which days are holidays?"} {
+			if _, err := srv.ContinueConversation(ctx, &pb.ContinueConversationRequest{ConversationId: c.ID.Hex(), Message: msg}); err != nil {
+				t.Fatalf("ContinueConversation error: %v", err)
+			}
+		}
+		if got, want := title(), "Holidays in Rome"; got != want {
+			t.Fatalf("title: got %q, want %q", got, want)
+		}
+		if got, want := len(assist.retitled), 1; got != want {
+			t.Fatalf("retitled %d times, want %d", got, want)
+		}
+		if got, want := assist.retitled[0].Title, c.Title; got != want {
+			t.Fatalf("current title: got %q, want %q", got, want)
+		}
+	}))
+
+	t.Run("renamed conversations keep their title", WithFixture(func(t *testing.T, f *Fixture) {
+		assist := &fakeAssistant{reply: "Sure", retitle: "Holidays in Rome"}
+		srv := NewServer(f.Repository, assist)
+		srv.RetitleAfter = []int{2}
+		c := f.CreateConversation()
+
+		if _, err := srv.RenameConversation(ctx, &pb.RenameConversationRequest{ConversationId: c.ID.Hex(), Title: "My trip"}); err != nil {
+			t.Fatalf("RenameConversation error: %v", err)
+		}
+		if _, err := srv.ContinueConversation(ctx, &pb.ContinueConversationRequest{ConversationId: c.ID.Hex(), Message: "Which days are holidays?"}); err != nil {
+			t.Fatalf("ContinueConversation error: %v", err)
+		}
+		if err := srv.Wait(ctx); err != nil {
+			t.Fatalf("Wait error: %v", err)
+		}
+		if len(assist.retitled) != 0 {
+			t.Fatal("expected renamed conversation not to be retitled")
+		}
+
+		// Regenerating the title on demand replaces the name chosen by the user.
+		out, err := srv.RegenerateTitle(ctx, &pb.RegenerateTitleRequest{ConversationId: c.ID.Hex()})
+		if err != nil {
+			t.Fatalf("RegenerateTitle error: %v", err)
+		}
+		if got, want := out.GetTitle(), "Holidays in Rome"; got != want {
+			t.Fatalf("title: got %q, want %q", got, want)
+		}
+		if got := assist.retitled[0].Title; got != "" {
+			t.Fatalf("current title: got %q, want none", got)
+		}
+
+		desc, err := srv.DescribeConversation(ctx, &pb.DescribeConversationRequest{ConversationId: c.ID.Hex()})
+		if err != nil {
+			t.Fatalf("DescribeConversation error: %v", err)
+		}
+		if got := desc.GetConversation(); got.GetTitle() != "Holidays in Rome" || got.GetRenamed() {
+			t.Fatalf("conversation: got title %q renamed %v, want regenerated title", got.GetTitle(), got.GetRenamed())
+		}
+	}))
+
+	t.Run("titles must fit on a line", func(t *testing.T) {
+		srv := NewServer(model.New(ConnectMongo()), &fakeAssistant{})
+		for _, title := range []string{" ", strings.Repeat("a", 81)} {
+			_, err := srv.RenameConversation(ctx, &pb.RenameConversationRequest{ConversationId: "08a59244257c872c5943e2a2", Title: title})
+			if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.InvalidArgument {
+				t.Fatalf("rename to %q: got %v, want InvalidArgument", title, err)
+			}
+		}
+	})
+}
+
+func TestServer_SubmitFeedback(t *testing.T) {
+	ctx := context.Background()
+
+	repo := model.New(ConnectMongo())
+	srv := NewServer(repo, &fakeAssistant{title: "Weather in Barcelona", reply: "25°C and sunny"})
+
+	start, err := srv.StartConversation(ctx, &pb.StartConversationRequest{Message: "What's the weather in Barcelona?"})
+	if err != nil {
+		t.Fatalf("StartConversation error: %v", err)
+	}
+
+	t.Run("feedback is stored with the reply", func(t *testing.T) {
+		for _, rating := range []pb.Feedback_Rating{pb.Feedback_GOOD, pb.Feedback_BAD} {
+			_, err := srv.SubmitFeedback(ctx, &pb.SubmitFeedbackRequest{
+				ConversationId: start.GetConversationId(),
+				MessageId:      start.GetMessageId(),
+				Rating:         rating,
+				Reason:         pb.Feedback_INACCURATE,
+				Comment:        "It is raining",
+			})
+			if err != nil {
+				t.Fatalf("SubmitFeedback error: %v", err)
+			}
+		}
+
+		feedback, err := repo.ListFeedback(ctx, model.FeedbackFilter{Rating: model.RatingBad}, 100)
+		if err != nil {
+			t.Fatalf("ListFeedback error: %v", err)
+		}
+		var got *pb.Feedback
+		for _, f := range feedback {
+			if f.MessageID.Hex() == start.GetMessageId() {
+				got = f.Proto()
+			}
+		}
+
+		want := &pb.Feedback{
+			ConversationId: start.GetConversationId(),
+			MessageId:      start.GetMessageId(),
+			Rating:         pb.Feedback_BAD,
+			Reason:         pb.Feedback_INACCURATE,
+			Comment:        "It is raining",
+			Question:       "What's the weather in Barcelona?",
+			Reply:          "25°C and sunny",
+		}
+		if !cmp.Equal(got, want, protocmp.Transform(), protocmp.IgnoreFields(&pb.Feedback{}, "timestamp")) {
+			t.Fatalf("feedback mismatch (-got +want):\n%s", cmp.Diff(got, want, protocmp.Transform(), protocmp.IgnoreFields(&pb.Feedback{}, "timestamp")))
+		}
+	})
+
+	t.Run("only replies can be rated", func(t *testing.T) {
+		out, err := srv.DescribeConversation(ctx, &pb.DescribeConversationRequest{ConversationId: start.GetConversationId()})
+		if err != nil {
+			t.Fatalf("DescribeConversation error: %v", err)
+		}
+
+		_, err = srv.SubmitFeedback(ctx, &pb.SubmitFeedbackRequest{
+			ConversationId: start.GetConversationId(),
+			MessageId:      out.GetConversation().GetMessages()[0].GetId(),
+			Rating:         pb.Feedback_GOOD,
+		})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.FailedPrecondition {
+			t.Fatalf("rating a question: got %v, want a FailedPrecondition error", err)
+		}
+	})
+
+	t.Run("rating is required", func(t *testing.T) {
+		_, err := srv.SubmitFeedback(ctx, &pb.SubmitFeedbackRequest{
+			ConversationId: start.GetConversationId(),
+			MessageId:      start.GetMessageId(),
+		})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.InvalidArgument {
+			t.Fatalf("no rating: got %v, want an InvalidArgument error", err)
+		}
+	})
+}
+
+func TestServer_StartConversation_Persona(t *testing.T) {
+	ctx := context.Background()
+
+	repo := model.New(ConnectMongo())
+	srv := NewServer(repo, &fakeAssistant{title: "Trip to Rome", reply: "Book early"})
+
+	name := "travel-agent-" + uuid.NewString()[:8]
+	if err := repo.CreatePersona(ctx, &model.Persona{Name: name, SystemPrompt: "You are a travel agent."}); err != nil {
+		t.Fatalf("CreatePersona error: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.DeletePersona(ctx, name) })
+
+	t.Run("persona is stored on the conversation", func(t *testing.T) {
+		resp, err := srv.StartConversation(ctx, &pb.StartConversationRequest{Message: "Plan a trip to Rome", Persona: name})
+		if err != nil {
+			t.Fatalf("StartConversation error: %v", err)
+		}
+
+		out, err := srv.DescribeConversation(ctx, &pb.DescribeConversationRequest{ConversationId: resp.GetConversationId()})
+		if err != nil {
+			t.Fatalf("DescribeConversation error: %v", err)
+		}
+		if got := out.GetConversation().GetPersona(); got != name {
+			t.Fatalf("persona: got %q, want %q", got, name)
+		}
+	})
+
+	t.Run("unknown persona is rejected", func(t *testing.T) {
+		_, err := srv.StartConversation(ctx, &pb.StartConversationRequest{Message: "Plan a trip to Rome", Persona: "nobody"})
+		if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.InvalidArgument {
+			t.Fatalf("unknown persona: got %v, want an InvalidArgument error", err)
+		}
+	})
+}
+
+func TestServer_Generation(t *testing.T) {
+	srv := NewServer(nil, nil)
+	srv.AllowedModels = []string{"gpt-4.1", "o4-mini"}
+	temperature := 0.7
+
+	t.Run("valid options", func(t *testing.T) {
+		for _, tt := range []struct {
+			opts *pb.GenerationOptions
+			want *model.Generation
+		}{
+			{
+				opts: &pb.GenerationOptions{Model: "o4-mini", MaxOutputTokens: 200, ReasoningEffort: pb.GenerationOptions_LOW},
+				want: &model.Generation{Model: "o4-mini", MaxOutputTokens: 200, ReasoningEffort: "low"},
+			},
+			{
+				opts: &pb.GenerationOptions{Model: "gpt-4.1", Temperature: &temperature},
+				want: &model.Generation{Model: "gpt-4.1", Temperature: &temperature},
+			},
+			{
+				// Left to the assistant to drop for the model replying.
+				opts: &pb.GenerationOptions{Temperature: &temperature, ReasoningEffort: pb.GenerationOptions_HIGH},
+				want: &model.Generation{Temperature: &temperature, ReasoningEffort: "high"},
+			},
+		} {
+			got, err := srv.generation(tt.opts)
+			if err != nil {
+				t.Fatalf("generation(%v) error: %v", tt.opts, err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Fatalf("generation(%v) mismatch (-got +want):\n%s", tt.opts, diff)
+			}
+		}
+	})
+
+	t.Run("no options", func(t *testing.T) {
+		for _, opts := range []*pb.GenerationOptions{nil, {}} {
+			if got, err := srv.generation(opts); got != nil || err != nil {
+				t.Fatalf("generation(%v): got %v, %v, want nil", opts, got, err)
+			}
+		}
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		tooHot := 2.5
+		for _, opts := range []*pb.GenerationOptions{
+			{Model: "o1"},
+			{Temperature: &tooHot},
+			{MaxOutputTokens: -1},
+			{Model: "o4-mini", Temperature: &temperature},
+			{Model: "gpt-4.1", ReasoningEffort: pb.GenerationOptions_LOW},
+		} {
+			_, err := srv.generation(opts)
+			if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.InvalidArgument {
+				t.Fatalf("generation(%v): got %v, want an InvalidArgument error", opts, err)
+			}
+		}
+	})
 }
@@ -0,0 +1,161 @@
+package chat
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	maxSyncLimit = 100
+
+	// syncLag keeps syncs behind the latest writes, for those still in a
+	// transaction to commit before a sync moves past their updated_at.
+	syncLag = 5 * time.Second
+)
+
+func (s *Server) SyncConversations(ctx context.Context, req *pb.SyncConversationsRequest) (*pb.SyncConversationsResponse, error) {
+	limit := int(req.GetLimit())
+	if limit < 0 || limit > maxSyncLimit {
+		return nil, twirp.InvalidArgumentError("limit", "must be between 1 and 100")
+	}
+	if limit == 0 {
+		limit = maxSyncLimit
+	}
+
+	from, err := parseSyncCursor(req.GetCursor())
+	if err != nil {
+		return nil, twirp.InvalidArgumentError("cursor", "is not a cursor returned by SyncConversations")
+	}
+
+	// Deletions older than tombstones are kept for are unknown.
+	now := time.Now()
+	if !from.UpdatedAt.IsZero() && from.UpdatedAt.Before(now.Add(-model.TombstoneRetention)) {
+		return &pb.SyncConversationsResponse{Resync: true}, nil
+	}
+
+	until := now.Add(-syncLag).Truncate(time.Millisecond)
+	conversations, err := s.repo.ChangedConversations(ctx, from.SyncPosition, until, limit)
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	// The next page returns the messages changed since the same time as this
+	// one: its conversations may have changed before this page's position too.
+	// Only once every change up to until is returned does the next sync start
+	// from there.
+	resp := &pb.SyncConversationsResponse{}
+	next := from
+	if len(conversations) == limit {
+		last := conversations[len(conversations)-1]
+		next.SyncPosition = model.SyncPosition{UpdatedAt: last.UpdatedAt, ID: last.ID}
+		resp.HasMore = true
+	} else if until.After(from.UpdatedAt) {
+		next = syncCursor{SyncPosition: model.SyncPosition{UpdatedAt: until}, Since: until}
+	}
+	resp.NextCursor = formatSyncCursor(next)
+
+	for _, conv := range conversations {
+		resp.Conversations = append(resp.Conversations, syncedConversation(conv, from.Since).Proto())
+	}
+
+	// A first sync has nothing to delete.
+	if !from.UpdatedAt.IsZero() {
+		deleted, err := s.repo.DeletedConversations(ctx, from.UpdatedAt, next.UpdatedAt)
+		if err != nil {
+			return nil, twirp.InternalErrorWith(err)
+		}
+		for _, id := range deleted {
+			resp.DeletedConversationIds = append(resp.DeletedConversationIds, id.Hex())
+		}
+	}
+
+	return resp, nil
+}
+
+// syncedConversation returns a copy of conv holding only the messages added or
+// updated since.
+func syncedConversation(conv *model.Conversation, since time.Time) *model.Conversation {
+	synced := *conv
+	synced.Messages = nil
+	for _, m := range conv.Messages {
+		if !m.UpdatedAt.Before(since) {
+			synced.Messages = append(synced.Messages, m)
+		}
+	}
+	return &synced
+}
+
+// syncCursor is where a sync stopped, and since when it returns the changed
+// messages: the position the client started paging from.
+type syncCursor struct {
+	model.SyncPosition
+	Since time.Time
+}
+
+// Cursors are opaque to clients, they encode the position and since as
+// "<updated_at in ms>.<conversation ID>.<since in ms>". Cursors from before
+// since was added lack it, since is then the position.
+
+func formatSyncCursor(c syncCursor) string {
+	if c.UpdatedAt.IsZero() {
+		return ""
+	}
+
+	var id, since string
+	if !c.ID.IsZero() {
+		id = c.ID.Hex()
+	}
+	if !c.Since.IsZero() {
+		since = strconv.FormatInt(c.Since.UnixMilli(), 10)
+	}
+	return base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "%d.%s.%s", c.UpdatedAt.UnixMilli(), id, since))
+}
+
+func parseSyncCursor(cursor string) (syncCursor, error) {
+	if cursor == "" {
+		return syncCursor{}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return syncCursor{}, err
+	}
+	ms, rest, _ := strings.Cut(string(raw), ".")
+	id, since, hasSince := strings.Cut(rest, ".")
+
+	var c syncCursor
+	if c.UpdatedAt, err = parseCursorTime(ms); err != nil {
+		return syncCursor{}, err
+	}
+	if id != "" {
+		if c.ID, err = primitive.ObjectIDFromHex(id); err != nil {
+			return syncCursor{}, err
+		}
+	}
+	switch {
+	case !hasSince:
+		c.Since = c.UpdatedAt
+	case since != "":
+		if c.Since, err = parseCursorTime(since); err != nil {
+			return syncCursor{}, err
+		}
+	}
+	return c, nil
+}
+
+func parseCursorTime(ms string) (time.Time, error) {
+	t, err := strconv.ParseInt(ms, 10, 64)
+	if err != nil || t <= 0 {
+		return time.Time{}, fmt.Errorf("invalid cursor time %q", ms)
+	}
+	return time.UnixMilli(t), nil
+}
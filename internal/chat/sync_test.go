@@ -0,0 +1,121 @@
+package chat
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	. "github.com/acai-travel/tech-challenge/internal/chat/testing"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/google/go-cmp/cmp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSyncCursor(t *testing.T) {
+	at := time.UnixMilli(time.Now().UnixMilli())
+	since := at.Add(-time.Hour)
+
+	for _, c := range []syncCursor{
+		{},
+		{SyncPosition: model.SyncPosition{UpdatedAt: at}, Since: at},
+		{SyncPosition: model.SyncPosition{UpdatedAt: at, ID: primitive.NewObjectID()}, Since: since},
+		{SyncPosition: model.SyncPosition{UpdatedAt: at, ID: primitive.NewObjectID()}},
+	} {
+		got, err := parseSyncCursor(formatSyncCursor(c))
+		if err != nil {
+			t.Fatalf("parse %+v: %v", c, err)
+		}
+		if !got.UpdatedAt.Equal(c.UpdatedAt) || got.ID != c.ID || !got.Since.Equal(c.Since) {
+			t.Fatalf("round trip: got %+v, want %+v", got, c)
+		}
+	}
+
+	// Cursors without since continue from their position.
+	legacy := base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "%d.", at.UnixMilli()))
+	if got, err := parseSyncCursor(legacy); err != nil || !got.Since.Equal(at) {
+		t.Fatalf("parse legacy cursor: got %+v, %v, want since %v", got, err, at)
+	}
+
+	for _, cursor := range []string{"not base64!", "bm90LWEtY3Vyc29y", "MTIzLnh5eg"} {
+		if _, err := parseSyncCursor(cursor); err == nil {
+			t.Fatalf("parse %q: got no error, want one", cursor)
+		}
+	}
+}
+
+func TestSyncedConversation(t *testing.T) {
+	since := time.Now()
+	old := &model.Message{ID: primitive.NewObjectID(), Content: "old", UpdatedAt: since.Add(-time.Minute)}
+	updated := &model.Message{ID: primitive.NewObjectID(), Content: "updated", UpdatedAt: since}
+	added := &model.Message{ID: primitive.NewObjectID(), Content: "added", UpdatedAt: since.Add(time.Second)}
+	conv := &model.Conversation{ID: primitive.NewObjectID(), Messages: []*model.Message{old, updated, added}}
+
+	var got []string
+	for _, m := range syncedConversation(conv, since).Messages {
+		got = append(got, m.Content)
+	}
+
+	want := []string{"updated", "added"}
+	if !cmp.Equal(got, want) {
+		t.Fatalf("messages mismatch (-got +want):\n%s", cmp.Diff(got, want))
+	}
+	if len(conv.Messages) != 3 {
+		t.Fatalf("conversation modified: got %d messages, want 3", len(conv.Messages))
+	}
+}
+
+func TestServer_SyncConversations_Pages(t *testing.T) {
+	ctx := context.Background()
+	db := ConnectMongo()
+	repo := model.New(db)
+	srv := NewServer(repo, nil)
+
+	// The client last synced at base. Since then, a message was added to b
+	// before a was updated, and b was updated again after a.
+	base := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+	message := func(content string, at time.Time) *model.Message {
+		return &model.Message{ID: primitive.NewObjectID(), Role: model.RoleUser, Content: content, CreatedAt: at, UpdatedAt: at}
+	}
+	a := &model.Conversation{ID: primitive.NewObjectID(), Title: "a", CreatedAt: base.Add(-time.Minute), UpdatedAt: base.Add(time.Minute),
+		Messages: []*model.Message{message("a1", base.Add(-time.Minute)), message("a2", base.Add(time.Minute))}}
+	b := &model.Conversation{ID: primitive.NewObjectID(), Title: "b", CreatedAt: base.Add(-time.Minute), UpdatedAt: base.Add(2 * time.Minute),
+		Messages: []*model.Message{message("b1", base.Add(-time.Minute)), message("b2", base.Add(30*time.Second)), message("b3", base.Add(2*time.Minute))}}
+
+	// Written directly, for the repository stamps the current time.
+	for _, c := range []*model.Conversation{a, b} {
+		if _, err := db.Collection("conversations").InsertOne(ctx, c); err != nil {
+			t.Fatalf("insert conversation: %v", err)
+		}
+		defer func() { _ = repo.DeleteConversation(ctx, c.ID.Hex()) }()
+	}
+
+	// Pages hold a single conversation, others in the database included.
+	synced := map[string][]string{}
+	cursor := formatSyncCursor(syncCursor{SyncPosition: model.SyncPosition{UpdatedAt: base}, Since: base})
+	for {
+		resp, err := srv.SyncConversations(ctx, &pb.SyncConversationsRequest{Cursor: cursor, Limit: 1})
+		if err != nil {
+			t.Fatalf("SyncConversations error: %v", err)
+		}
+		for _, c := range resp.GetConversations() {
+			if c.GetId() != a.ID.Hex() && c.GetId() != b.ID.Hex() {
+				continue
+			}
+			for _, m := range c.GetMessages() {
+				synced[c.GetTitle()] = append(synced[c.GetTitle()], m.GetContent())
+			}
+		}
+		cursor = resp.GetNextCursor()
+		if !resp.GetHasMore() {
+			break
+		}
+	}
+
+	want := map[string][]string{"a": {"a2"}, "b": {"b2", "b3"}}
+	if diff := cmp.Diff(synced, want); diff != "" {
+		t.Fatalf("synced messages mismatch (-got +want):\n%s", diff)
+	}
+}
@@ -0,0 +1,264 @@
+package testing
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go/v2/option"
+)
+
+// FakeOpenAI is a scriptable stand-in for the OpenAI Chat Completions endpoint.
+// Responses are queued up front and served in order; every request received is
+// recorded so tests can assert on the messages and tools the assistant sent.
+//
+//	ai := NewFakeOpenAI(t).
+//		CallTool("get_weather", map[string]any{"location": "Paris"}).
+//		Reply("It is sunny in Paris")
+//	a := assistant.New(config.Defaults().Assistant, reg, ai.Options()...)
+type FakeOpenAI struct {
+	server *httptest.Server
+	test   *testing.T
+
+	mu       sync.Mutex
+	queue    []*FakeResponse
+	requests []FakeRequest
+}
+
+// FakeResponse is a queued response: either a message (text or tool calls) or,
+// when Status is set, an API error.
+type FakeResponse struct {
+	Content   string
+	ToolCalls []FakeToolCall
+	Status    int
+	Error     string
+	Delay     time.Duration
+}
+
+type FakeToolCall struct {
+	ID        string
+	Name      string
+	Arguments string
+}
+
+// FakeRequest is the part of a chat completion request tests usually assert on.
+type FakeRequest struct {
+	Model               string        `json:"model"`
+	Temperature         *float64      `json:"temperature"`
+	MaxCompletionTokens int64         `json:"max_completion_tokens"`
+	ReasoningEffort     string        `json:"reasoning_effort"`
+	Messages            []FakeMessage `json:"messages"`
+	Tools               []struct {
+		Function struct {
+			Name string `json:"name"`
+		} `json:"function"`
+	} `json:"tools"`
+}
+
+type FakeMessage struct {
+	Role       string          `json:"role"`
+	RawContent json.RawMessage `json:"content"`
+	ToolCallID string          `json:"tool_call_id"`
+	ToolCalls  []struct {
+		ID       string `json:"id"`
+		Function struct {
+			Name      string `json:"name"`
+			Arguments string `json:"arguments"`
+		} `json:"function"`
+	} `json:"tool_calls"`
+}
+
+// Content returns the text of the message, whether it was sent as a plain
+// string or as an array of text parts.
+func (m FakeMessage) Content() string {
+	var s string
+	if err := json.Unmarshal(m.RawContent, &s); err == nil {
+		return s
+	}
+
+	var parts []struct {
+		Text string `json:"text"`
+	}
+	_ = json.Unmarshal(m.RawContent, &parts)
+
+	texts := make([]string, 0, len(parts))
+	for _, p := range parts {
+		texts = append(texts, p.Text)
+	}
+	return strings.Join(texts, "")
+}
+
+// ToolNames returns the names of the tools offered to the model.
+func (r FakeRequest) ToolNames() []string {
+	out := make([]string, 0, len(r.Tools))
+	for _, t := range r.Tools {
+		out = append(out, t.Function.Name)
+	}
+	return out
+}
+
+// NewFakeOpenAI starts a fake server which is shut down when the test ends.
+// A request arriving when no response is queued fails the test.
+func NewFakeOpenAI(t *testing.T) *FakeOpenAI {
+	f := &FakeOpenAI{test: t}
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// Options configures an OpenAI client to talk to the fake server. Retries are
+// disabled so that every queued response maps to exactly one request.
+func (f *FakeOpenAI) Options() []option.RequestOption {
+	return []option.RequestOption{
+		option.WithBaseURL(f.server.URL + "/v1/"),
+		option.WithAPIKey("test"),
+		option.WithMaxRetries(0),
+	}
+}
+
+// Reply queues an assistant message with the given text.
+func (f *FakeOpenAI) Reply(content string) *FakeOpenAI {
+	return f.enqueue(&FakeResponse{Content: content})
+}
+
+// CallTool queues an assistant message requesting a single tool call. Arguments
+// are marshalled to JSON unless they already are a string.
+func (f *FakeOpenAI) CallTool(name string, args any) *FakeOpenAI {
+	return f.CallTools(FakeToolCall{Name: name, Arguments: toArguments(f.test, args)})
+}
+
+// CallTools queues an assistant message requesting several tool calls at once.
+func (f *FakeOpenAI) CallTools(calls ...FakeToolCall) *FakeOpenAI {
+	for i := range calls {
+		if calls[i].ID == "" {
+			calls[i].ID = fmt.Sprintf("call_%d_%d", len(f.queue), i)
+		}
+	}
+	return f.enqueue(&FakeResponse{ToolCalls: calls})
+}
+
+// Fail queues an API error with the given HTTP status.
+func (f *FakeOpenAI) Fail(status int, message string) *FakeOpenAI {
+	return f.enqueue(&FakeResponse{Status: status, Error: message})
+}
+
+// After delays the last queued response, e.g. to exercise timeouts.
+func (f *FakeOpenAI) After(d time.Duration) *FakeOpenAI {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.queue) == 0 {
+		f.test.Fatal("FakeOpenAI: After called with no queued response")
+	}
+	f.queue[len(f.queue)-1].Delay = d
+	return f
+}
+
+// Requests returns the requests received so far.
+func (f *FakeOpenAI) Requests() []FakeRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]FakeRequest(nil), f.requests...)
+}
+
+// Pending returns how many queued responses have not been served yet.
+func (f *FakeOpenAI) Pending() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.queue)
+}
+
+func (f *FakeOpenAI) enqueue(r *FakeResponse) *FakeOpenAI {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.queue = append(f.queue, r)
+	return f
+}
+
+func (f *FakeOpenAI) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, "/chat/completions") {
+		http.NotFound(w, r)
+		return
+	}
+
+	var req FakeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	f.requests = append(f.requests, req)
+	var res *FakeResponse
+	if len(f.queue) > 0 {
+		res, f.queue = f.queue[0], f.queue[1:]
+	}
+	f.mu.Unlock()
+
+	if res == nil {
+		f.test.Errorf("FakeOpenAI: unexpected request #%d, no response queued", len(f.Requests()))
+		res = &FakeResponse{Status: http.StatusInternalServerError, Error: "no response queued"}
+	}
+
+	if res.Delay > 0 {
+		select {
+		case <-time.After(res.Delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if res.Status != 0 && res.Status != http.StatusOK {
+		w.WriteHeader(res.Status)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"error": map[string]any{"message": res.Error, "type": "fake_error"},
+		})
+		return
+	}
+
+	message := map[string]any{"role": "assistant", "content": res.Content}
+	finish := "stop"
+	if len(res.ToolCalls) > 0 {
+		calls := make([]map[string]any, 0, len(res.ToolCalls))
+		for _, c := range res.ToolCalls {
+			calls = append(calls, map[string]any{
+				"id":       c.ID,
+				"type":     "function",
+				"function": map[string]any{"name": c.Name, "arguments": c.Arguments},
+			})
+		}
+		message = map[string]any{"role": "assistant", "content": nil, "tool_calls": calls}
+		finish = "tool_calls"
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"id":      "chatcmpl-fake",
+		"object":  "chat.completion",
+		"created": time.Now().Unix(),
+		"model":   req.Model,
+		"choices": []map[string]any{{
+			"index":         0,
+			"message":       message,
+			"finish_reason": finish,
+		}},
+		"usage": map[string]any{"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15},
+	})
+}
+
+func toArguments(t *testing.T, args any) string {
+	if s, ok := args.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(args)
+	if err != nil {
+		t.Fatalf("FakeOpenAI: failed to marshal tool arguments: %v", err)
+	}
+	return string(b)
+}
@@ -0,0 +1,119 @@
+package chat
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/logx"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/twitchtv/twirp"
+)
+
+const maxTitleLength = 80
+
+func (s *Server) RenameConversation(ctx context.Context, req *pb.RenameConversationRequest) (*pb.RenameConversationResponse, error) {
+	if req.GetConversationId() == "" {
+		return nil, twirp.RequiredArgumentError("conversation_id")
+	}
+
+	title := strings.TrimSpace(req.GetTitle())
+	if title == "" {
+		return nil, twirp.RequiredArgumentError("title")
+	}
+	if utf8.RuneCountInString(title) > maxTitleLength {
+		return nil, twirp.InvalidArgumentError("title", "must be at most 80 characters")
+	}
+
+	ctx = logx.WithConversationID(ctx, req.GetConversationId())
+
+	conversation, err := s.repo.DescribeConversation(ctx, req.GetConversationId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.RenameConversation(ctx, conversation.ID, title, true); err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+	return &pb.RenameConversationResponse{}, nil
+}
+
+func (s *Server) RegenerateTitle(ctx context.Context, req *pb.RegenerateTitleRequest) (*pb.RegenerateTitleResponse, error) {
+	if req.GetConversationId() == "" {
+		return nil, twirp.RequiredArgumentError("conversation_id")
+	}
+
+	ctx = logx.WithConversationID(ctx, req.GetConversationId())
+
+	conversation, err := s.repo.DescribeConversation(ctx, req.GetConversationId())
+	if err != nil {
+		return nil, err
+	}
+
+	// Without a current title to keep, a new one is generated.
+	history := conversation.History()
+	history.Title = ""
+
+	title, err := s.assist.Retitle(ctx, history)
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+	if strings.TrimSpace(title) == "" {
+		return nil, twirp.InternalError("generated title is empty")
+	}
+
+	if err := s.repo.RenameConversation(context.WithoutCancel(ctx), conversation.ID, title, false); err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+	return &pb.RegenerateTitleResponse{Title: title}, nil
+}
+
+// revise revises the title of conv in the background when its last reply
+// completes one of the RetitleAfter turns, in case the topic drifted.
+func (s *Server) revise(ctx context.Context, conv *model.Conversation) {
+	if conv.Renamed || !slices.Contains(s.RetitleAfter, turns(conv)) {
+		return
+	}
+
+	current := *conv
+	if current.Title == untitled {
+		current.Title = ""
+	}
+
+	// The title is not worth failing the reply for, it is revised next time.
+	p, err := s.reserve()
+	if err != nil {
+		slog.WarnContext(ctx, "Skipped revising conversation title", "error", err)
+		return
+	}
+
+	s.background(ctx, p, func(ctx context.Context) {
+		title, err := s.assist.Retitle(ctx, &current)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to revise conversation title", "error", err)
+			return
+		}
+		if strings.TrimSpace(title) == "" || title == conv.Title {
+			return
+		}
+
+		// The title is kept if the user renamed the conversation meanwhile.
+		if err := s.repo.SetTitle(ctx, conv.ID, title); err != nil {
+			slog.ErrorContext(ctx, "Failed to store conversation title", "error", err)
+		}
+	})
+}
+
+// turns counts the messages of the user in conv.
+func turns(conv *model.Conversation) int {
+	n := 0
+	for _, m := range conv.Messages {
+		if m.Role == model.RoleUser {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,128 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/logx"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/gorilla/mux"
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// watchHeartbeat is how often an idle stream is written to, so that proxies
+// keep it open.
+const watchHeartbeat = 15 * time.Second
+
+// Watch returns the WatchConversation handler, streaming the changes to the
+// conversation {id} of the route as server-sent events: the conversation first,
+// then its messages as they are added or updated and its title as it changes.
+// Streams end when the client disconnects, the conversation is deleted, or ctx
+// is done, e.g. once the server shuts down.
+func (s *Server) Watch(ctx context.Context) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)["id"]
+
+		rctx, cancel := context.WithCancel(logx.WithConversationID(r.Context(), id))
+		defer cancel()
+		defer context.AfterFunc(ctx, cancel)()
+
+		// Watch before reading the conversation, so that no change is missed.
+		changes, err := s.repo.WatchConversation(rctx, id)
+		if err != nil {
+			_ = twirp.WriteError(w, err)
+			return
+		}
+		conv, err := s.repo.DescribeConversation(rctx, id)
+		if err != nil {
+			_ = twirp.WriteError(w, err)
+			return
+		}
+
+		// Streams outlive the server's write timeout.
+		rc := http.NewResponseController(w)
+		_ = rc.SetWriteDeadline(time.Time{})
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		send := func(event string, data proto.Message) error {
+			b, err := protojson.Marshal(data)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, b); err != nil {
+				return err
+			}
+			return rc.Flush()
+		}
+
+		if err := send("conversation", conv.Proto()); err != nil {
+			return
+		}
+
+		heartbeat := time.NewTicker(watchHeartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-rctx.Done():
+				return
+			case <-heartbeat.C:
+				if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil || rc.Flush() != nil {
+					return
+				}
+			case next, ok := <-changes:
+				if !ok {
+					return
+				}
+				for _, u := range diffConversation(conv, next) {
+					if err := send(u.event, u.data); err != nil {
+						return
+					}
+				}
+				conv = next
+			}
+		}
+	})
+}
+
+type update struct {
+	event string
+	data  proto.Message
+}
+
+// diffConversation lists the changes from prev to next: a "title" update when
+// it changed, and a "message" update per message added or updated.
+func diffConversation(prev, next *model.Conversation) []update {
+	var out []update
+	if next.Title != prev.Title {
+		out = append(out, update{"title", &pb.Conversation{
+			Id:        next.ID.Hex(),
+			Title:     next.Title,
+			Timestamp: timestamppb.New(next.UpdatedAt),
+		}})
+	}
+
+	known := make(map[primitive.ObjectID]*model.Message, len(prev.Messages))
+	for _, m := range prev.Messages {
+		known[m.ID] = m
+	}
+	for _, m := range next.Messages {
+		if p, ok := known[m.ID]; ok && p.Status == m.Status && p.Content == m.Content && p.Error == m.Error {
+			continue
+		}
+		out = append(out, update{"message", m.Proto()})
+	}
+
+	return out
+}
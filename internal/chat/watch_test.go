@@ -0,0 +1,146 @@
+package chat
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	. "github.com/acai-travel/tech-challenge/internal/chat/testing"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestServer_Watch(t *testing.T) {
+	ctx := context.Background()
+	model.WatchPollInterval = 50 * time.Millisecond // where change streams are unsupported
+	repo := model.New(ConnectMongo())
+	srv := NewServer(repo, &fakeAssistant{title: "Greetings", reply: "Hello"})
+
+	router := mux.NewRouter()
+	router.Handle("/conversations/{id}/watch", srv.Watch(ctx))
+	hs := httptest.NewServer(router)
+	t.Cleanup(hs.Close)
+
+	watch := func(t *testing.T, id string) *http.Response {
+		t.Helper()
+		reqCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		t.Cleanup(cancel)
+		req, _ := http.NewRequestWithContext(reqCtx, http.MethodGet, hs.URL+"/conversations/"+id+"/watch", nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("watch error: %v", err)
+		}
+		t.Cleanup(func() { _ = resp.Body.Close() })
+		return resp
+	}
+
+	t.Run("unknown conversation is not found", func(t *testing.T) {
+		if got, want := watch(t, primitive.NewObjectID().Hex()).StatusCode, http.StatusNotFound; got != want {
+			t.Fatalf("status: got %d, want %d", got, want)
+		}
+	})
+
+	t.Run("streams the conversation then its changes", func(t *testing.T) {
+		start, err := srv.StartConversation(ctx, &pb.StartConversationRequest{Message: "Hi"})
+		if err != nil {
+			t.Fatalf("StartConversation error: %v", err)
+		}
+
+		resp := watch(t, start.GetConversationId())
+		if got, want := resp.Header.Get("Content-Type"), "text/event-stream"; got != want {
+			t.Fatalf("content type: got %q, want %q", got, want)
+		}
+
+		lines := bufio.NewScanner(resp.Body)
+		// next returns the next event and its data, or "" once the stream ended.
+		next := func() (string, string) {
+			t.Helper()
+			var event, data string
+			for lines.Scan() {
+				line := lines.Text()
+				switch {
+				case line == "" && event != "":
+					return event, data
+				case strings.HasPrefix(line, "event: "):
+					event = strings.TrimPrefix(line, "event: ")
+				case strings.HasPrefix(line, "data: "):
+					data = strings.TrimPrefix(line, "data: ")
+				}
+			}
+			return "", ""
+		}
+
+		event, data := next()
+		var conv pb.Conversation
+		if err := protojson.Unmarshal([]byte(data), &conv); event != "conversation" || err != nil {
+			t.Fatalf("first event: got %s %s, want the conversation", event, data)
+		}
+		if got, want := conv.GetTitle(), "Greetings"; got != want {
+			t.Fatalf("title: got %q, want %q", got, want)
+		}
+
+		if _, err := srv.ContinueConversation(ctx, &pb.ContinueConversationRequest{ConversationId: start.GetConversationId(), Message: "How are you?"}); err != nil {
+			t.Fatalf("ContinueConversation error: %v", err)
+		}
+
+		var got []string
+		for range 2 {
+			event, data := next()
+			var m pb.Conversation_Message
+			if err := protojson.Unmarshal([]byte(data), &m); event != "message" || err != nil {
+				t.Fatalf("event: got %s %s, want a message", event, data)
+			}
+			got = append(got, m.GetContent())
+		}
+		if diff := cmp.Diff(got, []string{"How are you?", "Hello"}); diff != "" {
+			t.Fatalf("messages mismatch (-got +want):\n%s", diff)
+		}
+
+		// The stream ends once the conversation is deleted.
+		if err := repo.DeleteConversation(ctx, start.GetConversationId()); err != nil {
+			t.Fatalf("DeleteConversation error: %v", err)
+		}
+		if event, _ := next(); event != "" {
+			t.Fatalf("expected the stream to end, got %s", event)
+		}
+	})
+}
+
+func TestDiffConversation(t *testing.T) {
+	question := &model.Message{ID: primitive.NewObjectID(), Role: model.RoleUser, Content: "Hi"}
+	pending := &model.Message{ID: primitive.NewObjectID(), Role: model.RoleAssistant, Status: model.StatusPending}
+	prev := &model.Conversation{ID: primitive.NewObjectID(), Title: untitled, Messages: []*model.Message{question, pending}}
+
+	reply := *pending
+	reply.Status = model.StatusComplete
+	reply.Content = "Hello"
+	followUp := &model.Message{ID: primitive.NewObjectID(), Role: model.RoleUser, Content: "How are you?"}
+	next := &model.Conversation{ID: prev.ID, Title: "Greetings", Messages: []*model.Message{question, &reply, followUp}}
+
+	var got []string
+	for _, u := range diffConversation(prev, next) {
+		switch data := u.data.(type) {
+		case *pb.Conversation:
+			got = append(got, u.event+" "+data.GetTitle())
+		case *pb.Conversation_Message:
+			got = append(got, u.event+" "+data.GetContent())
+		}
+	}
+
+	want := []string{"title Greetings", "message Hello", "message How are you?"}
+	if !cmp.Equal(got, want) {
+		t.Fatalf("updates mismatch (-got +want):\n%s", cmp.Diff(got, want))
+	}
+
+	if u := diffConversation(next, next); len(u) != 0 {
+		t.Fatalf("unchanged conversation: got %d updates, want none", len(u))
+	}
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(path, []byte(`{
+		"openai": {"api_key": "from-file"},
+		"assistant": {"reply_model": "gpt-4.1-mini"},
+		"server": {"addr": ":9090", "write_timeout": "5m"}
+	}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("OPENAI_API_KEY", "sk-from-env")
+	t.Setenv("HTTP_ADDR", ":7070")
+	t.Setenv("ASSISTANT_REPLY_TEMPERATURE", "0.3")
+	t.Setenv("ASSISTANT_TITLE_MAX_OUTPUT_TOKENS", "30")
+	t.Setenv("ASSISTANT_RETITLE_AFTER", "2, 5")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+
+	if got, want := string(cfg.OpenAI.APIKey), "sk-from-env"; got != want {
+		t.Fatalf("api key: got %q, want %q (env overrides file)", got, want)
+	}
+	if got, want := cfg.Server.Addr, ":7070"; got != want {
+		t.Fatalf("addr: got %q, want %q", got, want)
+	}
+	if got, want := cfg.Assistant.ReplyModel, "gpt-4.1-mini"; got != want {
+		t.Fatalf("reply model: got %q, want %q", got, want)
+	}
+	if got, want := time.Duration(cfg.Server.WriteTimeout), 5*time.Minute; got != want {
+		t.Fatalf("write timeout: got %v, want %v", got, want)
+	}
+	if got, want := cfg.Assistant.TitleModel, "gpt-4.1-mini"; got != want {
+		t.Fatalf("title model: got %q, want default %q", got, want)
+	}
+	if got := cfg.Assistant.ReplyParams.Temperature; got == nil || *got != 0.3 {
+		t.Fatalf("reply temperature: got %v, want 0.3", got)
+	}
+	if got, want := cfg.Assistant.TitleParams.MaxOutputTokens, int64(30); got != want {
+		t.Fatalf("title max output tokens: got %d, want %d", got, want)
+	}
+	if got, want := cfg.Assistant.RetitleAfter, []int{2, 5}; !slices.Equal(got, want) {
+		t.Fatalf("retitle after: got %v, want %v", got, want)
+	}
+
+	t.Run("secrets are redacted", func(t *testing.T) {
+		out := cfg.Redacted()
+		if strings.Contains(out, "sk-from-env") || strings.Contains(out, "travel") {
+			t.Fatalf("secret leaked in dump:\n%s", out)
+		}
+		if !strings.Contains(out, `"api_key": "REDACTED"`) {
+			t.Fatalf("expected redacted api key in dump:\n%s", out)
+		}
+	})
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("HTTP_WRITE_TIMEOUT", "soon")
+	t.Setenv("LOG_FORMAT", "xml")
+	t.Setenv("ASSISTANT_REPLY_REASONING_EFFORT", "extreme")
+	t.Setenv("ASSISTANT_RETITLE_AFTER", "1")
+	t.Setenv("WEBHOOK_MAX_ATTEMPTS", "100")
+
+	_, err := Load("")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !strings.Contains(err.Error(), "HTTP_WRITE_TIMEOUT") {
+		t.Fatalf("expected invalid duration to be reported, got: %v", err)
+	}
+
+	t.Setenv("HTTP_WRITE_TIMEOUT", "")
+	_, err = Load("")
+	for _, want := range []string{"OPENAI_API_KEY", "log.format", "assistant.reply_params: reasoning_effort", "assistant.retitle_after", "webhooks.max_attempts"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %s to be reported, got: %v", want, err)
+		}
+	}
+}
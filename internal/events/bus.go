@@ -0,0 +1,86 @@
+package events
+
+import (
+	"strings"
+	"sync"
+)
+
+// Publisher publishes a message on a subject. It is satisfied by Bus, and by a
+// NATS connection (*nats.Conn) to share the events with other processes.
+type Publisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// Msg is a message delivered by the Bus.
+type Msg struct {
+	Subject string
+	Data    []byte
+}
+
+// Bus is an in-process stand-in for NATS core messaging. Subjects are made of
+// dot-separated tokens, and subscriptions use NATS wildcards: "*" matches a
+// single token and ">" all the remaining ones. Messages are delivered at most
+// once, synchronously, so handlers must not block.
+type Bus struct {
+	mu   sync.RWMutex
+	next int
+	subs map[int]subscription
+}
+
+type subscription struct {
+	pattern []string
+	handler func(Msg)
+}
+
+func NewBus() *Bus {
+	return &Bus{subs: map[int]subscription{}}
+}
+
+// Subscribe calls handler with the messages published on subjects matching
+// pattern, until unsubscribe is called.
+func (b *Bus) Subscribe(pattern string, handler func(Msg)) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.next
+	b.next++
+	b.subs[id] = subscription{pattern: strings.Split(pattern, "."), handler: handler}
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subs, id)
+	}
+}
+
+func (b *Bus) Publish(subject string, data []byte) error {
+	tokens := strings.Split(subject, ".")
+
+	b.mu.RLock()
+	var handlers []func(Msg)
+	for _, s := range b.subs {
+		if match(s.pattern, tokens) {
+			handlers = append(handlers, s.handler)
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, h := range handlers {
+		h(Msg{Subject: subject, Data: data})
+	}
+	return nil
+}
+
+func match(pattern, subject []string) bool {
+	for i, p := range pattern {
+		switch {
+		case p == ">":
+			return len(subject) > i
+		case i >= len(subject):
+			return false
+		case p != "*" && p != subject[i]:
+			return false
+		}
+	}
+	return len(pattern) == len(subject)
+}
@@ -0,0 +1,90 @@
+// Package events relays the changes to conversations, recorded in the outbox by
+// the repository, to pluggable sinks: the log, webhooks, or a NATS-compatible
+// bus. Caches, search indexes and analytics can so stay consistent without
+// polling the conversations.
+package events
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// SubjectPrefix prefixes the bus subject of every event, see Event.Subject.
+const SubjectPrefix = "acai.conversations"
+
+// Event is a change to a conversation, as handed to sinks. Conversation is set
+// when it started, with its messages, and when its title changed, without.
+// Message is set for message events.
+type Event struct {
+	ID             string
+	Type           model.EventType
+	ConversationID string
+	Time           time.Time
+	Conversation   *pb.Conversation
+	Message        *pb.Conversation_Message
+}
+
+// FromModel converts an event read from the outbox.
+func FromModel(e *model.Event) Event {
+	out := Event{
+		ID:             e.ID.Hex(),
+		Type:           e.Type,
+		ConversationID: e.ConversationID.Hex(),
+		Time:           e.CreatedAt,
+	}
+
+	switch {
+	case e.Conversation != nil:
+		out.Conversation = e.Conversation.Proto()
+	case e.Type == model.EventTitleChanged:
+		out.Conversation = &pb.Conversation{
+			Id:        out.ConversationID,
+			Title:     e.Title,
+			Timestamp: timestamppb.New(e.CreatedAt),
+		}
+	}
+	if e.Message != nil {
+		out.Message = e.Message.Proto()
+	}
+
+	return out
+}
+
+// Subject is where the event is published on the bus, e.g.
+// "acai.conversations.<conversation ID>.message_appended". Subscribing to
+// "acai.conversations.<conversation ID>.>" follows a single conversation.
+func (e Event) Subject() string {
+	return SubjectPrefix + "." + e.ConversationID + "." + string(e.Type)
+}
+
+// MarshalJSON encodes the event with the conversation and message as returned
+// by the API.
+func (e Event) MarshalJSON() ([]byte, error) {
+	out := struct {
+		ID             string          `json:"id"`
+		Type           model.EventType `json:"type"`
+		ConversationID string          `json:"conversation_id"`
+		Time           time.Time       `json:"time"`
+		Conversation   json.RawMessage `json:"conversation,omitempty"`
+		Message        json.RawMessage `json:"message,omitempty"`
+	}{ID: e.ID, Type: e.Type, ConversationID: e.ConversationID, Time: e.Time}
+
+	var err error
+	if e.Conversation != nil {
+		if out.Conversation, err = protojson.Marshal(e.Conversation); err != nil {
+			return nil, err
+		}
+	}
+	if e.Message != nil {
+		if out.Message, err = protojson.Marshal(e.Message); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(out)
+}
@@ -0,0 +1,202 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/webhook"
+	"github.com/google/go-cmp/cmp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestBus(t *testing.T) {
+	bus := NewBus()
+
+	var got []string
+	record := func(m Msg) { got = append(got, m.Subject) }
+	bus.Subscribe("acai.conversations.*.title_changed", record)
+	bus.Subscribe("acai.conversations.42.>", record)
+	unsubscribe := bus.Subscribe("acai.conversations.7.deleted", record)
+	unsubscribe()
+
+	for _, subject := range []string{
+		"acai.conversations.7.title_changed",
+		"acai.conversations.42.message_appended",
+		"acai.conversations.7.deleted",
+		"acai.conversations.42",
+		"acai.conversations.7.title_changed.extra",
+	} {
+		_ = bus.Publish(subject, nil)
+	}
+
+	want := []string{"acai.conversations.7.title_changed", "acai.conversations.42.message_appended"}
+	if !cmp.Equal(got, want) {
+		t.Fatalf("delivered subjects mismatch (-got +want):\n%s", cmp.Diff(got, want))
+	}
+}
+
+func TestWebhookEvents(t *testing.T) {
+	convID := primitive.NewObjectID()
+	user := &model.Message{ID: primitive.NewObjectID(), Role: model.RoleUser, Content: "Hi"}
+	reply := &model.Message{ID: primitive.NewObjectID(), Role: model.RoleAssistant, Content: "Hello"}
+	pending := &model.Message{ID: primitive.NewObjectID(), Role: model.RoleAssistant, Status: model.StatusPending}
+	failed := &model.Message{ID: pending.ID, Role: model.RoleAssistant, Status: model.StatusFailed, Error: "boom"}
+
+	for name, tc := range map[string]struct {
+		event *model.Event
+		want  []webhook.EventType
+	}{
+		"started with a reply": {
+			event: &model.Event{Type: model.EventConversationStarted, Conversation: &model.Conversation{ID: convID, Messages: []*model.Message{user, reply}}},
+			want:  []webhook.EventType{webhook.ConversationCreated, webhook.ReplyCreated},
+		},
+		"user message appended": {
+			event: &model.Event{Type: model.EventMessageAppended, Message: user},
+			want:  nil,
+		},
+		"pending reply appended": {
+			event: &model.Event{Type: model.EventMessageAppended, Message: pending},
+			want:  nil,
+		},
+		"reply failed": {
+			event: &model.Event{Type: model.EventMessageUpdated, Message: failed},
+			want:  []webhook.EventType{webhook.ReplyFailed},
+		},
+		"title changed": {
+			event: &model.Event{Type: model.EventTitleChanged, Title: "Greetings"},
+			want:  []webhook.EventType{webhook.TitleChanged},
+		},
+	} {
+		tc.event.ID = primitive.NewObjectID()
+		tc.event.ConversationID = convID
+
+		events, err := WebhookEvents(FromModel(tc.event))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+
+		var got []webhook.EventType
+		for _, e := range events {
+			got = append(got, e.Type)
+			if e.ConversationID != convID.Hex() {
+				t.Fatalf("%s: conversation ID: got %q, want %q", name, e.ConversationID, convID.Hex())
+			}
+		}
+		if !cmp.Equal(got, tc.want) {
+			t.Fatalf("%s: webhook events mismatch (-got +want):\n%s", name, cmp.Diff(got, tc.want))
+		}
+	}
+}
+
+type memoryOutbox struct {
+	events     []*model.Event
+	dispatched []primitive.ObjectID
+	extended   int
+	extendErr  error
+}
+
+func (m *memoryOutbox) ClaimEvents(_ context.Context, limit int, _ time.Duration) ([]*model.Event, error) {
+	return m.events[:min(limit, len(m.events))], nil
+}
+
+func (m *memoryOutbox) ExtendLease(context.Context, string, time.Duration) error {
+	if m.extendErr != nil {
+		return m.extendErr
+	}
+	m.extended++
+	return nil
+}
+
+func (m *memoryOutbox) MarkDispatched(_ context.Context, id primitive.ObjectID) error {
+	m.dispatched = append(m.dispatched, id)
+	return nil
+}
+
+type failingSink struct {
+	fail model.EventType
+	seen []model.EventType
+}
+
+func (*failingSink) Name() string { return "failing" }
+
+func (s *failingSink) Handle(_ context.Context, e Event) error {
+	if e.Type == s.fail {
+		return errors.New("unavailable")
+	}
+	s.seen = append(s.seen, e.Type)
+	return nil
+}
+
+type slowSink struct {
+	delay time.Duration
+}
+
+func (slowSink) Name() string { return "slow" }
+
+func (s slowSink) Handle(context.Context, Event) error {
+	time.Sleep(s.delay)
+	return nil
+}
+
+func TestRelay_Dispatch(t *testing.T) {
+	outbox := &memoryOutbox{}
+	for _, typ := range []model.EventType{model.EventConversationStarted, model.EventTitleChanged, model.EventConversationDeleted} {
+		outbox.events = append(outbox.events, &model.Event{ID: primitive.NewObjectID(), Type: typ, Conversation: &model.Conversation{}})
+	}
+
+	sink := &failingSink{fail: model.EventTitleChanged}
+	n, err := NewRelay(outbox, sink).Dispatch(context.Background())
+	if err == nil {
+		t.Fatal("expected error from the failing sink")
+	}
+
+	// Dispatching stops at the failed event, to keep events in order.
+	if n != 1 || len(outbox.dispatched) != 1 || outbox.dispatched[0] != outbox.events[0].ID {
+		t.Fatalf("dispatched: got %d %v, want only the first event", n, outbox.dispatched)
+	}
+	if got, want := sink.seen, []model.EventType{model.EventConversationStarted}; !cmp.Equal(got, want) {
+		t.Fatalf("sink events mismatch (-got +want):\n%s", cmp.Diff(got, want))
+	}
+}
+
+func TestRelay_Lease(t *testing.T) {
+	newOutbox := func() *memoryOutbox {
+		outbox := &memoryOutbox{}
+		for range 3 {
+			outbox.events = append(outbox.events, &model.Event{ID: primitive.NewObjectID(), Type: model.EventTitleChanged, Conversation: &model.Conversation{}})
+		}
+		return outbox
+	}
+
+	t.Run("the lease is extended while sinks are slow", func(t *testing.T) {
+		outbox := newOutbox()
+		relay := NewRelay(outbox, slowSink{delay: 60 * time.Millisecond})
+		relay.Lease = 90 * time.Millisecond
+
+		n, err := relay.Dispatch(context.Background())
+		if err != nil {
+			t.Fatalf("dispatch: %v", err)
+		}
+		if n != 3 || outbox.extended == 0 {
+			t.Fatalf("dispatched %d events with %d extensions, want 3 with some", n, outbox.extended)
+		}
+	})
+
+	t.Run("dispatching stops once the lease expired", func(t *testing.T) {
+		outbox := newOutbox()
+		outbox.extendErr = errors.New("unavailable")
+		relay := NewRelay(outbox, slowSink{delay: 60 * time.Millisecond})
+		relay.Lease = 90 * time.Millisecond
+
+		n, err := relay.Dispatch(context.Background())
+		if err == nil {
+			t.Fatal("expected error once the lease expired")
+		}
+		if n != 2 || len(outbox.dispatched) != 2 {
+			t.Fatalf("dispatched: got %d %v, want the events handled within the lease", n, outbox.dispatched)
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/logx"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Store is the outbox the relay reads events from.
+type Store interface {
+	ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]*model.Event, error)
+	ExtendLease(ctx context.Context, claim string, lease time.Duration) error
+	MarkDispatched(ctx context.Context, id primitive.ObjectID) error
+}
+
+// Relay hands the events of the outbox to the sinks, in order, at least once.
+// Several relays, e.g. one per server, may share an outbox.
+type Relay struct {
+	// Interval is how often the outbox is polled once empty.
+	Interval time.Duration
+	// BatchSize is the number of events claimed at once.
+	BatchSize int
+	// Lease is how long claimed events are reserved. It is extended while the
+	// batch is being dispatched, and events that a sink failed are handed to the
+	// sinks again once it expires.
+	Lease time.Duration
+
+	store Store
+	sinks []Sink
+	done  chan struct{}
+}
+
+func NewRelay(store Store, sinks ...Sink) *Relay {
+	return &Relay{
+		Interval:  500 * time.Millisecond,
+		BatchSize: 100,
+		Lease:     30 * time.Second,
+		store:     store,
+		sinks:     sinks,
+		done:      make(chan struct{}),
+	}
+}
+
+// Start relays events in the background until ctx is done. Events left in the
+// outbox are relayed on the next start.
+func (r *Relay) Start(ctx context.Context) {
+	go func() {
+		defer close(r.done)
+
+		t := time.NewTicker(r.Interval)
+		defer t.Stop()
+
+		for {
+			n, err := r.Dispatch(ctx)
+			if err != nil && ctx.Err() == nil {
+				slog.ErrorContext(ctx, "Failed to relay events", "error", err)
+			}
+			if n == r.BatchSize && err == nil {
+				continue // more are likely waiting
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+		}
+	}()
+}
+
+// Done is closed once the relay started by Start stopped.
+func (r *Relay) Done() <-chan struct{} {
+	return r.done
+}
+
+// Dispatch hands a batch of events to the sinks and returns how many were
+// dispatched. It stops at the first event a sink fails, to keep them in order,
+// and when the lease could not be extended, as another relay may then claim the
+// rest of the batch.
+func (r *Relay) Dispatch(ctx context.Context) (int, error) {
+	claimed, err := r.store.ClaimEvents(ctx, r.BatchSize, r.Lease)
+	if err != nil {
+		return 0, err
+	}
+	if len(claimed) == 0 {
+		return 0, nil
+	}
+
+	lease := r.keepLease(ctx, claimed[0].LeaseClaim)
+	defer lease.stop()
+
+	for i, me := range claimed {
+		if !lease.held() {
+			return i, fmt.Errorf("lease of event %s expired", me.ID.Hex())
+		}
+
+		e := FromModel(me)
+		ectx := logx.WithConversationID(ctx, e.ConversationID)
+
+		for _, sink := range r.sinks {
+			if err := sink.Handle(ectx, e); err != nil {
+				return i, fmt.Errorf("sink %s failed event %s: %w", sink.Name(), e.ID, err)
+			}
+		}
+		if err := r.store.MarkDispatched(ectx, me.ID); err != nil {
+			return i, err
+		}
+	}
+
+	return len(claimed), nil
+}
+
+// lease is the lease on a claimed batch, extended in the background.
+type lease struct {
+	until  atomic.Int64 // unix nanoseconds
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// keepLease extends the lease of claim every third of Lease until stopped.
+func (r *Relay) keepLease(ctx context.Context, claim string) *lease {
+	ctx, cancel := context.WithCancel(ctx)
+	l := &lease{cancel: cancel, done: make(chan struct{})}
+	l.until.Store(time.Now().Add(r.Lease).UnixNano())
+
+	go func() {
+		defer close(l.done)
+
+		t := time.NewTicker(r.Lease / 3)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+
+			until := time.Now().Add(r.Lease)
+			if err := r.store.ExtendLease(ctx, claim, r.Lease); err != nil {
+				if ctx.Err() == nil {
+					slog.ErrorContext(ctx, "Failed to extend outbox lease", "error", err)
+				}
+				continue
+			}
+			l.until.Store(until.UnixNano())
+		}
+	}()
+	return l
+}
+
+// held reports whether the lease has not expired yet.
+func (l *lease) held() bool {
+	return time.Now().UnixNano() < l.until.Load()
+}
+
+func (l *lease) stop() {
+	l.cancel()
+	<-l.done
+}
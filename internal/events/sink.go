@@ -0,0 +1,121 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/acai-travel/tech-challenge/internal/webhook"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Sink receives every event. An event is handed to the sinks again when one of
+// them fails, so sinks must tolerate duplicates, e.g. using the event ID.
+type Sink interface {
+	Name() string
+	Handle(ctx context.Context, e Event) error
+}
+
+// LogSink logs events.
+type LogSink struct{}
+
+func (LogSink) Name() string {
+	return "log"
+}
+
+func (LogSink) Handle(ctx context.Context, e Event) error {
+	slog.InfoContext(ctx, "Conversation event", "event_id", e.ID, "event_type", e.Type, "conversation_id", e.ConversationID)
+	return nil
+}
+
+// BusSink publishes events as JSON on their subject, see Event.Subject.
+type BusSink struct {
+	Publisher Publisher
+}
+
+func (BusSink) Name() string {
+	return "bus"
+}
+
+func (s BusSink) Handle(ctx context.Context, e Event) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return s.Publisher.Publish(e.Subject(), b)
+}
+
+// WebhookSink delivers events to webhook subscriptions, see WebhookEvents.
+type WebhookSink struct {
+	Webhooks *webhook.Dispatcher
+}
+
+func (WebhookSink) Name() string {
+	return "webhook"
+}
+
+func (s WebhookSink) Handle(ctx context.Context, e Event) error {
+	events, err := WebhookEvents(e)
+	if err != nil {
+		return err
+	}
+	for _, we := range events {
+		if err := s.Webhooks.Deliver(ctx, we); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WebhookEvents returns the webhook events notifying of e. Replies are notified
+// once complete or failed, rather than when they are appended pending. Their
+// IDs are derived from e's, so that receivers can spot duplicates.
+func WebhookEvents(e Event) ([]webhook.Event, error) {
+	var out []webhook.Event
+	add := func(id string, typ webhook.EventType, data proto.Message) error {
+		b, err := protojson.Marshal(data)
+		if err != nil {
+			return err
+		}
+		out = append(out, webhook.Event{ID: id, Type: typ, Time: e.Time, ConversationID: e.ConversationID, Data: b})
+		return nil
+	}
+
+	var err error
+	switch e.Type {
+	case model.EventConversationStarted:
+		err = add(e.ID, webhook.ConversationCreated, e.Conversation)
+		for _, m := range e.Conversation.GetMessages() {
+			if typ, ok := replyEvent(m); ok && err == nil {
+				err = add(e.ID+"-"+m.GetId(), typ, m)
+			}
+		}
+	case model.EventMessageAppended, model.EventMessageUpdated:
+		if typ, ok := replyEvent(e.Message); ok {
+			err = add(e.ID, typ, e.Message)
+		}
+	case model.EventTitleChanged:
+		err = add(e.ID, webhook.TitleChanged, e.Conversation)
+	case model.EventConversationDeleted:
+		err = add(e.ID, webhook.ConversationDeleted, &pb.Conversation{Id: e.ConversationID})
+	}
+
+	return out, err
+}
+
+func replyEvent(m *pb.Conversation_Message) (webhook.EventType, bool) {
+	if m.GetRole() != pb.Conversation_ASSISTANT {
+		return "", false
+	}
+	switch m.GetStatus() {
+	case pb.Conversation_COMPLETE:
+		return webhook.ReplyCreated, true
+	case pb.Conversation_FAILED:
+		return webhook.ReplyFailed, true
+	default:
+		return "", false
+	}
+}
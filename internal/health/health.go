@@ -0,0 +1,225 @@
+// Package health serves the liveness, readiness and status endpoints. Checks
+// run periodically in the background so that probes are cheap and do not hammer
+// the dependencies; endpoints report the latest results.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// historySize is how many past results are kept per check for /status.
+const historySize = 10
+
+// Check probes a dependency. Only required checks affect readiness, the others
+// are reported in /status.
+type Check struct {
+	Name     string
+	Required bool
+	Run      func(ctx context.Context) error
+}
+
+// Result is the outcome of one run of a check.
+type Result struct {
+	Time    time.Time     `json:"time"`
+	OK      bool          `json:"ok"`
+	Latency time.Duration `json:"latency_ns"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// Info describes the running service in /status.
+type Info struct {
+	Version string
+	Tools   []string
+}
+
+// Checker runs checks and serves their results.
+type Checker struct {
+	// Interval between two rounds of checks, and Timeout of each check.
+	Interval time.Duration
+	Timeout  time.Duration
+
+	info    Info
+	checks  []Check
+	started time.Time
+
+	mu       sync.RWMutex
+	history  map[string][]Result
+	draining bool
+}
+
+// NewChecker creates a checker. When info.Version is empty, the version is
+// taken from the build information.
+func NewChecker(info Info, checks ...Check) *Checker {
+	if info.Version == "" {
+		info.Version = buildVersion()
+	}
+	return &Checker{
+		Interval: 15 * time.Second,
+		Timeout:  3 * time.Second,
+		info:     info,
+		checks:   checks,
+		started:  time.Now(),
+		history:  make(map[string][]Result, len(checks)),
+	}
+}
+
+// Start runs the checks right away, then every Interval until ctx is done.
+func (c *Checker) Start(ctx context.Context) {
+	c.RunChecks(ctx)
+
+	go func() {
+		ticker := time.NewTicker(c.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.RunChecks(ctx)
+			}
+		}
+	}()
+}
+
+// Drain reports the service as not ready from now on, so that the orchestrator
+// stops routing traffic to it before it shuts down.
+func (c *Checker) Drain() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.draining = true
+}
+
+// RunChecks runs all checks concurrently and records their results.
+func (c *Checker) RunChecks(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			cctx, cancel := context.WithTimeout(ctx, c.Timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := check.Run(cctx)
+			res := Result{Time: start, OK: err == nil, Latency: time.Since(start)}
+			if err != nil {
+				res.Error = err.Error()
+			}
+
+			c.mu.Lock()
+			h := append(c.history[check.Name], res)
+			if len(h) > historySize {
+				h = h[len(h)-historySize:]
+			}
+			c.history[check.Name] = h
+			c.mu.Unlock()
+		}()
+	}
+	wg.Wait()
+}
+
+// Ready reports whether every required check passed on its latest run.
+func (c *Checker) Ready() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.draining {
+		return false
+	}
+	for _, check := range c.checks {
+		if !check.Required {
+			continue
+		}
+		h := c.history[check.Name]
+		if len(h) == 0 || !h[len(h)-1].OK {
+			return false
+		}
+	}
+	return true
+}
+
+// Healthz reports that the process is alive and serving.
+func (c *Checker) Healthz(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Readyz reports whether the service can take traffic.
+func (c *Checker) Readyz(w http.ResponseWriter, _ *http.Request) {
+	if !c.Ready() {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "unavailable"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+type dependencyStatus struct {
+	Required bool     `json:"required"`
+	OK       bool     `json:"ok"`
+	History  []Result `json:"history"`
+}
+
+type status struct {
+	Ready        bool                        `json:"ready"`
+	Version      string                      `json:"version"`
+	StartedAt    time.Time                   `json:"started_at"`
+	Uptime       string                      `json:"uptime"`
+	Tools        []string                    `json:"tools"`
+	Dependencies map[string]dependencyStatus `json:"dependencies"`
+}
+
+// Status serves a detailed report of the service and its dependencies.
+func (c *Checker) Status(w http.ResponseWriter, _ *http.Request) {
+	s := status{
+		Ready:        c.Ready(),
+		Version:      c.info.Version,
+		StartedAt:    c.started,
+		Uptime:       time.Since(c.started).Round(time.Second).String(),
+		Tools:        c.info.Tools,
+		Dependencies: make(map[string]dependencyStatus, len(c.checks)),
+	}
+
+	c.mu.RLock()
+	for _, check := range c.checks {
+		h := append([]Result(nil), c.history[check.Name]...)
+		s.Dependencies[check.Name] = dependencyStatus{
+			Required: check.Required,
+			OK:       len(h) > 0 && h[len(h)-1].OK,
+			History:  h,
+		}
+	}
+	c.mu.RUnlock()
+
+	writeJSON(w, http.StatusOK, s)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// buildVersion returns the module version, or the VCS revision for builds from
+// a checkout.
+func buildVersion() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	if v := bi.Main.Version; v != "" && v != "(devel)" {
+		return v
+	}
+	for _, s := range bi.Settings {
+		if s.Key == "vcs.revision" {
+			return s.Value
+		}
+	}
+	return "devel"
+}
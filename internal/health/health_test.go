@@ -0,0 +1,61 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestChecker(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dbErr := errors.New("connection refused")
+	var db error
+	c := NewChecker(Info{Version: "test", Tools: []string{"get_weather"}},
+		Check{Name: "mongodb", Required: true, Run: func(context.Context) error { return db }},
+		Check{Name: "openai", Run: func(context.Context) error { return errors.New("unauthorized") }},
+	)
+	c.Start(ctx)
+
+	get := func(h http.HandlerFunc) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		return rec
+	}
+
+	if got := get(c.Readyz).Code; got != http.StatusOK {
+		t.Fatalf("readyz with optional check failing: got %d, want %d", got, http.StatusOK)
+	}
+
+	db = dbErr
+	c.RunChecks(ctx)
+	if got := get(c.Readyz).Code; got != http.StatusServiceUnavailable {
+		t.Fatalf("readyz with required check failing: got %d, want %d", got, http.StatusServiceUnavailable)
+	}
+	if got := get(c.Healthz).Code; got != http.StatusOK {
+		t.Fatalf("healthz: got %d, want %d", got, http.StatusOK)
+	}
+
+	var s status
+	if err := json.Unmarshal(get(c.Status).Body.Bytes(), &s); err != nil {
+		t.Fatalf("status is not JSON: %v", err)
+	}
+	mongo := s.Dependencies["mongodb"]
+	if s.Ready || s.Version != "test" || len(mongo.History) != 2 || mongo.OK {
+		t.Fatalf("unexpected status: %+v", s)
+	}
+	if got, want := mongo.History[1].Error, dbErr.Error(); got != want {
+		t.Fatalf("last mongodb error: got %q, want %q", got, want)
+	}
+
+	db = nil
+	c.RunChecks(ctx)
+	c.Drain()
+	if got := get(c.Readyz).Code; got != http.StatusServiceUnavailable {
+		t.Fatalf("readyz while draining: got %d, want %d", got, http.StatusServiceUnavailable)
+	}
+}
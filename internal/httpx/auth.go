@@ -0,0 +1,29 @@
+package httpx
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/twitchtv/twirp"
+)
+
+// BearerToken rejects requests not carrying the given token in their
+// Authorization header, with a Twirp Unauthenticated error.
+func BearerToken(token string) func(handler http.Handler) http.Handler {
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				err := twirp.NewError(twirp.Unauthenticated, "invalid or missing bearer token")
+				if rpc, ok := r.Context().Value(logKey{}).(*rpcLog); ok {
+					rpc.code, rpc.msg = err.Code(), err.Msg()
+				}
+				_ = twirp.WriteError(w, err)
+				return
+			}
+
+			handler.ServeHTTP(w, r)
+		})
+	}
+}
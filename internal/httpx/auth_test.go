@@ -0,0 +1,27 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBearerToken(t *testing.T) {
+	h := BearerToken("s3cret")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for header, want := range map[string]int{
+		"":              http.StatusUnauthorized,
+		"Bearer other":  http.StatusUnauthorized,
+		"s3cret":        http.StatusUnauthorized,
+		"Bearer s3cret": http.StatusOK,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/twirp/acai.admin.AdminService/ListFailedDeliveries", nil)
+		req.Header.Set("Authorization", header)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if got := rec.Code; got != want {
+			t.Fatalf("Authorization %q: got %d, want %d", header, got, want)
+		}
+	}
+}
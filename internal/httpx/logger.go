@@ -1,8 +1,11 @@
 package httpx
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
+
+	"github.com/twitchtv/twirp"
 )
 
 type statusAwareResponseWriter struct {
@@ -15,16 +18,44 @@ func (w *statusAwareResponseWriter) WriteHeader(status int) {
 	w.ResponseWriter.WriteHeader(status)
 }
 
+// Unwrap gives http.ResponseController access to the underlying writer, e.g. to
+// flush streamed responses.
+func (w *statusAwareResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+type logKey struct{}
+
+// rpcLog collects what the Twirp hooks learn about a request for Logger.
+type rpcLog struct {
+	method string
+	code   twirp.ErrorCode
+	msg    string
+}
+
 func Logger() func(handler http.Handler) http.Handler {
 	return func(handler http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			saw := &statusAwareResponseWriter{ResponseWriter: w}
+			rpc := &rpcLog{}
+			r = r.WithContext(context.WithValue(r.Context(), logKey{}, rpc))
 
 			defer func() {
-				if saw.status/100 == 5 {
-					slog.ErrorContext(r.Context(), "HTTP request failed", "http_method", r.Method, "http_path", r.URL.Path, "http_status", saw.status)
+				attrs := []any{"http_method", r.Method, "http_path", r.URL.Path, "http_status", saw.status}
+				if rpc.method != "" {
+					attrs = append(attrs, "twirp_method", rpc.method)
+				}
+
+				failed := saw.status/100 == 5
+				if rpc.code != twirp.NoError {
+					attrs = append(attrs, "twirp_code", rpc.code, "error", rpc.msg)
+					failed = twirp.ServerHTTPStatusFromErrorCode(rpc.code)/100 == 5
+				}
+
+				if failed {
+					slog.ErrorContext(r.Context(), "HTTP request failed", attrs...)
 				} else {
-					slog.InfoContext(r.Context(), "HTTP request complete", "http_method", r.Method, "http_path", r.URL.Path, "http_status", saw.status)
+					slog.InfoContext(r.Context(), "HTTP request complete", attrs...)
 				}
 			}()
 
@@ -32,3 +63,22 @@ func Logger() func(handler http.Handler) http.Handler {
 		})
 	}
 }
+
+// TwirpHooks reports the method and error code of Twirp requests to Logger, so
+// that failures are classified by their Twirp code rather than HTTP status.
+func TwirpHooks() *twirp.ServerHooks {
+	return &twirp.ServerHooks{
+		RequestRouted: func(ctx context.Context) (context.Context, error) {
+			if rpc, ok := ctx.Value(logKey{}).(*rpcLog); ok {
+				rpc.method, _ = twirp.MethodName(ctx)
+			}
+			return ctx, nil
+		},
+		Error: func(ctx context.Context, err twirp.Error) context.Context {
+			if rpc, ok := ctx.Value(logKey{}).(*rpcLog); ok {
+				rpc.code, rpc.msg = err.Code(), err.Msg()
+			}
+			return ctx
+		},
+	}
+}
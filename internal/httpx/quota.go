@@ -0,0 +1,72 @@
+package httpx
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/usage"
+)
+
+// QuotaStore keeps track of the tokens consumed by each client per day.
+type QuotaStore interface {
+	// ReserveTokens charges tokens to client unless that takes its usage of
+	// the day over limit, reporting whether it did. The check and the charge
+	// must be atomic.
+	ReserveTokens(ctx context.Context, client string, day time.Time, tokens, limit int64) (bool, error)
+	// AddTokenUsage charges tokens to client, or refunds them when negative.
+	AddTokenUsage(ctx context.Context, client string, day time.Time, tokens int64) error
+}
+
+// TokenQuota rejects matching requests from clients that consumed their daily
+// token allowance, with a Twirp ResourceExhausted error telling them to retry
+// after midnight UTC. Each request reserves tokens from the allowance up
+// front, so that concurrent requests cannot all pass the check: the allowance
+// is only overshot by what requests consume beyond their reservation. Tokens
+// reported with usage.AddTokens are charged, less the reservation, once the
+// request and the work it holds in the background with usage.Hold are done.
+// When the store is unavailable requests are let through.
+func TokenQuota(store QuotaStore, daily, reserve int64, match func(*http.Request) bool) func(handler http.Handler) http.Handler {
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !match(r) {
+				handler.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			client := ClientFromContext(ctx)
+			now := time.Now().UTC()
+			day := now.Truncate(24 * time.Hour)
+
+			reserved := reserve
+			ok, err := store.ReserveTokens(ctx, client, day, reserve, daily)
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to reserve tokens", "error", err)
+				reserved = 0
+			} else if !ok {
+				writeExhausted(w, r, "daily token quota exceeded", day.Add(24*time.Hour).Sub(now))
+				return
+			}
+
+			tctx, tokens := usage.WithTokens(ctx)
+			handler.ServeHTTP(w, r.WithContext(tctx))
+
+			// The reservation is kept until the tokens consumed are known.
+			var settled atomic.Bool
+			tokens.Close(func(n int64) {
+				if !settled.Swap(true) {
+					n -= reserved
+				}
+				if n == 0 {
+					return
+				}
+				if err := store.AddTokenUsage(context.WithoutCancel(ctx), client, day, n); err != nil {
+					slog.ErrorContext(ctx, "Failed to record token usage", "error", err, "tokens", n)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package httpx
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/twitchtv/twirp"
+)
+
+// bucket is a token bucket refilled continuously at rate tokens per second.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimit allows each client, as identified by Identity, rate requests per
+// second with bursts of up to burst requests. Requests over the limit are
+// rejected with a Twirp ResourceExhausted error telling when to retry.
+func RateLimit(rate float64, burst int) func(handler http.Handler) http.Handler {
+	var (
+		mu      sync.Mutex
+		buckets = make(map[string]*bucket)
+		swept   = time.Now()
+	)
+
+	// take consumes a token for client, or returns how long until one is available.
+	take := func(client string, now time.Time) time.Duration {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// Forget clients whose bucket has been full for a while.
+		if now.Sub(swept) > time.Minute {
+			for k, b := range buckets {
+				if b.tokens+now.Sub(b.last).Seconds()*rate >= float64(burst) {
+					delete(buckets, k)
+				}
+			}
+			swept = now
+		}
+
+		b, ok := buckets[client]
+		if !ok {
+			b = &bucket{tokens: float64(burst), last: now}
+			buckets[client] = b
+		}
+
+		b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+		b.last = now
+
+		if b.tokens < 1 {
+			return time.Duration((1 - b.tokens) / rate * float64(time.Second))
+		}
+		b.tokens--
+		return 0
+	}
+
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if wait := take(ClientFromContext(r.Context()), time.Now()); wait > 0 {
+				writeExhausted(w, r, "rate limit exceeded", wait)
+				return
+			}
+			handler.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ConcurrencyLimit caps the number of matching requests handled at once. Up to
+// queue more requests wait at most wait for a slot; beyond that, or once the wait
+// is over, requests are rejected with a Twirp ResourceExhausted error so that
+// clients back off instead of piling up.
+func ConcurrencyLimit(limit, queue int, wait time.Duration, match func(*http.Request) bool) func(handler http.Handler) http.Handler {
+	slots := make(chan struct{}, limit)
+	waiting := make(chan struct{}, queue)
+
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !match(r) {
+				handler.ServeHTTP(w, r)
+				return
+			}
+
+			select {
+			case slots <- struct{}{}:
+			default:
+				select {
+				case waiting <- struct{}{}:
+				default:
+					writeExhausted(w, r, "server is busy, too many requests in progress", wait)
+					return
+				}
+
+				timer := time.NewTimer(wait)
+				select {
+				case slots <- struct{}{}:
+					timer.Stop()
+					<-waiting
+				case <-timer.C:
+					<-waiting
+					writeExhausted(w, r, "server is busy, too many requests in progress", wait)
+					return
+				case <-r.Context().Done():
+					timer.Stop()
+					<-waiting
+					return
+				}
+			}
+			defer func() { <-slots }()
+
+			handler.ServeHTTP(w, r)
+		})
+	}
+}
+
+// MatchPathSuffix matches requests whose path ends with one of suffixes, e.g.
+// the names of Twirp methods.
+func MatchPathSuffix(suffixes ...string) func(*http.Request) bool {
+	return func(r *http.Request) bool {
+		for _, s := range suffixes {
+			if strings.HasSuffix(r.URL.Path, s) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// writeExhausted rejects a request with a Twirp ResourceExhausted error. The
+// retry hint is sent both as a Retry-After header and as retry_after_ms metadata.
+func writeExhausted(w http.ResponseWriter, r *http.Request, msg string, retryAfter time.Duration) {
+	err := twirp.NewError(twirp.ResourceExhausted, msg).
+		WithMeta("retry_after_ms", strconv.FormatInt(retryAfter.Milliseconds(), 10))
+
+	if rpc, ok := r.Context().Value(logKey{}).(*rpcLog); ok {
+		rpc.code, rpc.msg = err.Code(), err.Msg()
+	}
+
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	_ = twirp.WriteError(w, err)
+}
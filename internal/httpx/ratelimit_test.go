@@ -0,0 +1,224 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/usage"
+)
+
+func serve(h http.Handler, client string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/twirp/acai.chat.ChatService/StartConversation", nil)
+	req.Header.Set(ClientIDHeader, client)
+	rec := httptest.NewRecorder()
+	Identity(netip.MustParsePrefix("192.0.2.0/24"))(h).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdentity(t *testing.T) {
+	var client string
+	h := Identity(netip.MustParsePrefix("10.0.0.0/8"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client = ClientFromContext(r.Context())
+	}))
+
+	tests := []struct {
+		remote string
+		header string
+		want   string
+	}{
+		{remote: "10.1.2.3:4321", header: "alice", want: "alice"},
+		{remote: "203.0.113.7:4321", header: "alice", want: "203.0.113.7"},
+		{remote: "10.1.2.3:4321", want: "10.1.2.3"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = tt.remote
+		if tt.header != "" {
+			req.Header.Set(ClientIDHeader, tt.header)
+		}
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		if client != tt.want {
+			t.Fatalf("client from %s with %q: got %q, want %q", tt.remote, tt.header, client, tt.want)
+		}
+	}
+}
+
+var ok = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+func TestRateLimit(t *testing.T) {
+	h := RateLimit(1, 2)(ok)
+
+	for i := range 2 {
+		if got := serve(h, "alice").Code; got != http.StatusOK {
+			t.Fatalf("request %d: got %d, want %d", i, got, http.StatusOK)
+		}
+	}
+
+	rec := serve(h, "alice")
+	if got, want := rec.Code, http.StatusTooManyRequests; got != want {
+		t.Fatalf("over the limit: got %d, want %d", got, want)
+	}
+	if got, want := rec.Header().Get("Retry-After"), "1"; got != want {
+		t.Fatalf("Retry-After: got %q, want %q", got, want)
+	}
+
+	if got := serve(h, "bob").Code; got != http.StatusOK {
+		t.Fatalf("other client: got %d, want %d", got, http.StatusOK)
+	}
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	release := make(chan struct{})
+	entered := make(chan struct{}, 2)
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		<-release
+	})
+
+	h := ConcurrencyLimit(1, 1, time.Second, MatchPathSuffix("/StartConversation"))(slow)
+
+	var wg sync.WaitGroup
+	codes := make(chan int, 2)
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- serve(h, "alice").Code
+		}()
+	}
+
+	<-entered
+	// One request is in flight and one is queued: the next one is turned away.
+	time.Sleep(50 * time.Millisecond)
+	if got, want := serve(h, "alice").Code, http.StatusTooManyRequests; got != want {
+		t.Fatalf("over the queue: got %d, want %d", got, want)
+	}
+
+	close(release)
+	wg.Wait()
+	close(codes)
+	for code := range codes {
+		if code != http.StatusOK {
+			t.Fatalf("queued request: got %d, want %d", code, http.StatusOK)
+		}
+	}
+}
+
+type memoryQuota struct {
+	mu    sync.Mutex
+	usage map[string]int64
+}
+
+func (m *memoryQuota) ReserveTokens(_ context.Context, client string, _ time.Time, tokens, limit int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.usage[client]+tokens > limit {
+		return false, nil
+	}
+	m.usage[client] += tokens
+	return true, nil
+}
+
+func (m *memoryQuota) AddTokenUsage(_ context.Context, client string, _ time.Time, tokens int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.usage[client] += tokens
+	return nil
+}
+
+func TestTokenQuota(t *testing.T) {
+	store := &memoryQuota{usage: map[string]int64{}}
+	spend := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		usage.AddTokens(r.Context(), 60)
+	})
+
+	h := TokenQuota(store, 100, 1, MatchPathSuffix("/StartConversation"))(spend)
+
+	for i := range 2 {
+		if got := serve(h, "alice").Code; got != http.StatusOK {
+			t.Fatalf("request %d: got %d, want %d", i, got, http.StatusOK)
+		}
+	}
+	if got, want := store.usage["alice"], int64(120); got != want {
+		t.Fatalf("usage: got %d, want %d", got, want)
+	}
+
+	rec := serve(h, "alice")
+	if got, want := rec.Code, http.StatusTooManyRequests; got != want {
+		t.Fatalf("over quota: got %d, want %d", got, want)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Fatal("expected a Retry-After header")
+	}
+}
+
+func TestTokenQuota_Concurrent(t *testing.T) {
+	store := &memoryQuota{usage: map[string]int64{}}
+	release := make(chan struct{})
+	entered := make(chan struct{}, 2)
+	h := TokenQuota(store, 100, 40, MatchPathSuffix("/StartConversation"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		<-release
+		usage.AddTokens(r.Context(), 10)
+	}))
+
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			serve(h, "alice")
+		}()
+	}
+	<-entered
+	<-entered
+
+	// The requests in flight reserved 80 tokens, too many for another one.
+	if got, want := serve(h, "alice").Code, http.StatusTooManyRequests; got != want {
+		t.Fatalf("over the reservations: got %d, want %d", got, want)
+	}
+
+	close(release)
+	wg.Wait()
+	if got, want := store.usage["alice"], int64(20); got != want {
+		t.Fatalf("usage once settled: got %d, want %d", got, want)
+	}
+}
+
+func TestTokenQuota_Background(t *testing.T) {
+	store := &memoryQuota{usage: map[string]int64{}}
+	var background context.Context
+	var release func()
+	h := TokenQuota(store, 100, 60, MatchPathSuffix("/StartConversation"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		usage.AddTokens(r.Context(), 10)
+		background = context.WithoutCancel(r.Context())
+		release = usage.Hold(background)
+	}))
+
+	serve(h, "alice")
+
+	// The reservation is kept while the work left running by the request is.
+	if got, want := store.usage["alice"], int64(60); got != want {
+		t.Fatalf("usage while held: got %d, want %d", got, want)
+	}
+	if got, want := serve(h, "alice").Code, http.StatusTooManyRequests; got != want {
+		t.Fatalf("over the reservations: got %d, want %d", got, want)
+	}
+
+	usage.AddTokens(background, 25)
+	release()
+	if got, want := store.usage["alice"], int64(35); got != want {
+		t.Fatalf("usage once released: got %d, want %d", got, want)
+	}
+
+	// Tokens reported afterwards are charged as they are.
+	usage.AddTokens(background, 5)
+	if got, want := store.usage["alice"], int64(40); got != want {
+		t.Fatalf("usage after the release: got %d, want %d", got, want)
+	}
+}
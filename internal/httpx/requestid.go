@@ -0,0 +1,111 @@
+package httpx
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"net/netip"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// RequestIDHeader carries the request ID, in both the request and response.
+	RequestIDHeader = "X-Request-ID"
+	// ClientIDHeader identifies the caller on behalf of which a trusted proxy,
+	// e.g. an authenticating gateway, forwards the request.
+	ClientIDHeader = "X-Client-ID"
+)
+
+// maxRequestID bounds request IDs accepted from clients, as they end up in logs.
+const maxRequestID = 128
+
+type requestIDKey struct{}
+type clientKey struct{}
+
+// RequestID reuses the X-Request-ID sent by the client, or generates one, and
+// stores it in the request context. It is echoed back in the response.
+func RequestID() func(handler http.Handler) http.Handler {
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(RequestIDHeader)
+			if !validID(id) {
+				id = newRequestID()
+			}
+
+			w.Header().Set(RequestIDHeader, id)
+			trace.SpanFromContext(r.Context()).SetAttributes(attribute.String("http.request.id", id))
+
+			handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+		})
+	}
+}
+
+// RequestIDFromContext returns the ID of the request being handled, if any.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func validID(id string) bool {
+	if id == "" || len(id) > maxRequestID {
+		return false
+	}
+	for _, c := range id {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// Identity records who is calling in the request context: the remote IP
+// address, or the X-Client-ID header of requests from one of trustedProxies.
+// The header is ignored from anyone else, as clients could otherwise evade the
+// limits per client by sending a new identity with each request.
+func Identity(trustedProxies ...netip.Prefix) func(handler http.Handler) http.Handler {
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			client := r.RemoteAddr
+			if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+				client = host
+			}
+
+			if id := r.Header.Get(ClientIDHeader); validID(id) && trusted(client, trustedProxies) {
+				client = id
+			}
+
+			handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientKey{}, client)))
+		})
+	}
+}
+
+// trusted reports whether addr belongs to one of proxies.
+func trusted(addr string, proxies []netip.Prefix) bool {
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return false
+	}
+	ip = ip.Unmap()
+	for _, p := range proxies {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientFromContext returns the identity of the caller, if known.
+func ClientFromContext(ctx context.Context) string {
+	client, _ := ctx.Value(clientKey{}).(string)
+	return client
+}
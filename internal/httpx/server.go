@@ -0,0 +1,74 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// ServerConfig configures the HTTP server started by Serve.
+type ServerConfig struct {
+	Addr string
+
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	// WriteTimeout bounds the whole handling of a request, so it must leave
+	// room for a reply that takes several model and tool round-trips.
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+
+	// ShutdownTimeout is how long in-flight requests are given to complete once
+	// the server is asked to stop.
+	ShutdownTimeout time.Duration
+
+	// TLSCertFile and TLSKeyFile enable HTTPS when both are set.
+	TLSCertFile string
+	TLSKeyFile  string
+}
+
+// Serve runs an HTTP server until ctx is done, then stops accepting connections
+// and waits up to cfg.ShutdownTimeout for in-flight requests to complete. It
+// returns nil after a graceful shutdown.
+func Serve(ctx context.Context, cfg ServerConfig, handler http.Handler) error {
+	srv := &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		if cfg.TLSCertFile != "" && cfg.TLSKeyFile != "" {
+			errc <- srv.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+		} else {
+			errc <- srv.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down the server, draining in-flight requests...", "timeout", cfg.ShutdownTimeout)
+
+	sctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(sctx); err != nil {
+		// The deadline passed: abort the remaining requests.
+		_ = srv.Close()
+		return err
+	}
+
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
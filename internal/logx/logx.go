@@ -0,0 +1,79 @@
+// Package logx configures structured logging. Its handler decorates every log
+// record with the request-scoped attributes found in the context, so that log
+// lines can be correlated with a request, a trace and a conversation.
+package logx
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/acai-travel/tech-challenge/internal/httpx"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Output formats accepted by New.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// New returns a logger writing to w in the given format, text unless "json".
+func New(w io.Writer, format string, level slog.Level) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level}
+
+	var h slog.Handler
+	if format == FormatJSON {
+		h = slog.NewJSONHandler(w, opts)
+	} else {
+		h = slog.NewTextHandler(w, opts)
+	}
+
+	return slog.New(NewHandler(h))
+}
+
+type conversationKey struct{}
+
+// WithConversationID stores the conversation a request is about in ctx, so that
+// it is added to the log lines emitted while handling it.
+func WithConversationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, conversationKey{}, id)
+}
+
+// Handler adds the request ID, caller identity, trace and span IDs and
+// conversation ID from the context to every record.
+type Handler struct {
+	next slog.Handler
+}
+
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{next: next}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	if id := httpx.RequestIDFromContext(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if client := httpx.ClientFromContext(ctx); client != "" {
+		r.AddAttrs(slog.String("client_id", client))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	if id, ok := ctx.Value(conversationKey{}).(string); ok {
+		r.AddAttrs(slog.String("conversation_id", id))
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name)}
+}
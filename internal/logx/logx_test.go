@@ -0,0 +1,66 @@
+package logx
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/acai-travel/tech-challenge/internal/httpx"
+)
+
+func TestHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, FormatJSON, slog.LevelInfo)
+
+	handler := httpx.RequestID()(httpx.Identity(netip.MustParsePrefix("192.0.2.0/24"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.InfoContext(WithConversationID(r.Context(), "c1"), "hello")
+	})))
+
+	t.Run("request ID is propagated", func(t *testing.T) {
+		buf.Reset()
+		req := httptest.NewRequest(http.MethodPost, "/twirp/acai.chat.ChatService/ListConversations", nil)
+		req.Header.Set(httpx.RequestIDHeader, "req-123")
+		req.Header.Set(httpx.ClientIDHeader, "cli")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if got, want := rec.Header().Get(httpx.RequestIDHeader), "req-123"; got != want {
+			t.Fatalf("response header: got %q, want %q", got, want)
+		}
+
+		var line map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+			t.Fatalf("log line is not JSON: %v\n%s", err, buf.String())
+		}
+		for k, want := range map[string]string{"request_id": "req-123", "client_id": "cli", "conversation_id": "c1", "msg": "hello"} {
+			if got := line[k]; got != want {
+				t.Fatalf("%s: got %v, want %q", k, got, want)
+			}
+		}
+	})
+
+	t.Run("request ID is generated", func(t *testing.T) {
+		buf.Reset()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		id := rec.Header().Get(httpx.RequestIDHeader)
+		if len(id) != 32 {
+			t.Fatalf("generated request ID: got %q", id)
+		}
+
+		var line map[string]any
+		_ = json.Unmarshal(buf.Bytes(), &line)
+		if line["request_id"] != id {
+			t.Fatalf("request_id: got %v, want %q", line["request_id"], id)
+		}
+		if got, want := line["client_id"], "192.0.2.1"; got != want {
+			t.Fatalf("client_id: got %v, want %q", got, want)
+		}
+	})
+}
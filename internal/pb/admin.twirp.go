@@ -0,0 +1,3108 @@
+// Code generated by protoc-gen-twirp v8.1.3, DO NOT EDIT.
+// source: rpc/admin.proto
+
+package pb
+
+import context "context"
+import fmt "fmt"
+import http "net/http"
+import io "io"
+import json "encoding/json"
+import strconv "strconv"
+import strings "strings"
+
+import protojson "google.golang.org/protobuf/encoding/protojson"
+import proto "google.golang.org/protobuf/proto"
+import twirp "github.com/twitchtv/twirp"
+import ctxsetters "github.com/twitchtv/twirp/ctxsetters"
+
+import bytes "bytes"
+import errors "errors"
+import path "path"
+import url "net/url"
+
+// Version compatibility assertion.
+// If the constant is not defined in the package, that likely means
+// the package needs to be updated to work with this generated code.
+// See https://twitchtv.github.io/twirp/docs/version_matrix.html
+const _ = twirp.TwirpPackageMinVersion_8_1_0
+
+// ======================
+// AdminService Interface
+// ======================
+
+// Operations on the service itself, authenticated with the admin token.
+type AdminService interface {
+	// List the webhook subscriptions, without their secrets
+	ListWebhookSubscriptions(context.Context, *ListWebhookSubscriptionsRequest) (*ListWebhookSubscriptionsResponse, error)
+
+	// List the most recent webhook deliveries that failed every attempt
+	ListFailedDeliveries(context.Context, *ListFailedDeliveriesRequest) (*ListFailedDeliveriesResponse, error)
+
+	// Attempt a failed delivery again, it is removed from the failed ones on success
+	ReplayFailedDelivery(context.Context, *ReplayFailedDeliveryRequest) (*ReplayFailedDeliveryResponse, error)
+
+	// List the most recent feedback on replies, see also the JSONL export
+	ListFeedback(context.Context, *ListFeedbackRequest) (*ListFeedbackResponse, error)
+
+	// List the personas conversations can be started with
+	ListPersonas(context.Context, *ListPersonasRequest) (*ListPersonasResponse, error)
+
+	// Create a persona
+	CreatePersona(context.Context, *CreatePersonaRequest) (*CreatePersonaResponse, error)
+
+	// Replace a persona, changing the replies of the conversations started with it
+	UpdatePersona(context.Context, *UpdatePersonaRequest) (*UpdatePersonaResponse, error)
+
+	// Delete a persona, the conversations started with it carry on with the defaults
+	DeletePersona(context.Context, *DeletePersonaRequest) (*DeletePersonaResponse, error)
+}
+
+// ============================
+// AdminService Protobuf Client
+// ============================
+
+type adminServiceProtobufClient struct {
+	client      HTTPClient
+	urls        [8]string
+	interceptor twirp.Interceptor
+	opts        twirp.ClientOptions
+}
+
+// NewAdminServiceProtobufClient creates a Protobuf client that implements the AdminService interface.
+// It communicates using Protobuf and can be configured with a custom HTTPClient.
+func NewAdminServiceProtobufClient(baseURL string, client HTTPClient, opts ...twirp.ClientOption) AdminService {
+	if c, ok := client.(*http.Client); ok {
+		client = withoutRedirects(c)
+	}
+
+	clientOpts := twirp.ClientOptions{}
+	for _, o := range opts {
+		o(&clientOpts)
+	}
+
+	// Using ReadOpt allows backwards and forwards compatibility with new options in the future
+	literalURLs := false
+	_ = clientOpts.ReadOpt("literalURLs", &literalURLs)
+	var pathPrefix string
+	if ok := clientOpts.ReadOpt("pathPrefix", &pathPrefix); !ok {
+		pathPrefix = "/twirp" // default prefix
+	}
+
+	// Build method URLs: <baseURL>[<prefix>]/<package>.<Service>/<Method>
+	serviceURL := sanitizeBaseURL(baseURL)
+	serviceURL += baseServicePath(pathPrefix, "acai.admin", "AdminService")
+	urls := [8]string{
+		serviceURL + "ListWebhookSubscriptions",
+		serviceURL + "ListFailedDeliveries",
+		serviceURL + "ReplayFailedDelivery",
+		serviceURL + "ListFeedback",
+		serviceURL + "ListPersonas",
+		serviceURL + "CreatePersona",
+		serviceURL + "UpdatePersona",
+		serviceURL + "DeletePersona",
+	}
+
+	return &adminServiceProtobufClient{
+		client:      client,
+		urls:        urls,
+		interceptor: twirp.ChainInterceptors(clientOpts.Interceptors...),
+		opts:        clientOpts,
+	}
+}
+
+func (c *adminServiceProtobufClient) ListWebhookSubscriptions(ctx context.Context, in *ListWebhookSubscriptionsRequest) (*ListWebhookSubscriptionsResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.admin")
+	ctx = ctxsetters.WithServiceName(ctx, "AdminService")
+	ctx = ctxsetters.WithMethodName(ctx, "ListWebhookSubscriptions")
+	caller := c.callListWebhookSubscriptions
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ListWebhookSubscriptionsRequest) (*ListWebhookSubscriptionsResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListWebhookSubscriptionsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListWebhookSubscriptionsRequest) when calling interceptor")
+					}
+					return c.callListWebhookSubscriptions(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListWebhookSubscriptionsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListWebhookSubscriptionsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *adminServiceProtobufClient) callListWebhookSubscriptions(ctx context.Context, in *ListWebhookSubscriptionsRequest) (*ListWebhookSubscriptionsResponse, error) {
+	out := new(ListWebhookSubscriptionsResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[0], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *adminServiceProtobufClient) ListFailedDeliveries(ctx context.Context, in *ListFailedDeliveriesRequest) (*ListFailedDeliveriesResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.admin")
+	ctx = ctxsetters.WithServiceName(ctx, "AdminService")
+	ctx = ctxsetters.WithMethodName(ctx, "ListFailedDeliveries")
+	caller := c.callListFailedDeliveries
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ListFailedDeliveriesRequest) (*ListFailedDeliveriesResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListFailedDeliveriesRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListFailedDeliveriesRequest) when calling interceptor")
+					}
+					return c.callListFailedDeliveries(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListFailedDeliveriesResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListFailedDeliveriesResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *adminServiceProtobufClient) callListFailedDeliveries(ctx context.Context, in *ListFailedDeliveriesRequest) (*ListFailedDeliveriesResponse, error) {
+	out := new(ListFailedDeliveriesResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[1], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *adminServiceProtobufClient) ReplayFailedDelivery(ctx context.Context, in *ReplayFailedDeliveryRequest) (*ReplayFailedDeliveryResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.admin")
+	ctx = ctxsetters.WithServiceName(ctx, "AdminService")
+	ctx = ctxsetters.WithMethodName(ctx, "ReplayFailedDelivery")
+	caller := c.callReplayFailedDelivery
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ReplayFailedDeliveryRequest) (*ReplayFailedDeliveryResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ReplayFailedDeliveryRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ReplayFailedDeliveryRequest) when calling interceptor")
+					}
+					return c.callReplayFailedDelivery(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ReplayFailedDeliveryResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ReplayFailedDeliveryResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *adminServiceProtobufClient) callReplayFailedDelivery(ctx context.Context, in *ReplayFailedDeliveryRequest) (*ReplayFailedDeliveryResponse, error) {
+	out := new(ReplayFailedDeliveryResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[2], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *adminServiceProtobufClient) ListFeedback(ctx context.Context, in *ListFeedbackRequest) (*ListFeedbackResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.admin")
+	ctx = ctxsetters.WithServiceName(ctx, "AdminService")
+	ctx = ctxsetters.WithMethodName(ctx, "ListFeedback")
+	caller := c.callListFeedback
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ListFeedbackRequest) (*ListFeedbackResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListFeedbackRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListFeedbackRequest) when calling interceptor")
+					}
+					return c.callListFeedback(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListFeedbackResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListFeedbackResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *adminServiceProtobufClient) callListFeedback(ctx context.Context, in *ListFeedbackRequest) (*ListFeedbackResponse, error) {
+	out := new(ListFeedbackResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[3], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *adminServiceProtobufClient) ListPersonas(ctx context.Context, in *ListPersonasRequest) (*ListPersonasResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.admin")
+	ctx = ctxsetters.WithServiceName(ctx, "AdminService")
+	ctx = ctxsetters.WithMethodName(ctx, "ListPersonas")
+	caller := c.callListPersonas
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ListPersonasRequest) (*ListPersonasResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListPersonasRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListPersonasRequest) when calling interceptor")
+					}
+					return c.callListPersonas(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListPersonasResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListPersonasResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *adminServiceProtobufClient) callListPersonas(ctx context.Context, in *ListPersonasRequest) (*ListPersonasResponse, error) {
+	out := new(ListPersonasResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[4], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *adminServiceProtobufClient) CreatePersona(ctx context.Context, in *CreatePersonaRequest) (*CreatePersonaResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.admin")
+	ctx = ctxsetters.WithServiceName(ctx, "AdminService")
+	ctx = ctxsetters.WithMethodName(ctx, "CreatePersona")
+	caller := c.callCreatePersona
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *CreatePersonaRequest) (*CreatePersonaResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*CreatePersonaRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*CreatePersonaRequest) when calling interceptor")
+					}
+					return c.callCreatePersona(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*CreatePersonaResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*CreatePersonaResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *adminServiceProtobufClient) callCreatePersona(ctx context.Context, in *CreatePersonaRequest) (*CreatePersonaResponse, error) {
+	out := new(CreatePersonaResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[5], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *adminServiceProtobufClient) UpdatePersona(ctx context.Context, in *UpdatePersonaRequest) (*UpdatePersonaResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.admin")
+	ctx = ctxsetters.WithServiceName(ctx, "AdminService")
+	ctx = ctxsetters.WithMethodName(ctx, "UpdatePersona")
+	caller := c.callUpdatePersona
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *UpdatePersonaRequest) (*UpdatePersonaResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*UpdatePersonaRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*UpdatePersonaRequest) when calling interceptor")
+					}
+					return c.callUpdatePersona(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*UpdatePersonaResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*UpdatePersonaResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *adminServiceProtobufClient) callUpdatePersona(ctx context.Context, in *UpdatePersonaRequest) (*UpdatePersonaResponse, error) {
+	out := new(UpdatePersonaResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[6], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *adminServiceProtobufClient) DeletePersona(ctx context.Context, in *DeletePersonaRequest) (*DeletePersonaResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.admin")
+	ctx = ctxsetters.WithServiceName(ctx, "AdminService")
+	ctx = ctxsetters.WithMethodName(ctx, "DeletePersona")
+	caller := c.callDeletePersona
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *DeletePersonaRequest) (*DeletePersonaResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DeletePersonaRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DeletePersonaRequest) when calling interceptor")
+					}
+					return c.callDeletePersona(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DeletePersonaResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DeletePersonaResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *adminServiceProtobufClient) callDeletePersona(ctx context.Context, in *DeletePersonaRequest) (*DeletePersonaResponse, error) {
+	out := new(DeletePersonaResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[7], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+// ========================
+// AdminService JSON Client
+// ========================
+
+type adminServiceJSONClient struct {
+	client      HTTPClient
+	urls        [8]string
+	interceptor twirp.Interceptor
+	opts        twirp.ClientOptions
+}
+
+// NewAdminServiceJSONClient creates a JSON client that implements the AdminService interface.
+// It communicates using JSON and can be configured with a custom HTTPClient.
+func NewAdminServiceJSONClient(baseURL string, client HTTPClient, opts ...twirp.ClientOption) AdminService {
+	if c, ok := client.(*http.Client); ok {
+		client = withoutRedirects(c)
+	}
+
+	clientOpts := twirp.ClientOptions{}
+	for _, o := range opts {
+		o(&clientOpts)
+	}
+
+	// Using ReadOpt allows backwards and forwards compatibility with new options in the future
+	literalURLs := false
+	_ = clientOpts.ReadOpt("literalURLs", &literalURLs)
+	var pathPrefix string
+	if ok := clientOpts.ReadOpt("pathPrefix", &pathPrefix); !ok {
+		pathPrefix = "/twirp" // default prefix
+	}
+
+	// Build method URLs: <baseURL>[<prefix>]/<package>.<Service>/<Method>
+	serviceURL := sanitizeBaseURL(baseURL)
+	serviceURL += baseServicePath(pathPrefix, "acai.admin", "AdminService")
+	urls := [8]string{
+		serviceURL + "ListWebhookSubscriptions",
+		serviceURL + "ListFailedDeliveries",
+		serviceURL + "ReplayFailedDelivery",
+		serviceURL + "ListFeedback",
+		serviceURL + "ListPersonas",
+		serviceURL + "CreatePersona",
+		serviceURL + "UpdatePersona",
+		serviceURL + "DeletePersona",
+	}
+
+	return &adminServiceJSONClient{
+		client:      client,
+		urls:        urls,
+		interceptor: twirp.ChainInterceptors(clientOpts.Interceptors...),
+		opts:        clientOpts,
+	}
+}
+
+func (c *adminServiceJSONClient) ListWebhookSubscriptions(ctx context.Context, in *ListWebhookSubscriptionsRequest) (*ListWebhookSubscriptionsResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.admin")
+	ctx = ctxsetters.WithServiceName(ctx, "AdminService")
+	ctx = ctxsetters.WithMethodName(ctx, "ListWebhookSubscriptions")
+	caller := c.callListWebhookSubscriptions
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ListWebhookSubscriptionsRequest) (*ListWebhookSubscriptionsResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListWebhookSubscriptionsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListWebhookSubscriptionsRequest) when calling interceptor")
+					}
+					return c.callListWebhookSubscriptions(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListWebhookSubscriptionsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListWebhookSubscriptionsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *adminServiceJSONClient) callListWebhookSubscriptions(ctx context.Context, in *ListWebhookSubscriptionsRequest) (*ListWebhookSubscriptionsResponse, error) {
+	out := new(ListWebhookSubscriptionsResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[0], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *adminServiceJSONClient) ListFailedDeliveries(ctx context.Context, in *ListFailedDeliveriesRequest) (*ListFailedDeliveriesResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.admin")
+	ctx = ctxsetters.WithServiceName(ctx, "AdminService")
+	ctx = ctxsetters.WithMethodName(ctx, "ListFailedDeliveries")
+	caller := c.callListFailedDeliveries
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ListFailedDeliveriesRequest) (*ListFailedDeliveriesResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListFailedDeliveriesRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListFailedDeliveriesRequest) when calling interceptor")
+					}
+					return c.callListFailedDeliveries(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListFailedDeliveriesResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListFailedDeliveriesResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *adminServiceJSONClient) callListFailedDeliveries(ctx context.Context, in *ListFailedDeliveriesRequest) (*ListFailedDeliveriesResponse, error) {
+	out := new(ListFailedDeliveriesResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[1], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *adminServiceJSONClient) ReplayFailedDelivery(ctx context.Context, in *ReplayFailedDeliveryRequest) (*ReplayFailedDeliveryResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.admin")
+	ctx = ctxsetters.WithServiceName(ctx, "AdminService")
+	ctx = ctxsetters.WithMethodName(ctx, "ReplayFailedDelivery")
+	caller := c.callReplayFailedDelivery
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ReplayFailedDeliveryRequest) (*ReplayFailedDeliveryResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ReplayFailedDeliveryRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ReplayFailedDeliveryRequest) when calling interceptor")
+					}
+					return c.callReplayFailedDelivery(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ReplayFailedDeliveryResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ReplayFailedDeliveryResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *adminServiceJSONClient) callReplayFailedDelivery(ctx context.Context, in *ReplayFailedDeliveryRequest) (*ReplayFailedDeliveryResponse, error) {
+	out := new(ReplayFailedDeliveryResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[2], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *adminServiceJSONClient) ListFeedback(ctx context.Context, in *ListFeedbackRequest) (*ListFeedbackResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.admin")
+	ctx = ctxsetters.WithServiceName(ctx, "AdminService")
+	ctx = ctxsetters.WithMethodName(ctx, "ListFeedback")
+	caller := c.callListFeedback
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ListFeedbackRequest) (*ListFeedbackResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListFeedbackRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListFeedbackRequest) when calling interceptor")
+					}
+					return c.callListFeedback(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListFeedbackResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListFeedbackResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *adminServiceJSONClient) callListFeedback(ctx context.Context, in *ListFeedbackRequest) (*ListFeedbackResponse, error) {
+	out := new(ListFeedbackResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[3], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *adminServiceJSONClient) ListPersonas(ctx context.Context, in *ListPersonasRequest) (*ListPersonasResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.admin")
+	ctx = ctxsetters.WithServiceName(ctx, "AdminService")
+	ctx = ctxsetters.WithMethodName(ctx, "ListPersonas")
+	caller := c.callListPersonas
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ListPersonasRequest) (*ListPersonasResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListPersonasRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListPersonasRequest) when calling interceptor")
+					}
+					return c.callListPersonas(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListPersonasResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListPersonasResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *adminServiceJSONClient) callListPersonas(ctx context.Context, in *ListPersonasRequest) (*ListPersonasResponse, error) {
+	out := new(ListPersonasResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[4], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *adminServiceJSONClient) CreatePersona(ctx context.Context, in *CreatePersonaRequest) (*CreatePersonaResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.admin")
+	ctx = ctxsetters.WithServiceName(ctx, "AdminService")
+	ctx = ctxsetters.WithMethodName(ctx, "CreatePersona")
+	caller := c.callCreatePersona
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *CreatePersonaRequest) (*CreatePersonaResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*CreatePersonaRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*CreatePersonaRequest) when calling interceptor")
+					}
+					return c.callCreatePersona(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*CreatePersonaResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*CreatePersonaResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *adminServiceJSONClient) callCreatePersona(ctx context.Context, in *CreatePersonaRequest) (*CreatePersonaResponse, error) {
+	out := new(CreatePersonaResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[5], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *adminServiceJSONClient) UpdatePersona(ctx context.Context, in *UpdatePersonaRequest) (*UpdatePersonaResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.admin")
+	ctx = ctxsetters.WithServiceName(ctx, "AdminService")
+	ctx = ctxsetters.WithMethodName(ctx, "UpdatePersona")
+	caller := c.callUpdatePersona
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *UpdatePersonaRequest) (*UpdatePersonaResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*UpdatePersonaRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*UpdatePersonaRequest) when calling interceptor")
+					}
+					return c.callUpdatePersona(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*UpdatePersonaResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*UpdatePersonaResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *adminServiceJSONClient) callUpdatePersona(ctx context.Context, in *UpdatePersonaRequest) (*UpdatePersonaResponse, error) {
+	out := new(UpdatePersonaResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[6], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *adminServiceJSONClient) DeletePersona(ctx context.Context, in *DeletePersonaRequest) (*DeletePersonaResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.admin")
+	ctx = ctxsetters.WithServiceName(ctx, "AdminService")
+	ctx = ctxsetters.WithMethodName(ctx, "DeletePersona")
+	caller := c.callDeletePersona
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *DeletePersonaRequest) (*DeletePersonaResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DeletePersonaRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DeletePersonaRequest) when calling interceptor")
+					}
+					return c.callDeletePersona(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DeletePersonaResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DeletePersonaResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *adminServiceJSONClient) callDeletePersona(ctx context.Context, in *DeletePersonaRequest) (*DeletePersonaResponse, error) {
+	out := new(DeletePersonaResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[7], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+// ===========================
+// AdminService Server Handler
+// ===========================
+
+type adminServiceServer struct {
+	AdminService
+	interceptor      twirp.Interceptor
+	hooks            *twirp.ServerHooks
+	pathPrefix       string // prefix for routing
+	jsonSkipDefaults bool   // do not include unpopulated fields (default values) in the response
+	jsonCamelCase    bool   // JSON fields are serialized as lowerCamelCase rather than keeping the original proto names
+}
+
+// NewAdminServiceServer builds a TwirpServer that can be used as an http.Handler to handle
+// HTTP requests that are routed to the right method in the provided svc implementation.
+// The opts are twirp.ServerOption modifiers, for example twirp.WithServerHooks(hooks).
+func NewAdminServiceServer(svc AdminService, opts ...interface{}) TwirpServer {
+	serverOpts := newServerOpts(opts)
+
+	// Using ReadOpt allows backwards and forwards compatibility with new options in the future
+	jsonSkipDefaults := false
+	_ = serverOpts.ReadOpt("jsonSkipDefaults", &jsonSkipDefaults)
+	jsonCamelCase := false
+	_ = serverOpts.ReadOpt("jsonCamelCase", &jsonCamelCase)
+	var pathPrefix string
+	if ok := serverOpts.ReadOpt("pathPrefix", &pathPrefix); !ok {
+		pathPrefix = "/twirp" // default prefix
+	}
+
+	return &adminServiceServer{
+		AdminService:     svc,
+		hooks:            serverOpts.Hooks,
+		interceptor:      twirp.ChainInterceptors(serverOpts.Interceptors...),
+		pathPrefix:       pathPrefix,
+		jsonSkipDefaults: jsonSkipDefaults,
+		jsonCamelCase:    jsonCamelCase,
+	}
+}
+
+// writeError writes an HTTP response with a valid Twirp error format, and triggers hooks.
+// If err is not a twirp.Error, it will get wrapped with twirp.InternalErrorWith(err)
+func (s *adminServiceServer) writeError(ctx context.Context, resp http.ResponseWriter, err error) {
+	writeError(ctx, resp, err, s.hooks)
+}
+
+// handleRequestBodyError is used to handle error when the twirp server cannot read request
+func (s *adminServiceServer) handleRequestBodyError(ctx context.Context, resp http.ResponseWriter, msg string, err error) {
+	if context.Canceled == ctx.Err() {
+		s.writeError(ctx, resp, twirp.NewError(twirp.Canceled, "failed to read request: context canceled"))
+		return
+	}
+	if context.DeadlineExceeded == ctx.Err() {
+		s.writeError(ctx, resp, twirp.NewError(twirp.DeadlineExceeded, "failed to read request: deadline exceeded"))
+		return
+	}
+	s.writeError(ctx, resp, twirp.WrapError(malformedRequestError(msg), err))
+}
+
+// AdminServicePathPrefix is a convenience constant that may identify URL paths.
+// Should be used with caution, it only matches routes generated by Twirp Go clients,
+// with the default "/twirp" prefix and default CamelCase service and method names.
+// More info: https://twitchtv.github.io/twirp/docs/routing.html
+const AdminServicePathPrefix = "/twirp/acai.admin.AdminService/"
+
+func (s *adminServiceServer) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	ctx = ctxsetters.WithPackageName(ctx, "acai.admin")
+	ctx = ctxsetters.WithServiceName(ctx, "AdminService")
+	ctx = ctxsetters.WithResponseWriter(ctx, resp)
+
+	var err error
+	ctx, err = callRequestReceived(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	if req.Method != "POST" {
+		msg := fmt.Sprintf("unsupported method %q (only POST is allowed)", req.Method)
+		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+		return
+	}
+
+	// Verify path format: [<prefix>]/<package>.<Service>/<Method>
+	prefix, pkgService, method := parseTwirpPath(req.URL.Path)
+	if pkgService != "acai.admin.AdminService" {
+		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
+		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+		return
+	}
+	if prefix != s.pathPrefix {
+		msg := fmt.Sprintf("invalid path prefix %q, expected %q, on path %q", prefix, s.pathPrefix, req.URL.Path)
+		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+		return
+	}
+
+	switch method {
+	case "ListWebhookSubscriptions":
+		s.serveListWebhookSubscriptions(ctx, resp, req)
+		return
+	case "ListFailedDeliveries":
+		s.serveListFailedDeliveries(ctx, resp, req)
+		return
+	case "ReplayFailedDelivery":
+		s.serveReplayFailedDelivery(ctx, resp, req)
+		return
+	case "ListFeedback":
+		s.serveListFeedback(ctx, resp, req)
+		return
+	case "ListPersonas":
+		s.serveListPersonas(ctx, resp, req)
+		return
+	case "CreatePersona":
+		s.serveCreatePersona(ctx, resp, req)
+		return
+	case "UpdatePersona":
+		s.serveUpdatePersona(ctx, resp, req)
+		return
+	case "DeletePersona":
+		s.serveDeletePersona(ctx, resp, req)
+		return
+	default:
+		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
+		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+		return
+	}
+}
+
+func (s *adminServiceServer) serveListWebhookSubscriptions(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveListWebhookSubscriptionsJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveListWebhookSubscriptionsProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *adminServiceServer) serveListWebhookSubscriptionsJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListWebhookSubscriptions")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(ListWebhookSubscriptionsRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.AdminService.ListWebhookSubscriptions
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ListWebhookSubscriptionsRequest) (*ListWebhookSubscriptionsResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListWebhookSubscriptionsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListWebhookSubscriptionsRequest) when calling interceptor")
+					}
+					return s.AdminService.ListWebhookSubscriptions(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListWebhookSubscriptionsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListWebhookSubscriptionsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ListWebhookSubscriptionsResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListWebhookSubscriptionsResponse and nil error while calling ListWebhookSubscriptions. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *adminServiceServer) serveListWebhookSubscriptionsProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListWebhookSubscriptions")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(ListWebhookSubscriptionsRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.AdminService.ListWebhookSubscriptions
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ListWebhookSubscriptionsRequest) (*ListWebhookSubscriptionsResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListWebhookSubscriptionsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListWebhookSubscriptionsRequest) when calling interceptor")
+					}
+					return s.AdminService.ListWebhookSubscriptions(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListWebhookSubscriptionsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListWebhookSubscriptionsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ListWebhookSubscriptionsResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListWebhookSubscriptionsResponse and nil error while calling ListWebhookSubscriptions. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *adminServiceServer) serveListFailedDeliveries(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveListFailedDeliveriesJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveListFailedDeliveriesProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *adminServiceServer) serveListFailedDeliveriesJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListFailedDeliveries")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(ListFailedDeliveriesRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.AdminService.ListFailedDeliveries
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ListFailedDeliveriesRequest) (*ListFailedDeliveriesResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListFailedDeliveriesRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListFailedDeliveriesRequest) when calling interceptor")
+					}
+					return s.AdminService.ListFailedDeliveries(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListFailedDeliveriesResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListFailedDeliveriesResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ListFailedDeliveriesResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListFailedDeliveriesResponse and nil error while calling ListFailedDeliveries. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *adminServiceServer) serveListFailedDeliveriesProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListFailedDeliveries")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(ListFailedDeliveriesRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.AdminService.ListFailedDeliveries
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ListFailedDeliveriesRequest) (*ListFailedDeliveriesResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListFailedDeliveriesRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListFailedDeliveriesRequest) when calling interceptor")
+					}
+					return s.AdminService.ListFailedDeliveries(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListFailedDeliveriesResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListFailedDeliveriesResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ListFailedDeliveriesResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListFailedDeliveriesResponse and nil error while calling ListFailedDeliveries. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *adminServiceServer) serveReplayFailedDelivery(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveReplayFailedDeliveryJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveReplayFailedDeliveryProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *adminServiceServer) serveReplayFailedDeliveryJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ReplayFailedDelivery")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(ReplayFailedDeliveryRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.AdminService.ReplayFailedDelivery
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ReplayFailedDeliveryRequest) (*ReplayFailedDeliveryResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ReplayFailedDeliveryRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ReplayFailedDeliveryRequest) when calling interceptor")
+					}
+					return s.AdminService.ReplayFailedDelivery(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ReplayFailedDeliveryResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ReplayFailedDeliveryResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ReplayFailedDeliveryResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ReplayFailedDeliveryResponse and nil error while calling ReplayFailedDelivery. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *adminServiceServer) serveReplayFailedDeliveryProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ReplayFailedDelivery")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(ReplayFailedDeliveryRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.AdminService.ReplayFailedDelivery
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ReplayFailedDeliveryRequest) (*ReplayFailedDeliveryResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ReplayFailedDeliveryRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ReplayFailedDeliveryRequest) when calling interceptor")
+					}
+					return s.AdminService.ReplayFailedDelivery(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ReplayFailedDeliveryResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ReplayFailedDeliveryResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ReplayFailedDeliveryResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ReplayFailedDeliveryResponse and nil error while calling ReplayFailedDelivery. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *adminServiceServer) serveListFeedback(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveListFeedbackJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveListFeedbackProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *adminServiceServer) serveListFeedbackJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListFeedback")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(ListFeedbackRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.AdminService.ListFeedback
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ListFeedbackRequest) (*ListFeedbackResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListFeedbackRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListFeedbackRequest) when calling interceptor")
+					}
+					return s.AdminService.ListFeedback(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListFeedbackResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListFeedbackResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ListFeedbackResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListFeedbackResponse and nil error while calling ListFeedback. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *adminServiceServer) serveListFeedbackProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListFeedback")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(ListFeedbackRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.AdminService.ListFeedback
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ListFeedbackRequest) (*ListFeedbackResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListFeedbackRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListFeedbackRequest) when calling interceptor")
+					}
+					return s.AdminService.ListFeedback(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListFeedbackResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListFeedbackResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ListFeedbackResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListFeedbackResponse and nil error while calling ListFeedback. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *adminServiceServer) serveListPersonas(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveListPersonasJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveListPersonasProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *adminServiceServer) serveListPersonasJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListPersonas")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(ListPersonasRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.AdminService.ListPersonas
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ListPersonasRequest) (*ListPersonasResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListPersonasRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListPersonasRequest) when calling interceptor")
+					}
+					return s.AdminService.ListPersonas(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListPersonasResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListPersonasResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ListPersonasResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListPersonasResponse and nil error while calling ListPersonas. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *adminServiceServer) serveListPersonasProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListPersonas")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(ListPersonasRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.AdminService.ListPersonas
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ListPersonasRequest) (*ListPersonasResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListPersonasRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListPersonasRequest) when calling interceptor")
+					}
+					return s.AdminService.ListPersonas(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListPersonasResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListPersonasResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ListPersonasResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListPersonasResponse and nil error while calling ListPersonas. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *adminServiceServer) serveCreatePersona(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveCreatePersonaJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveCreatePersonaProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *adminServiceServer) serveCreatePersonaJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "CreatePersona")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(CreatePersonaRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.AdminService.CreatePersona
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *CreatePersonaRequest) (*CreatePersonaResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*CreatePersonaRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*CreatePersonaRequest) when calling interceptor")
+					}
+					return s.AdminService.CreatePersona(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*CreatePersonaResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*CreatePersonaResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *CreatePersonaResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *CreatePersonaResponse and nil error while calling CreatePersona. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *adminServiceServer) serveCreatePersonaProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "CreatePersona")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(CreatePersonaRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.AdminService.CreatePersona
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *CreatePersonaRequest) (*CreatePersonaResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*CreatePersonaRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*CreatePersonaRequest) when calling interceptor")
+					}
+					return s.AdminService.CreatePersona(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*CreatePersonaResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*CreatePersonaResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *CreatePersonaResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *CreatePersonaResponse and nil error while calling CreatePersona. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *adminServiceServer) serveUpdatePersona(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveUpdatePersonaJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveUpdatePersonaProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *adminServiceServer) serveUpdatePersonaJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "UpdatePersona")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(UpdatePersonaRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.AdminService.UpdatePersona
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *UpdatePersonaRequest) (*UpdatePersonaResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*UpdatePersonaRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*UpdatePersonaRequest) when calling interceptor")
+					}
+					return s.AdminService.UpdatePersona(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*UpdatePersonaResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*UpdatePersonaResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *UpdatePersonaResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *UpdatePersonaResponse and nil error while calling UpdatePersona. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *adminServiceServer) serveUpdatePersonaProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "UpdatePersona")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(UpdatePersonaRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.AdminService.UpdatePersona
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *UpdatePersonaRequest) (*UpdatePersonaResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*UpdatePersonaRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*UpdatePersonaRequest) when calling interceptor")
+					}
+					return s.AdminService.UpdatePersona(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*UpdatePersonaResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*UpdatePersonaResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *UpdatePersonaResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *UpdatePersonaResponse and nil error while calling UpdatePersona. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *adminServiceServer) serveDeletePersona(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveDeletePersonaJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveDeletePersonaProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *adminServiceServer) serveDeletePersonaJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "DeletePersona")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(DeletePersonaRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.AdminService.DeletePersona
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *DeletePersonaRequest) (*DeletePersonaResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DeletePersonaRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DeletePersonaRequest) when calling interceptor")
+					}
+					return s.AdminService.DeletePersona(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DeletePersonaResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DeletePersonaResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *DeletePersonaResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *DeletePersonaResponse and nil error while calling DeletePersona. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *adminServiceServer) serveDeletePersonaProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "DeletePersona")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(DeletePersonaRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.AdminService.DeletePersona
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *DeletePersonaRequest) (*DeletePersonaResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DeletePersonaRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DeletePersonaRequest) when calling interceptor")
+					}
+					return s.AdminService.DeletePersona(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DeletePersonaResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DeletePersonaResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *DeletePersonaResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *DeletePersonaResponse and nil error while calling DeletePersona. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *adminServiceServer) ServiceDescriptor() ([]byte, int) {
+	return twirpFileDescriptor0, 0
+}
+
+func (s *adminServiceServer) ProtocGenTwirpVersion() string {
+	return "v8.1.3"
+}
+
+// PathPrefix returns the base service path, in the form: "/<prefix>/<package>.<Service>/"
+// that is everything in a Twirp route except for the <Method>. This can be used for routing,
+// for example to identify the requests that are targeted to this service in a mux.
+func (s *adminServiceServer) PathPrefix() string {
+	return baseServicePath(s.pathPrefix, "acai.admin", "AdminService")
+}
+
+// =====
+// Utils
+// =====
+
+// HTTPClient is the interface used by generated clients to send HTTP requests.
+// It is fulfilled by *(net/http).Client, which is sufficient for most users.
+// Users can provide their own implementation for special retry policies.
+//
+// HTTPClient implementations should not follow redirects. Redirects are
+// automatically disabled if *(net/http).Client is passed to client
+// constructors. See the withoutRedirects function in this file for more
+// details.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// TwirpServer is the interface generated server structs will support: they're
+// HTTP handlers with additional methods for accessing metadata about the
+// service. Those accessors are a low-level API for building reflection tools.
+// Most people can think of TwirpServers as just http.Handlers.
+type TwirpServer interface {
+	http.Handler
+
+	// ServiceDescriptor returns gzipped bytes describing the .proto file that
+	// this service was generated from. Once unzipped, the bytes can be
+	// unmarshalled as a
+	// google.golang.org/protobuf/types/descriptorpb.FileDescriptorProto.
+	//
+	// The returned integer is the index of this particular service within that
+	// FileDescriptorProto's 'Service' slice of ServiceDescriptorProtos. This is a
+	// low-level field, expected to be used for reflection.
+	ServiceDescriptor() ([]byte, int)
+
+	// ProtocGenTwirpVersion is the semantic version string of the version of
+	// twirp used to generate this file.
+	ProtocGenTwirpVersion() string
+
+	// PathPrefix returns the HTTP URL path prefix for all methods handled by this
+	// service. This can be used with an HTTP mux to route Twirp requests.
+	// The path prefix is in the form: "/<prefix>/<package>.<Service>/"
+	// that is, everything in a Twirp route except for the <Method> at the end.
+	PathPrefix() string
+}
+
+func newServerOpts(opts []interface{}) *twirp.ServerOptions {
+	serverOpts := &twirp.ServerOptions{}
+	for _, opt := range opts {
+		switch o := opt.(type) {
+		case twirp.ServerOption:
+			o(serverOpts)
+		case *twirp.ServerHooks: // backwards compatibility, allow to specify hooks as an argument
+			twirp.WithServerHooks(o)(serverOpts)
+		case nil: // backwards compatibility, allow nil value for the argument
+			continue
+		default:
+			panic(fmt.Sprintf("Invalid option type %T, please use a twirp.ServerOption", o))
+		}
+	}
+	return serverOpts
+}
+
+// WriteError writes an HTTP response with a valid Twirp error format (code, msg, meta).
+// Useful outside of the Twirp server (e.g. http middleware), but does not trigger hooks.
+// If err is not a twirp.Error, it will get wrapped with twirp.InternalErrorWith(err)
+func WriteError(resp http.ResponseWriter, err error) {
+	writeError(context.Background(), resp, err, nil)
+}
+
+// writeError writes Twirp errors in the response and triggers hooks.
+func writeError(ctx context.Context, resp http.ResponseWriter, err error, hooks *twirp.ServerHooks) {
+	// Convert to a twirp.Error. Non-twirp errors are converted to internal errors.
+	var twerr twirp.Error
+	if !errors.As(err, &twerr) {
+		twerr = twirp.InternalErrorWith(err)
+	}
+
+	statusCode := twirp.ServerHTTPStatusFromErrorCode(twerr.Code())
+	ctx = ctxsetters.WithStatusCode(ctx, statusCode)
+	ctx = callError(ctx, hooks, twerr)
+
+	respBody := marshalErrorToJSON(twerr)
+
+	resp.Header().Set("Content-Type", "application/json") // Error responses are always JSON
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBody)))
+	resp.WriteHeader(statusCode) // set HTTP status code and send response
+
+	_, writeErr := resp.Write(respBody)
+	if writeErr != nil {
+		// We have three options here. We could log the error, call the Error
+		// hook, or just silently ignore the error.
+		//
+		// Logging is unacceptable because we don't have a user-controlled
+		// logger; writing out to stderr without permission is too rude.
+		//
+		// Calling the Error hook would confuse users: it would mean the Error
+		// hook got called twice for one request, which is likely to lead to
+		// duplicated log messages and metrics, no matter how well we document
+		// the behavior.
+		//
+		// Silently ignoring the error is our least-bad option. It's highly
+		// likely that the connection is broken and the original 'err' says
+		// so anyway.
+		_ = writeErr
+	}
+
+	callResponseSent(ctx, hooks)
+}
+
+// sanitizeBaseURL parses the the baseURL, and adds the "http" scheme if needed.
+// If the URL is unparsable, the baseURL is returned unchanged.
+func sanitizeBaseURL(baseURL string) string {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return baseURL // invalid URL will fail later when making requests
+	}
+	if u.Scheme == "" {
+		u.Scheme = "http"
+	}
+	return u.String()
+}
+
+// baseServicePath composes the path prefix for the service (without <Method>).
+// e.g.: baseServicePath("/twirp", "my.pkg", "MyService")
+//
+//	returns => "/twirp/my.pkg.MyService/"
+//
+// e.g.: baseServicePath("", "", "MyService")
+//
+//	returns => "/MyService/"
+func baseServicePath(prefix, pkg, service string) string {
+	fullServiceName := service
+	if pkg != "" {
+		fullServiceName = pkg + "." + service
+	}
+	return path.Join("/", prefix, fullServiceName) + "/"
+}
+
+// parseTwirpPath extracts path components form a valid Twirp route.
+// Expected format: "[<prefix>]/<package>.<Service>/<Method>"
+// e.g.: prefix, pkgService, method := parseTwirpPath("/twirp/pkg.Svc/MakeHat")
+func parseTwirpPath(path string) (string, string, string) {
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 {
+		return "", "", ""
+	}
+	method := parts[len(parts)-1]
+	pkgService := parts[len(parts)-2]
+	prefix := strings.Join(parts[0:len(parts)-2], "/")
+	return prefix, pkgService, method
+}
+
+// getCustomHTTPReqHeaders retrieves a copy of any headers that are set in
+// a context through the twirp.WithHTTPRequestHeaders function.
+// If there are no headers set, or if they have the wrong type, nil is returned.
+func getCustomHTTPReqHeaders(ctx context.Context) http.Header {
+	header, ok := twirp.HTTPRequestHeaders(ctx)
+	if !ok || header == nil {
+		return nil
+	}
+	copied := make(http.Header)
+	for k, vv := range header {
+		if vv == nil {
+			copied[k] = nil
+			continue
+		}
+		copied[k] = make([]string, len(vv))
+		copy(copied[k], vv)
+	}
+	return copied
+}
+
+// newRequest makes an http.Request from a client, adding common headers.
+func newRequest(ctx context.Context, url string, reqBody io.Reader, contentType string) (*http.Request, error) {
+	req, err := http.NewRequest("POST", url, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if customHeader := getCustomHTTPReqHeaders(ctx); customHeader != nil {
+		req.Header = customHeader
+	}
+	req.Header.Set("Accept", contentType)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Twirp-Version", "v8.1.3")
+	return req, nil
+}
+
+// JSON serialization for errors
+type twerrJSON struct {
+	Code string            `json:"code"`
+	Msg  string            `json:"msg"`
+	Meta map[string]string `json:"meta,omitempty"`
+}
+
+// marshalErrorToJSON returns JSON from a twirp.Error, that can be used as HTTP error response body.
+// If serialization fails, it will use a descriptive Internal error instead.
+func marshalErrorToJSON(twerr twirp.Error) []byte {
+	// make sure that msg is not too large
+	msg := twerr.Msg()
+	if len(msg) > 1e6 {
+		msg = msg[:1e6]
+	}
+
+	tj := twerrJSON{
+		Code: string(twerr.Code()),
+		Msg:  msg,
+		Meta: twerr.MetaMap(),
+	}
+
+	buf, err := json.Marshal(&tj)
+	if err != nil {
+		buf = []byte("{\"type\": \"" + twirp.Internal + "\", \"msg\": \"There was an error but it could not be serialized into JSON\"}") // fallback
+	}
+
+	return buf
+}
+
+// errorFromResponse builds a twirp.Error from a non-200 HTTP response.
+// If the response has a valid serialized Twirp error, then it's returned.
+// If not, the response status code is used to generate a similar twirp
+// error. See twirpErrorFromIntermediary for more info on intermediary errors.
+func errorFromResponse(resp *http.Response) twirp.Error {
+	statusCode := resp.StatusCode
+	statusText := http.StatusText(statusCode)
+
+	if isHTTPRedirect(statusCode) {
+		// Unexpected redirect: it must be an error from an intermediary.
+		// Twirp clients don't follow redirects automatically, Twirp only handles
+		// POST requests, redirects should only happen on GET and HEAD requests.
+		location := resp.Header.Get("Location")
+		msg := fmt.Sprintf("unexpected HTTP status code %d %q received, Location=%q", statusCode, statusText, location)
+		return twirpErrorFromIntermediary(statusCode, msg, location)
+	}
+
+	respBodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return wrapInternal(err, "failed to read server error response body")
+	}
+
+	var tj twerrJSON
+	dec := json.NewDecoder(bytes.NewReader(respBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&tj); err != nil || tj.Code == "" {
+		// Invalid JSON response; it must be an error from an intermediary.
+		msg := fmt.Sprintf("Error from intermediary with HTTP status code %d %q", statusCode, statusText)
+		return twirpErrorFromIntermediary(statusCode, msg, string(respBodyBytes))
+	}
+
+	errorCode := twirp.ErrorCode(tj.Code)
+	if !twirp.IsValidErrorCode(errorCode) {
+		msg := "invalid type returned from server error response: " + tj.Code
+		return twirp.InternalError(msg).WithMeta("body", string(respBodyBytes))
+	}
+
+	twerr := twirp.NewError(errorCode, tj.Msg)
+	for k, v := range tj.Meta {
+		twerr = twerr.WithMeta(k, v)
+	}
+	return twerr
+}
+
+// twirpErrorFromIntermediary maps HTTP errors from non-twirp sources to twirp errors.
+// The mapping is similar to gRPC: https://github.com/grpc/grpc/blob/master/doc/http-grpc-status-mapping.md.
+// Returned twirp Errors have some additional metadata for inspection.
+func twirpErrorFromIntermediary(status int, msg string, bodyOrLocation string) twirp.Error {
+	var code twirp.ErrorCode
+	if isHTTPRedirect(status) { // 3xx
+		code = twirp.Internal
+	} else {
+		switch status {
+		case 400: // Bad Request
+			code = twirp.Internal
+		case 401: // Unauthorized
+			code = twirp.Unauthenticated
+		case 403: // Forbidden
+			code = twirp.PermissionDenied
+		case 404: // Not Found
+			code = twirp.BadRoute
+		case 429: // Too Many Requests
+			code = twirp.ResourceExhausted
+		case 502, 503, 504: // Bad Gateway, Service Unavailable, Gateway Timeout
+			code = twirp.Unavailable
+		default: // All other codes
+			code = twirp.Unknown
+		}
+	}
+
+	twerr := twirp.NewError(code, msg)
+	twerr = twerr.WithMeta("http_error_from_intermediary", "true") // to easily know if this error was from intermediary
+	twerr = twerr.WithMeta("status_code", strconv.Itoa(status))
+	if isHTTPRedirect(status) {
+		twerr = twerr.WithMeta("location", bodyOrLocation)
+	} else {
+		twerr = twerr.WithMeta("body", bodyOrLocation)
+	}
+	return twerr
+}
+
+func isHTTPRedirect(status int) bool {
+	return status >= 300 && status <= 399
+}
+
+// wrapInternal wraps an error with a prefix as an Internal error.
+// The original error cause is accessible by github.com/pkg/errors.Cause.
+func wrapInternal(err error, prefix string) twirp.Error {
+	return twirp.InternalErrorWith(&wrappedError{prefix: prefix, cause: err})
+}
+
+type wrappedError struct {
+	prefix string
+	cause  error
+}
+
+func (e *wrappedError) Error() string { return e.prefix + ": " + e.cause.Error() }
+func (e *wrappedError) Unwrap() error { return e.cause } // for go1.13 + errors.Is/As
+func (e *wrappedError) Cause() error  { return e.cause } // for github.com/pkg/errors
+
+// ensurePanicResponses makes sure that rpc methods causing a panic still result in a Twirp Internal
+// error response (status 500), and error hooks are properly called with the panic wrapped as an error.
+// The panic is re-raised so it can be handled normally with middleware.
+func ensurePanicResponses(ctx context.Context, resp http.ResponseWriter, hooks *twirp.ServerHooks) {
+	if r := recover(); r != nil {
+		// Wrap the panic as an error so it can be passed to error hooks.
+		// The original error is accessible from error hooks, but not visible in the response.
+		err := errFromPanic(r)
+		twerr := &internalWithCause{msg: "Internal service panic", cause: err}
+		// Actually write the error
+		writeError(ctx, resp, twerr, hooks)
+		// If possible, flush the error to the wire.
+		f, ok := resp.(http.Flusher)
+		if ok {
+			f.Flush()
+		}
+
+		panic(r)
+	}
+}
+
+// errFromPanic returns the typed error if the recovered panic is an error, otherwise formats as error.
+func errFromPanic(p interface{}) error {
+	if err, ok := p.(error); ok {
+		return err
+	}
+	return fmt.Errorf("panic: %v", p)
+}
+
+// internalWithCause is a Twirp Internal error wrapping an original error cause,
+// but the original error message is not exposed on Msg(). The original error
+// can be checked with go1.13+ errors.Is/As, and also by (github.com/pkg/errors).Unwrap
+type internalWithCause struct {
+	msg   string
+	cause error
+}
+
+func (e *internalWithCause) Unwrap() error                               { return e.cause } // for go1.13 + errors.Is/As
+func (e *internalWithCause) Cause() error                                { return e.cause } // for github.com/pkg/errors
+func (e *internalWithCause) Error() string                               { return e.msg + ": " + e.cause.Error() }
+func (e *internalWithCause) Code() twirp.ErrorCode                       { return twirp.Internal }
+func (e *internalWithCause) Msg() string                                 { return e.msg }
+func (e *internalWithCause) Meta(key string) string                      { return "" }
+func (e *internalWithCause) MetaMap() map[string]string                  { return nil }
+func (e *internalWithCause) WithMeta(key string, val string) twirp.Error { return e }
+
+// malformedRequestError is used when the twirp server cannot unmarshal a request
+func malformedRequestError(msg string) twirp.Error {
+	return twirp.NewError(twirp.Malformed, msg)
+}
+
+// badRouteError is used when the twirp server cannot route a request
+func badRouteError(msg string, method, url string) twirp.Error {
+	err := twirp.NewError(twirp.BadRoute, msg)
+	err = err.WithMeta("twirp_invalid_route", method+" "+url)
+	return err
+}
+
+// withoutRedirects makes sure that the POST request can not be redirected.
+// The standard library will, by default, redirect requests (including POSTs) if it gets a 302 or
+// 303 response, and also 301s in go1.8. It redirects by making a second request, changing the
+// method to GET and removing the body. This produces very confusing error messages, so instead we
+// set a redirect policy that always errors. This stops Go from executing the redirect.
+//
+// We have to be a little careful in case the user-provided http.Client has its own CheckRedirect
+// policy - if so, we'll run through that policy first.
+//
+// Because this requires modifying the http.Client, we make a new copy of the client and return it.
+func withoutRedirects(in *http.Client) *http.Client {
+	copy := *in
+	copy.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if in.CheckRedirect != nil {
+			// Run the input's redirect if it exists, in case it has side effects, but ignore any error it
+			// returns, since we want to use ErrUseLastResponse.
+			err := in.CheckRedirect(req, via)
+			_ = err // Silly, but this makes sure generated code passes errcheck -blank, which some people use.
+		}
+		return http.ErrUseLastResponse
+	}
+	return &copy
+}
+
+// doProtobufRequest makes a Protobuf request to the remote Twirp service.
+func doProtobufRequest(ctx context.Context, client HTTPClient, hooks *twirp.ClientHooks, url string, in, out proto.Message) (_ context.Context, err error) {
+	reqBodyBytes, err := proto.Marshal(in)
+	if err != nil {
+		return ctx, wrapInternal(err, "failed to marshal proto request")
+	}
+	reqBody := bytes.NewBuffer(reqBodyBytes)
+	if err = ctx.Err(); err != nil {
+		return ctx, wrapInternal(err, "aborted because context was done")
+	}
+
+	req, err := newRequest(ctx, url, reqBody, "application/protobuf")
+	if err != nil {
+		return ctx, wrapInternal(err, "could not build request")
+	}
+	ctx, err = callClientRequestPrepared(ctx, hooks, req)
+	if err != nil {
+		return ctx, err
+	}
+
+	req = req.WithContext(ctx)
+	resp, err := client.Do(req)
+	if err != nil {
+		return ctx, wrapInternal(err, "failed to do request")
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if err = ctx.Err(); err != nil {
+		return ctx, wrapInternal(err, "aborted because context was done")
+	}
+
+	if resp.StatusCode != 200 {
+		return ctx, errorFromResponse(resp)
+	}
+
+	respBodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return ctx, wrapInternal(err, "failed to read response body")
+	}
+	if err = ctx.Err(); err != nil {
+		return ctx, wrapInternal(err, "aborted because context was done")
+	}
+
+	if err = proto.Unmarshal(respBodyBytes, out); err != nil {
+		return ctx, wrapInternal(err, "failed to unmarshal proto response")
+	}
+	return ctx, nil
+}
+
+// doJSONRequest makes a JSON request to the remote Twirp service.
+func doJSONRequest(ctx context.Context, client HTTPClient, hooks *twirp.ClientHooks, url string, in, out proto.Message) (_ context.Context, err error) {
+	marshaler := &protojson.MarshalOptions{UseProtoNames: true}
+	reqBytes, err := marshaler.Marshal(in)
+	if err != nil {
+		return ctx, wrapInternal(err, "failed to marshal json request")
+	}
+	if err = ctx.Err(); err != nil {
+		return ctx, wrapInternal(err, "aborted because context was done")
+	}
+
+	req, err := newRequest(ctx, url, bytes.NewReader(reqBytes), "application/json")
+	if err != nil {
+		return ctx, wrapInternal(err, "could not build request")
+	}
+	ctx, err = callClientRequestPrepared(ctx, hooks, req)
+	if err != nil {
+		return ctx, err
+	}
+
+	req = req.WithContext(ctx)
+	resp, err := client.Do(req)
+	if err != nil {
+		return ctx, wrapInternal(err, "failed to do request")
+	}
+
+	defer func() {
+		cerr := resp.Body.Close()
+		if err == nil && cerr != nil {
+			err = wrapInternal(cerr, "failed to close response body")
+		}
+	}()
+
+	if err = ctx.Err(); err != nil {
+		return ctx, wrapInternal(err, "aborted because context was done")
+	}
+
+	if resp.StatusCode != 200 {
+		return ctx, errorFromResponse(resp)
+	}
+
+	d := json.NewDecoder(resp.Body)
+	rawRespBody := json.RawMessage{}
+	if err := d.Decode(&rawRespBody); err != nil {
+		return ctx, wrapInternal(err, "failed to unmarshal json response")
+	}
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawRespBody, out); err != nil {
+		return ctx, wrapInternal(err, "failed to unmarshal json response")
+	}
+	if err = ctx.Err(); err != nil {
+		return ctx, wrapInternal(err, "aborted because context was done")
+	}
+	return ctx, nil
+}
+
+// Call twirp.ServerHooks.RequestReceived if the hook is available
+func callRequestReceived(ctx context.Context, h *twirp.ServerHooks) (context.Context, error) {
+	if h == nil || h.RequestReceived == nil {
+		return ctx, nil
+	}
+	return h.RequestReceived(ctx)
+}
+
+// Call twirp.ServerHooks.RequestRouted if the hook is available
+func callRequestRouted(ctx context.Context, h *twirp.ServerHooks) (context.Context, error) {
+	if h == nil || h.RequestRouted == nil {
+		return ctx, nil
+	}
+	return h.RequestRouted(ctx)
+}
+
+// Call twirp.ServerHooks.ResponsePrepared if the hook is available
+func callResponsePrepared(ctx context.Context, h *twirp.ServerHooks) context.Context {
+	if h == nil || h.ResponsePrepared == nil {
+		return ctx
+	}
+	return h.ResponsePrepared(ctx)
+}
+
+// Call twirp.ServerHooks.ResponseSent if the hook is available
+func callResponseSent(ctx context.Context, h *twirp.ServerHooks) {
+	if h == nil || h.ResponseSent == nil {
+		return
+	}
+	h.ResponseSent(ctx)
+}
+
+// Call twirp.ServerHooks.Error if the hook is available
+func callError(ctx context.Context, h *twirp.ServerHooks, err twirp.Error) context.Context {
+	if h == nil || h.Error == nil {
+		return ctx
+	}
+	return h.Error(ctx, err)
+}
+
+func callClientResponseReceived(ctx context.Context, h *twirp.ClientHooks) {
+	if h == nil || h.ResponseReceived == nil {
+		return
+	}
+	h.ResponseReceived(ctx)
+}
+
+func callClientRequestPrepared(ctx context.Context, h *twirp.ClientHooks, req *http.Request) (context.Context, error) {
+	if h == nil || h.RequestPrepared == nil {
+		return ctx, nil
+	}
+	return h.RequestPrepared(ctx, req)
+}
+
+func callClientError(ctx context.Context, h *twirp.ClientHooks, err twirp.Error) {
+	if h == nil || h.Error == nil {
+		return
+	}
+	h.Error(ctx, err)
+}
+
+var twirpFileDescriptor0 = []byte{
+	// 869 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x54, 0xdf, 0x6f, 0x1b, 0x45,
+	0x10, 0xee, 0xd9, 0xb5, 0x13, 0x8f, 0x63, 0x17, 0x6d, 0x1c, 0x38, 0xae, 0x85, 0x5c, 0x16, 0xa1,
+	0x5a, 0x40, 0x6d, 0x14, 0x5e, 0x10, 0x6f, 0x84, 0xa6, 0xb4, 0x12, 0x12, 0xe5, 0x1a, 0x84, 0x54,
+	0x21, 0x59, 0x6b, 0xdf, 0x34, 0x5d, 0xf5, 0x7e, 0xb1, 0xbb, 0x8e, 0xe4, 0x47, 0xc4, 0x0b, 0xe2,
+	0x2f, 0xe6, 0x11, 0xdd, 0xee, 0x9e, 0xef, 0xce, 0x39, 0xdb, 0x10, 0xf1, 0x76, 0xfb, 0xdd, 0x37,
+	0xdf, 0xce, 0xce, 0xcc, 0x37, 0xf0, 0x40, 0x64, 0x8b, 0x29, 0x0b, 0x63, 0x9e, 0x4c, 0x32, 0x91,
+	0xaa, 0x94, 0x00, 0x5b, 0x30, 0x3e, 0xd1, 0x88, 0x77, 0x7a, 0x9d, 0xa6, 0xd7, 0x11, 0x4e, 0xf5,
+	0x9f, 0xf9, 0xf2, 0xcd, 0x54, 0xf1, 0x18, 0xa5, 0x62, 0x71, 0x66, 0xc8, 0xde, 0x30, 0x8f, 0x5e,
+	0xbc, 0x65, 0xca, 0x9c, 0xe9, 0x8f, 0x70, 0xfc, 0x0b, 0xce, 0xdf, 0xa6, 0xe9, 0xbb, 0x57, 0xcb,
+	0xb9, 0x5c, 0x08, 0x9e, 0x29, 0x9e, 0x26, 0x64, 0x08, 0x2d, 0x1e, 0xba, 0x8e, 0xef, 0x8c, 0x7b,
+	0x41, 0x8b, 0x87, 0xe4, 0x3d, 0x68, 0x2f, 0x45, 0xe4, 0xb6, 0x34, 0x90, 0x7f, 0x92, 0xf7, 0xa1,
+	0x8b, 0x37, 0x98, 0x28, 0xe9, 0xb6, 0xfd, 0xf6, 0xb8, 0x17, 0xd8, 0x13, 0xfd, 0xdb, 0x81, 0xe1,
+	0x33, 0xc6, 0x23, 0x0c, 0x9f, 0x62, 0xc4, 0x6f, 0x50, 0xac, 0x6e, 0x89, 0x3d, 0x86, 0x07, 0xb2,
+	0x72, 0xd9, 0x8c, 0x87, 0x56, 0x78, 0x58, 0x85, 0x5f, 0x84, 0xe4, 0x43, 0x38, 0xd4, 0xaa, 0x39,
+	0xa3, 0xad, 0x19, 0x07, 0xfa, 0xfc, 0x22, 0x24, 0x1f, 0x01, 0x98, 0x5f, 0x6a, 0x95, 0xa1, 0x7b,
+	0x5f, 0xff, 0xec, 0x69, 0xe4, 0x6a, 0x95, 0x21, 0xf1, 0xe0, 0x90, 0x29, 0x85, 0x71, 0xa6, 0xa4,
+	0xdb, 0xf1, 0x9d, 0x71, 0x27, 0x58, 0x9f, 0xf3, 0xd0, 0x88, 0x49, 0x35, 0x43, 0x21, 0x52, 0xe1,
+	0x76, 0x4d, 0x68, 0x8e, 0x5c, 0xe6, 0x00, 0xf9, 0x1a, 0x7a, 0xeb, 0xa2, 0xb9, 0x07, 0xbe, 0x33,
+	0xee, 0x9f, 0x7b, 0x13, 0x53, 0xd6, 0x49, 0x51, 0xd6, 0xc9, 0x55, 0xc1, 0x08, 0x4a, 0x32, 0x3d,
+	0x83, 0xd3, 0x1f, 0xb8, 0x54, 0x0d, 0xf5, 0x94, 0x01, 0xfe, 0xb6, 0x44, 0xa9, 0x28, 0x07, 0x7f,
+	0x3b, 0x45, 0x66, 0x69, 0x22, 0x91, 0x5c, 0xc2, 0xa0, 0x5a, 0x07, 0xe9, 0x3a, 0x7e, 0x7b, 0xdc,
+	0x3f, 0x3f, 0x9d, 0x94, 0x7d, 0x9e, 0x34, 0x08, 0x04, 0xf5, 0x28, 0xfa, 0x2b, 0x3c, 0xcc, 0xaf,
+	0xaa, 0xf5, 0x82, 0x63, 0x91, 0x49, 0x53, 0x13, 0x9c, 0xc6, 0x26, 0x8c, 0xa0, 0x13, 0xf1, 0x98,
+	0x2b, 0xdd, 0xa3, 0x4e, 0x60, 0x0e, 0xf4, 0x35, 0x3c, 0x6a, 0x56, 0xb7, 0x8f, 0xf8, 0x06, 0x20,
+	0x5c, 0xa3, 0xf6, 0x05, 0x5e, 0xf5, 0x05, 0xf5, 0x19, 0x09, 0x2a, 0x6c, 0xfa, 0x04, 0x1e, 0x06,
+	0x98, 0x45, 0x6c, 0xb5, 0xc1, 0xb1, 0x99, 0x6f, 0x8c, 0x13, 0xfd, 0x18, 0x1e, 0x35, 0xd3, 0x4d,
+	0x2a, 0xf4, 0xaf, 0x16, 0x1c, 0xeb, 0x5c, 0x11, 0xc3, 0x39, 0x5b, 0xbc, 0x2b, 0x74, 0xbe, 0x84,
+	0x8e, 0xe4, 0xc9, 0x02, 0xb5, 0xd4, 0xee, 0x26, 0x1b, 0x62, 0x1e, 0xb1, 0x4c, 0x14, 0x37, 0x3e,
+	0xd8, 0x13, 0xa1, 0x89, 0xe4, 0x1c, 0xba, 0x82, 0x29, 0x9e, 0x5c, 0xeb, 0xf9, 0x1d, 0x16, 0x25,
+	0xd0, 0x06, 0x2c, 0xf2, 0x99, 0x04, 0x9a, 0x11, 0x58, 0x26, 0x21, 0x70, 0x5f, 0xa5, 0x69, 0x64,
+	0x87, 0x5a, 0x7f, 0x13, 0x0a, 0xb0, 0x94, 0x18, 0xce, 0xf2, 0x83, 0x99, 0xe8, 0xc3, 0xe7, 0xf7,
+	0x82, 0x5e, 0x8e, 0x5d, 0xe5, 0xd0, 0x9f, 0x8e, 0x53, 0x36, 0xaa, 0x5b, 0x69, 0xd4, 0xc5, 0x00,
+	0xfa, 0xb3, 0x32, 0x94, 0x7e, 0x0f, 0xa3, 0x7a, 0x2d, 0x6c, 0xbf, 0xa6, 0x70, 0xf8, 0xc6, 0x62,
+	0xb6, 0x5b, 0xc7, 0x0d, 0xa9, 0x06, 0x6b, 0x12, 0xfd, 0xbd, 0x05, 0x07, 0x2f, 0x51, 0xc8, 0x34,
+	0x61, 0x79, 0xc6, 0x09, 0x8b, 0xd1, 0xf6, 0x44, 0x7f, 0x13, 0x1f, 0xfa, 0x21, 0xae, 0xe7, 0xc8,
+	0x1a, 0xbc, 0x0a, 0x91, 0x4f, 0x60, 0x20, 0x57, 0x52, 0x61, 0x3c, 0xcb, 0x44, 0x1a, 0x67, 0xca,
+	0x5a, 0xfc, 0xc8, 0x80, 0x2f, 0x35, 0x96, 0x3f, 0x2a, 0x4e, 0x43, 0x2c, 0xaa, 0x61, 0x0e, 0xe4,
+	0x53, 0xe8, 0xe7, 0x66, 0x46, 0xc1, 0xd4, 0x52, 0xa0, 0xae, 0x87, 0xf3, 0xfc, 0x5e, 0x50, 0x05,
+	0x6d, 0x45, 0x4c, 0xc1, 0xba, 0x7a, 0x45, 0x99, 0xc3, 0xdd, 0x0d, 0x7e, 0x31, 0x84, 0xa3, 0x59,
+	0xe5, 0x0a, 0x7a, 0x62, 0x06, 0xcb, 0x96, 0x61, 0x6d, 0x72, 0x5b, 0xe3, 0x12, 0x2e, 0x6b, 0x9c,
+	0x59, 0xac, 0x5e, 0x63, 0xe3, 0x08, 0xcb, 0x0f, 0xd6, 0x24, 0x7a, 0x09, 0xa3, 0xef, 0x04, 0x32,
+	0x85, 0xc5, 0x2f, 0x3b, 0xb9, 0x4f, 0xe0, 0xc0, 0x72, 0xec, 0xec, 0x36, 0xea, 0x14, 0x1c, 0xfa,
+	0x0c, 0x4e, 0x36, 0x64, 0x6c, 0x42, 0xff, 0x51, 0xe7, 0x12, 0x46, 0x3f, 0x67, 0xe1, 0xff, 0x91,
+	0xce, 0x86, 0xcc, 0xdd, 0xd2, 0xf9, 0x0c, 0x46, 0x4f, 0x31, 0xc2, 0x5b, 0xe9, 0x34, 0x4c, 0x23,
+	0xfd, 0x00, 0x4e, 0x36, 0xb8, 0xe6, 0xce, 0xf3, 0x3f, 0xba, 0x70, 0xf4, 0x6d, 0xae, 0xff, 0x0a,
+	0xc5, 0x0d, 0x5f, 0x20, 0x59, 0x82, 0xbb, 0x6d, 0x43, 0x93, 0xcf, 0xab, 0xf9, 0xec, 0x59, 0xf5,
+	0xde, 0x17, 0xff, 0x8e, 0x6c, 0xdf, 0xce, 0xad, 0x2f, 0x37, 0xf6, 0x29, 0x79, 0xbc, 0xa9, 0xb2,
+	0x65, 0x9f, 0x7b, 0xe3, 0xfd, 0xc4, 0xf2, 0xaa, 0xa6, 0x7d, 0x59, 0xbf, 0x6a, 0xc7, 0x02, 0xf6,
+	0xc6, 0xfb, 0x89, 0xf6, 0xaa, 0x9f, 0xe0, 0xa8, 0xba, 0x6d, 0xc8, 0xe9, 0xad, 0x24, 0xeb, 0x3b,
+	0xd9, 0xf3, 0xb7, 0x13, 0xea, 0x92, 0x85, 0xb9, 0x6e, 0x4b, 0x6e, 0xb8, 0xd1, 0xf3, 0xb7, 0x13,
+	0xac, 0xe4, 0x15, 0x0c, 0x6a, 0xfe, 0x20, 0xb5, 0x90, 0x26, 0x07, 0x7a, 0x67, 0x3b, 0x18, 0xa5,
+	0x6a, 0x6d, 0xcc, 0xeb, 0xaa, 0x4d, 0x46, 0xf2, 0xce, 0x76, 0x30, 0x4a, 0xd5, 0xda, 0x20, 0xd7,
+	0x55, 0x9b, 0xfc, 0xe0, 0x9d, 0xed, 0x60, 0x18, 0xd5, 0x8b, 0xc1, 0xeb, 0x3e, 0x4f, 0x14, 0x8a,
+	0x84, 0x45, 0xd3, 0x6c, 0x3e, 0xef, 0xea, 0x35, 0xf8, 0xd5, 0x3f, 0x03, 0x00, 0x3f, 0x02, 0x74,
+	0xb4, 0x6b, 0x0a, 0x00, 0x00,
+}
@@ -16,11 +16,6 @@ import proto "google.golang.org/protobuf/proto"
 import twirp "github.com/twitchtv/twirp"
 import ctxsetters "github.com/twitchtv/twirp/ctxsetters"
 
-import bytes "bytes"
-import errors "errors"
-import path "path"
-import url "net/url"
-
 // Version compatibility assertion.
 // If the constant is not defined in the package, that likely means
 // the package needs to be updated to work with this generated code.
@@ -32,18 +27,37 @@ const _ = twirp.TwirpPackageMinVersion_8_1_0
 // =====================
 
 type ChatService interface {
-	// create a new conversation by sending a message and getting a reply
+	// Create a new conversation by sending a message and getting a reply
 	// use ContinueConversation with the returned conversation_id to continue the conversation
 	StartConversation(context.Context, *StartConversationRequest) (*StartConversationResponse, error)
 
-	// continue an existing conversation by adding a new message and getting a reply
+	// Continue an existing conversation by adding a new message and getting a reply
 	ContinueConversation(context.Context, *ContinueConversationRequest) (*ContinueConversationResponse, error)
 
-	// list most recent conversations
+	// List most recent conversations
 	ListConversations(context.Context, *ListConversationsRequest) (*ListConversationsResponse, error)
 
-	// describe a conversation by its ID
+	// Describe a conversation by its ID
 	DescribeConversation(context.Context, *DescribeConversationRequest) (*DescribeConversationResponse, error)
+
+	// Get the status of a message, typically a reply requested in async mode
+	GetMessageStatus(context.Context, *GetMessageStatusRequest) (*GetMessageStatusResponse, error)
+
+	// Generate again a reply that failed, in async mode
+	RetryMessage(context.Context, *RetryMessageRequest) (*RetryMessageResponse, error)
+
+	// Get what changed since a previous sync: the conversations created or updated, with only their new or updated
+	// messages, and the IDs of the deleted ones
+	SyncConversations(context.Context, *SyncConversationsRequest) (*SyncConversationsResponse, error)
+
+	// Rate a reply of the assistant, replacing any feedback given on it before
+	SubmitFeedback(context.Context, *SubmitFeedbackRequest) (*SubmitFeedbackResponse, error)
+
+	// Title a conversation as chosen by the user, which stops it from being retitled automatically
+	RenameConversation(context.Context, *RenameConversationRequest) (*RenameConversationResponse, error)
+
+	// Generate a new title for a conversation from its messages so far, resuming automatic retitling if it was renamed
+	RegenerateTitle(context.Context, *RegenerateTitleRequest) (*RegenerateTitleResponse, error)
 }
 
 // ===========================
@@ -52,7 +66,7 @@ type ChatService interface {
 
 type chatServiceProtobufClient struct {
 	client      HTTPClient
-	urls        [4]string
+	urls        [10]string
 	interceptor twirp.Interceptor
 	opts        twirp.ClientOptions
 }
@@ -80,11 +94,17 @@ func NewChatServiceProtobufClient(baseURL string, client HTTPClient, opts ...twi
 	// Build method URLs: <baseURL>[<prefix>]/<package>.<Service>/<Method>
 	serviceURL := sanitizeBaseURL(baseURL)
 	serviceURL += baseServicePath(pathPrefix, "acai.chat", "ChatService")
-	urls := [4]string{
+	urls := [10]string{
 		serviceURL + "StartConversation",
 		serviceURL + "ContinueConversation",
 		serviceURL + "ListConversations",
 		serviceURL + "DescribeConversation",
+		serviceURL + "GetMessageStatus",
+		serviceURL + "RetryMessage",
+		serviceURL + "SyncConversations",
+		serviceURL + "SubmitFeedback",
+		serviceURL + "RenameConversation",
+		serviceURL + "RegenerateTitle",
 	}
 
 	return &chatServiceProtobufClient{
@@ -279,13 +299,289 @@ func (c *chatServiceProtobufClient) callDescribeConversation(ctx context.Context
 	return out, nil
 }
 
+func (c *chatServiceProtobufClient) GetMessageStatus(ctx context.Context, in *GetMessageStatusRequest) (*GetMessageStatusResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "GetMessageStatus")
+	caller := c.callGetMessageStatus
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *GetMessageStatusRequest) (*GetMessageStatusResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*GetMessageStatusRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*GetMessageStatusRequest) when calling interceptor")
+					}
+					return c.callGetMessageStatus(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*GetMessageStatusResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*GetMessageStatusResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceProtobufClient) callGetMessageStatus(ctx context.Context, in *GetMessageStatusRequest) (*GetMessageStatusResponse, error) {
+	out := new(GetMessageStatusResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[4], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceProtobufClient) RetryMessage(ctx context.Context, in *RetryMessageRequest) (*RetryMessageResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "RetryMessage")
+	caller := c.callRetryMessage
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *RetryMessageRequest) (*RetryMessageResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RetryMessageRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RetryMessageRequest) when calling interceptor")
+					}
+					return c.callRetryMessage(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RetryMessageResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RetryMessageResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceProtobufClient) callRetryMessage(ctx context.Context, in *RetryMessageRequest) (*RetryMessageResponse, error) {
+	out := new(RetryMessageResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[5], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceProtobufClient) SyncConversations(ctx context.Context, in *SyncConversationsRequest) (*SyncConversationsResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "SyncConversations")
+	caller := c.callSyncConversations
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *SyncConversationsRequest) (*SyncConversationsResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*SyncConversationsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*SyncConversationsRequest) when calling interceptor")
+					}
+					return c.callSyncConversations(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*SyncConversationsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*SyncConversationsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceProtobufClient) callSyncConversations(ctx context.Context, in *SyncConversationsRequest) (*SyncConversationsResponse, error) {
+	out := new(SyncConversationsResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[6], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceProtobufClient) SubmitFeedback(ctx context.Context, in *SubmitFeedbackRequest) (*SubmitFeedbackResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "SubmitFeedback")
+	caller := c.callSubmitFeedback
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *SubmitFeedbackRequest) (*SubmitFeedbackResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*SubmitFeedbackRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*SubmitFeedbackRequest) when calling interceptor")
+					}
+					return c.callSubmitFeedback(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*SubmitFeedbackResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*SubmitFeedbackResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceProtobufClient) callSubmitFeedback(ctx context.Context, in *SubmitFeedbackRequest) (*SubmitFeedbackResponse, error) {
+	out := new(SubmitFeedbackResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[7], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceProtobufClient) RenameConversation(ctx context.Context, in *RenameConversationRequest) (*RenameConversationResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "RenameConversation")
+	caller := c.callRenameConversation
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *RenameConversationRequest) (*RenameConversationResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RenameConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RenameConversationRequest) when calling interceptor")
+					}
+					return c.callRenameConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RenameConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RenameConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceProtobufClient) callRenameConversation(ctx context.Context, in *RenameConversationRequest) (*RenameConversationResponse, error) {
+	out := new(RenameConversationResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[8], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceProtobufClient) RegenerateTitle(ctx context.Context, in *RegenerateTitleRequest) (*RegenerateTitleResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "RegenerateTitle")
+	caller := c.callRegenerateTitle
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *RegenerateTitleRequest) (*RegenerateTitleResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RegenerateTitleRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RegenerateTitleRequest) when calling interceptor")
+					}
+					return c.callRegenerateTitle(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RegenerateTitleResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RegenerateTitleResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceProtobufClient) callRegenerateTitle(ctx context.Context, in *RegenerateTitleRequest) (*RegenerateTitleResponse, error) {
+	out := new(RegenerateTitleResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[9], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
 // =======================
 // ChatService JSON Client
 // =======================
 
 type chatServiceJSONClient struct {
 	client      HTTPClient
-	urls        [4]string
+	urls        [10]string
 	interceptor twirp.Interceptor
 	opts        twirp.ClientOptions
 }
@@ -313,11 +609,17 @@ func NewChatServiceJSONClient(baseURL string, client HTTPClient, opts ...twirp.C
 	// Build method URLs: <baseURL>[<prefix>]/<package>.<Service>/<Method>
 	serviceURL := sanitizeBaseURL(baseURL)
 	serviceURL += baseServicePath(pathPrefix, "acai.chat", "ChatService")
-	urls := [4]string{
+	urls := [10]string{
 		serviceURL + "StartConversation",
 		serviceURL + "ContinueConversation",
 		serviceURL + "ListConversations",
 		serviceURL + "DescribeConversation",
+		serviceURL + "GetMessageStatus",
+		serviceURL + "RetryMessage",
+		serviceURL + "SyncConversations",
+		serviceURL + "SubmitFeedback",
+		serviceURL + "RenameConversation",
+		serviceURL + "RegenerateTitle",
 	}
 
 	return &chatServiceJSONClient{
@@ -512,28 +814,304 @@ func (c *chatServiceJSONClient) callDescribeConversation(ctx context.Context, in
 	return out, nil
 }
 
-// ==========================
-// ChatService Server Handler
-// ==========================
-
-type chatServiceServer struct {
-	ChatService
-	interceptor      twirp.Interceptor
-	hooks            *twirp.ServerHooks
-	pathPrefix       string // prefix for routing
-	jsonSkipDefaults bool   // do not include unpopulated fields (default values) in the response
-	jsonCamelCase    bool   // JSON fields are serialized as lowerCamelCase rather than keeping the original proto names
+func (c *chatServiceJSONClient) GetMessageStatus(ctx context.Context, in *GetMessageStatusRequest) (*GetMessageStatusResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "GetMessageStatus")
+	caller := c.callGetMessageStatus
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *GetMessageStatusRequest) (*GetMessageStatusResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*GetMessageStatusRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*GetMessageStatusRequest) when calling interceptor")
+					}
+					return c.callGetMessageStatus(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*GetMessageStatusResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*GetMessageStatusResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
 }
 
-// NewChatServiceServer builds a TwirpServer that can be used as an http.Handler to handle
-// HTTP requests that are routed to the right method in the provided svc implementation.
-// The opts are twirp.ServerOption modifiers, for example twirp.WithServerHooks(hooks).
-func NewChatServiceServer(svc ChatService, opts ...interface{}) TwirpServer {
-	serverOpts := newServerOpts(opts)
+func (c *chatServiceJSONClient) callGetMessageStatus(ctx context.Context, in *GetMessageStatusRequest) (*GetMessageStatusResponse, error) {
+	out := new(GetMessageStatusResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[4], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
 
-	// Using ReadOpt allows backwards and forwards compatibility with new options in the future
-	jsonSkipDefaults := false
-	_ = serverOpts.ReadOpt("jsonSkipDefaults", &jsonSkipDefaults)
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) RetryMessage(ctx context.Context, in *RetryMessageRequest) (*RetryMessageResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "RetryMessage")
+	caller := c.callRetryMessage
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *RetryMessageRequest) (*RetryMessageResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RetryMessageRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RetryMessageRequest) when calling interceptor")
+					}
+					return c.callRetryMessage(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RetryMessageResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RetryMessageResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callRetryMessage(ctx context.Context, in *RetryMessageRequest) (*RetryMessageResponse, error) {
+	out := new(RetryMessageResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[5], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) SyncConversations(ctx context.Context, in *SyncConversationsRequest) (*SyncConversationsResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "SyncConversations")
+	caller := c.callSyncConversations
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *SyncConversationsRequest) (*SyncConversationsResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*SyncConversationsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*SyncConversationsRequest) when calling interceptor")
+					}
+					return c.callSyncConversations(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*SyncConversationsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*SyncConversationsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callSyncConversations(ctx context.Context, in *SyncConversationsRequest) (*SyncConversationsResponse, error) {
+	out := new(SyncConversationsResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[6], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) SubmitFeedback(ctx context.Context, in *SubmitFeedbackRequest) (*SubmitFeedbackResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "SubmitFeedback")
+	caller := c.callSubmitFeedback
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *SubmitFeedbackRequest) (*SubmitFeedbackResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*SubmitFeedbackRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*SubmitFeedbackRequest) when calling interceptor")
+					}
+					return c.callSubmitFeedback(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*SubmitFeedbackResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*SubmitFeedbackResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callSubmitFeedback(ctx context.Context, in *SubmitFeedbackRequest) (*SubmitFeedbackResponse, error) {
+	out := new(SubmitFeedbackResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[7], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) RenameConversation(ctx context.Context, in *RenameConversationRequest) (*RenameConversationResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "RenameConversation")
+	caller := c.callRenameConversation
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *RenameConversationRequest) (*RenameConversationResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RenameConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RenameConversationRequest) when calling interceptor")
+					}
+					return c.callRenameConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RenameConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RenameConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callRenameConversation(ctx context.Context, in *RenameConversationRequest) (*RenameConversationResponse, error) {
+	out := new(RenameConversationResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[8], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *chatServiceJSONClient) RegenerateTitle(ctx context.Context, in *RegenerateTitleRequest) (*RegenerateTitleResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "acai.chat")
+	ctx = ctxsetters.WithServiceName(ctx, "ChatService")
+	ctx = ctxsetters.WithMethodName(ctx, "RegenerateTitle")
+	caller := c.callRegenerateTitle
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *RegenerateTitleRequest) (*RegenerateTitleResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RegenerateTitleRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RegenerateTitleRequest) when calling interceptor")
+					}
+					return c.callRegenerateTitle(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RegenerateTitleResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RegenerateTitleResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *chatServiceJSONClient) callRegenerateTitle(ctx context.Context, in *RegenerateTitleRequest) (*RegenerateTitleResponse, error) {
+	out := new(RegenerateTitleResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[9], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+// ==========================
+// ChatService Server Handler
+// ==========================
+
+type chatServiceServer struct {
+	ChatService
+	interceptor      twirp.Interceptor
+	hooks            *twirp.ServerHooks
+	pathPrefix       string // prefix for routing
+	jsonSkipDefaults bool   // do not include unpopulated fields (default values) in the response
+	jsonCamelCase    bool   // JSON fields are serialized as lowerCamelCase rather than keeping the original proto names
+}
+
+// NewChatServiceServer builds a TwirpServer that can be used as an http.Handler to handle
+// HTTP requests that are routed to the right method in the provided svc implementation.
+// The opts are twirp.ServerOption modifiers, for example twirp.WithServerHooks(hooks).
+func NewChatServiceServer(svc ChatService, opts ...interface{}) TwirpServer {
+	serverOpts := newServerOpts(opts)
+
+	// Using ReadOpt allows backwards and forwards compatibility with new options in the future
+	jsonSkipDefaults := false
+	_ = serverOpts.ReadOpt("jsonSkipDefaults", &jsonSkipDefaults)
 	jsonCamelCase := false
 	_ = serverOpts.ReadOpt("jsonCamelCase", &jsonCamelCase)
 	var pathPrefix string
@@ -621,6 +1199,24 @@ func (s *chatServiceServer) ServeHTTP(resp http.ResponseWriter, req *http.Reques
 	case "DescribeConversation":
 		s.serveDescribeConversation(ctx, resp, req)
 		return
+	case "GetMessageStatus":
+		s.serveGetMessageStatus(ctx, resp, req)
+		return
+	case "RetryMessage":
+		s.serveRetryMessage(ctx, resp, req)
+		return
+	case "SyncConversations":
+		s.serveSyncConversations(ctx, resp, req)
+		return
+	case "SubmitFeedback":
+		s.serveSubmitFeedback(ctx, resp, req)
+		return
+	case "RenameConversation":
+		s.serveRenameConversation(ctx, resp, req)
+		return
+	case "RegenerateTitle":
+		s.serveRegenerateTitle(ctx, resp, req)
+		return
 	default:
 		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
 		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
@@ -628,7 +1224,1087 @@ func (s *chatServiceServer) ServeHTTP(resp http.ResponseWriter, req *http.Reques
 	}
 }
 
-func (s *chatServiceServer) serveStartConversation(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *chatServiceServer) serveStartConversation(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveStartConversationJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveStartConversationProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveStartConversationJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "StartConversation")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(StartConversationRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.StartConversation
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *StartConversationRequest) (*StartConversationResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*StartConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*StartConversationRequest) when calling interceptor")
+					}
+					return s.ChatService.StartConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*StartConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*StartConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *StartConversationResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *StartConversationResponse and nil error while calling StartConversation. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveStartConversationProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "StartConversation")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(StartConversationRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.StartConversation
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *StartConversationRequest) (*StartConversationResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*StartConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*StartConversationRequest) when calling interceptor")
+					}
+					return s.ChatService.StartConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*StartConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*StartConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *StartConversationResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *StartConversationResponse and nil error while calling StartConversation. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveContinueConversation(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveContinueConversationJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveContinueConversationProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveContinueConversationJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ContinueConversation")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(ContinueConversationRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.ContinueConversation
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ContinueConversationRequest) (*ContinueConversationResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ContinueConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ContinueConversationRequest) when calling interceptor")
+					}
+					return s.ChatService.ContinueConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ContinueConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ContinueConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ContinueConversationResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ContinueConversationResponse and nil error while calling ContinueConversation. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveContinueConversationProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ContinueConversation")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(ContinueConversationRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.ContinueConversation
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ContinueConversationRequest) (*ContinueConversationResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ContinueConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ContinueConversationRequest) when calling interceptor")
+					}
+					return s.ChatService.ContinueConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ContinueConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ContinueConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ContinueConversationResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ContinueConversationResponse and nil error while calling ContinueConversation. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveListConversations(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveListConversationsJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveListConversationsProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveListConversationsJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListConversations")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(ListConversationsRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.ListConversations
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ListConversationsRequest) (*ListConversationsResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListConversationsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListConversationsRequest) when calling interceptor")
+					}
+					return s.ChatService.ListConversations(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListConversationsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListConversationsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ListConversationsResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListConversationsResponse and nil error while calling ListConversations. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveListConversationsProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListConversations")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(ListConversationsRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.ListConversations
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *ListConversationsRequest) (*ListConversationsResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ListConversationsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ListConversationsRequest) when calling interceptor")
+					}
+					return s.ChatService.ListConversations(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ListConversationsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ListConversationsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *ListConversationsResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListConversationsResponse and nil error while calling ListConversations. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveDescribeConversation(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveDescribeConversationJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveDescribeConversationProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveDescribeConversationJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "DescribeConversation")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(DescribeConversationRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.DescribeConversation
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *DescribeConversationRequest) (*DescribeConversationResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DescribeConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DescribeConversationRequest) when calling interceptor")
+					}
+					return s.ChatService.DescribeConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DescribeConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DescribeConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *DescribeConversationResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *DescribeConversationResponse and nil error while calling DescribeConversation. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveDescribeConversationProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "DescribeConversation")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(DescribeConversationRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.DescribeConversation
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *DescribeConversationRequest) (*DescribeConversationResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DescribeConversationRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DescribeConversationRequest) when calling interceptor")
+					}
+					return s.ChatService.DescribeConversation(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DescribeConversationResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DescribeConversationResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *DescribeConversationResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *DescribeConversationResponse and nil error while calling DescribeConversation. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveGetMessageStatus(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveGetMessageStatusJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveGetMessageStatusProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveGetMessageStatusJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "GetMessageStatus")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(GetMessageStatusRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.GetMessageStatus
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *GetMessageStatusRequest) (*GetMessageStatusResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*GetMessageStatusRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*GetMessageStatusRequest) when calling interceptor")
+					}
+					return s.ChatService.GetMessageStatus(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*GetMessageStatusResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*GetMessageStatusResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *GetMessageStatusResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *GetMessageStatusResponse and nil error while calling GetMessageStatus. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveGetMessageStatusProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "GetMessageStatus")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(GetMessageStatusRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.GetMessageStatus
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *GetMessageStatusRequest) (*GetMessageStatusResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*GetMessageStatusRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*GetMessageStatusRequest) when calling interceptor")
+					}
+					return s.ChatService.GetMessageStatus(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*GetMessageStatusResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*GetMessageStatusResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *GetMessageStatusResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *GetMessageStatusResponse and nil error while calling GetMessageStatus. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveRetryMessage(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveRetryMessageJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveRetryMessageProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *chatServiceServer) serveRetryMessageJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "RetryMessage")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(RetryMessageRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.ChatService.RetryMessage
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *RetryMessageRequest) (*RetryMessageResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RetryMessageRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RetryMessageRequest) when calling interceptor")
+					}
+					return s.ChatService.RetryMessage(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RetryMessageResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RetryMessageResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *RetryMessageResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RetryMessageResponse and nil error while calling RetryMessage. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveRetryMessageProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "RetryMessage")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(RetryMessageRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.ChatService.RetryMessage
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *RetryMessageRequest) (*RetryMessageResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*RetryMessageRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*RetryMessageRequest) when calling interceptor")
+					}
+					return s.ChatService.RetryMessage(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*RetryMessageResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*RetryMessageResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *RetryMessageResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RetryMessageResponse and nil error while calling RetryMessage. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *chatServiceServer) serveSyncConversations(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	header := req.Header.Get("Content-Type")
 	i := strings.Index(header, ";")
 	if i == -1 {
@@ -636,9 +2312,9 @@ func (s *chatServiceServer) serveStartConversation(ctx context.Context, resp htt
 	}
 	switch strings.TrimSpace(strings.ToLower(header[:i])) {
 	case "application/json":
-		s.serveStartConversationJSON(ctx, resp, req)
+		s.serveSyncConversationsJSON(ctx, resp, req)
 	case "application/protobuf":
-		s.serveStartConversationProtobuf(ctx, resp, req)
+		s.serveSyncConversationsProtobuf(ctx, resp, req)
 	default:
 		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
 		twerr := badRouteError(msg, req.Method, req.URL.Path)
@@ -646,9 +2322,9 @@ func (s *chatServiceServer) serveStartConversation(ctx context.Context, resp htt
 	}
 }
 
-func (s *chatServiceServer) serveStartConversationJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *chatServiceServer) serveSyncConversationsJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "StartConversation")
+	ctx = ctxsetters.WithMethodName(ctx, "SyncConversations")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -661,29 +2337,29 @@ func (s *chatServiceServer) serveStartConversationJSON(ctx context.Context, resp
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-	reqContent := new(StartConversationRequest)
+	reqContent := new(SyncConversationsRequest)
 	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
 	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
 
-	handler := s.ChatService.StartConversation
+	handler := s.ChatService.SyncConversations
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *StartConversationRequest) (*StartConversationResponse, error) {
+		handler = func(ctx context.Context, req *SyncConversationsRequest) (*SyncConversationsResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*StartConversationRequest)
+					typedReq, ok := req.(*SyncConversationsRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*StartConversationRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*SyncConversationsRequest) when calling interceptor")
 					}
-					return s.ChatService.StartConversation(ctx, typedReq)
+					return s.ChatService.SyncConversations(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*StartConversationResponse)
+				typedResp, ok := resp.(*SyncConversationsResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*StartConversationResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*SyncConversationsResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -692,7 +2368,7 @@ func (s *chatServiceServer) serveStartConversationJSON(ctx context.Context, resp
 	}
 
 	// Call service method
-	var respContent *StartConversationResponse
+	var respContent *SyncConversationsResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -703,7 +2379,7 @@ func (s *chatServiceServer) serveStartConversationJSON(ctx context.Context, resp
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *StartConversationResponse and nil error while calling StartConversation. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *SyncConversationsResponse and nil error while calling SyncConversations. nil responses are not supported"))
 		return
 	}
 
@@ -729,9 +2405,9 @@ func (s *chatServiceServer) serveStartConversationJSON(ctx context.Context, resp
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *chatServiceServer) serveStartConversationProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *chatServiceServer) serveSyncConversationsProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "StartConversation")
+	ctx = ctxsetters.WithMethodName(ctx, "SyncConversations")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -743,28 +2419,28 @@ func (s *chatServiceServer) serveStartConversationProtobuf(ctx context.Context,
 		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
 	}
-	reqContent := new(StartConversationRequest)
+	reqContent := new(SyncConversationsRequest)
 	if err = proto.Unmarshal(buf, reqContent); err != nil {
 		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
 	}
 
-	handler := s.ChatService.StartConversation
+	handler := s.ChatService.SyncConversations
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *StartConversationRequest) (*StartConversationResponse, error) {
+		handler = func(ctx context.Context, req *SyncConversationsRequest) (*SyncConversationsResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*StartConversationRequest)
+					typedReq, ok := req.(*SyncConversationsRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*StartConversationRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*SyncConversationsRequest) when calling interceptor")
 					}
-					return s.ChatService.StartConversation(ctx, typedReq)
+					return s.ChatService.SyncConversations(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*StartConversationResponse)
+				typedResp, ok := resp.(*SyncConversationsResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*StartConversationResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*SyncConversationsResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -773,7 +2449,7 @@ func (s *chatServiceServer) serveStartConversationProtobuf(ctx context.Context,
 	}
 
 	// Call service method
-	var respContent *StartConversationResponse
+	var respContent *SyncConversationsResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -784,7 +2460,7 @@ func (s *chatServiceServer) serveStartConversationProtobuf(ctx context.Context,
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *StartConversationResponse and nil error while calling StartConversation. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *SyncConversationsResponse and nil error while calling SyncConversations. nil responses are not supported"))
 		return
 	}
 
@@ -808,7 +2484,7 @@ func (s *chatServiceServer) serveStartConversationProtobuf(ctx context.Context,
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *chatServiceServer) serveContinueConversation(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *chatServiceServer) serveSubmitFeedback(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	header := req.Header.Get("Content-Type")
 	i := strings.Index(header, ";")
 	if i == -1 {
@@ -816,9 +2492,9 @@ func (s *chatServiceServer) serveContinueConversation(ctx context.Context, resp
 	}
 	switch strings.TrimSpace(strings.ToLower(header[:i])) {
 	case "application/json":
-		s.serveContinueConversationJSON(ctx, resp, req)
+		s.serveSubmitFeedbackJSON(ctx, resp, req)
 	case "application/protobuf":
-		s.serveContinueConversationProtobuf(ctx, resp, req)
+		s.serveSubmitFeedbackProtobuf(ctx, resp, req)
 	default:
 		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
 		twerr := badRouteError(msg, req.Method, req.URL.Path)
@@ -826,9 +2502,9 @@ func (s *chatServiceServer) serveContinueConversation(ctx context.Context, resp
 	}
 }
 
-func (s *chatServiceServer) serveContinueConversationJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *chatServiceServer) serveSubmitFeedbackJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "ContinueConversation")
+	ctx = ctxsetters.WithMethodName(ctx, "SubmitFeedback")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -841,29 +2517,29 @@ func (s *chatServiceServer) serveContinueConversationJSON(ctx context.Context, r
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-	reqContent := new(ContinueConversationRequest)
+	reqContent := new(SubmitFeedbackRequest)
 	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
 	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
 
-	handler := s.ChatService.ContinueConversation
+	handler := s.ChatService.SubmitFeedback
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *ContinueConversationRequest) (*ContinueConversationResponse, error) {
+		handler = func(ctx context.Context, req *SubmitFeedbackRequest) (*SubmitFeedbackResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*ContinueConversationRequest)
+					typedReq, ok := req.(*SubmitFeedbackRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*ContinueConversationRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*SubmitFeedbackRequest) when calling interceptor")
 					}
-					return s.ChatService.ContinueConversation(ctx, typedReq)
+					return s.ChatService.SubmitFeedback(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*ContinueConversationResponse)
+				typedResp, ok := resp.(*SubmitFeedbackResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*ContinueConversationResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*SubmitFeedbackResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -872,7 +2548,7 @@ func (s *chatServiceServer) serveContinueConversationJSON(ctx context.Context, r
 	}
 
 	// Call service method
-	var respContent *ContinueConversationResponse
+	var respContent *SubmitFeedbackResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -883,7 +2559,7 @@ func (s *chatServiceServer) serveContinueConversationJSON(ctx context.Context, r
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *ContinueConversationResponse and nil error while calling ContinueConversation. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *SubmitFeedbackResponse and nil error while calling SubmitFeedback. nil responses are not supported"))
 		return
 	}
 
@@ -909,9 +2585,9 @@ func (s *chatServiceServer) serveContinueConversationJSON(ctx context.Context, r
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *chatServiceServer) serveContinueConversationProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *chatServiceServer) serveSubmitFeedbackProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "ContinueConversation")
+	ctx = ctxsetters.WithMethodName(ctx, "SubmitFeedback")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -923,28 +2599,28 @@ func (s *chatServiceServer) serveContinueConversationProtobuf(ctx context.Contex
 		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
 	}
-	reqContent := new(ContinueConversationRequest)
+	reqContent := new(SubmitFeedbackRequest)
 	if err = proto.Unmarshal(buf, reqContent); err != nil {
 		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
 	}
 
-	handler := s.ChatService.ContinueConversation
+	handler := s.ChatService.SubmitFeedback
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *ContinueConversationRequest) (*ContinueConversationResponse, error) {
+		handler = func(ctx context.Context, req *SubmitFeedbackRequest) (*SubmitFeedbackResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*ContinueConversationRequest)
+					typedReq, ok := req.(*SubmitFeedbackRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*ContinueConversationRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*SubmitFeedbackRequest) when calling interceptor")
 					}
-					return s.ChatService.ContinueConversation(ctx, typedReq)
+					return s.ChatService.SubmitFeedback(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*ContinueConversationResponse)
+				typedResp, ok := resp.(*SubmitFeedbackResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*ContinueConversationResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*SubmitFeedbackResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -953,7 +2629,7 @@ func (s *chatServiceServer) serveContinueConversationProtobuf(ctx context.Contex
 	}
 
 	// Call service method
-	var respContent *ContinueConversationResponse
+	var respContent *SubmitFeedbackResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -964,7 +2640,7 @@ func (s *chatServiceServer) serveContinueConversationProtobuf(ctx context.Contex
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *ContinueConversationResponse and nil error while calling ContinueConversation. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *SubmitFeedbackResponse and nil error while calling SubmitFeedback. nil responses are not supported"))
 		return
 	}
 
@@ -988,7 +2664,7 @@ func (s *chatServiceServer) serveContinueConversationProtobuf(ctx context.Contex
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *chatServiceServer) serveListConversations(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *chatServiceServer) serveRenameConversation(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	header := req.Header.Get("Content-Type")
 	i := strings.Index(header, ";")
 	if i == -1 {
@@ -996,9 +2672,9 @@ func (s *chatServiceServer) serveListConversations(ctx context.Context, resp htt
 	}
 	switch strings.TrimSpace(strings.ToLower(header[:i])) {
 	case "application/json":
-		s.serveListConversationsJSON(ctx, resp, req)
+		s.serveRenameConversationJSON(ctx, resp, req)
 	case "application/protobuf":
-		s.serveListConversationsProtobuf(ctx, resp, req)
+		s.serveRenameConversationProtobuf(ctx, resp, req)
 	default:
 		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
 		twerr := badRouteError(msg, req.Method, req.URL.Path)
@@ -1006,9 +2682,9 @@ func (s *chatServiceServer) serveListConversations(ctx context.Context, resp htt
 	}
 }
 
-func (s *chatServiceServer) serveListConversationsJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *chatServiceServer) serveRenameConversationJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "ListConversations")
+	ctx = ctxsetters.WithMethodName(ctx, "RenameConversation")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -1021,29 +2697,29 @@ func (s *chatServiceServer) serveListConversationsJSON(ctx context.Context, resp
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-	reqContent := new(ListConversationsRequest)
+	reqContent := new(RenameConversationRequest)
 	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
 	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
 
-	handler := s.ChatService.ListConversations
+	handler := s.ChatService.RenameConversation
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *ListConversationsRequest) (*ListConversationsResponse, error) {
+		handler = func(ctx context.Context, req *RenameConversationRequest) (*RenameConversationResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*ListConversationsRequest)
+					typedReq, ok := req.(*RenameConversationRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*ListConversationsRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*RenameConversationRequest) when calling interceptor")
 					}
-					return s.ChatService.ListConversations(ctx, typedReq)
+					return s.ChatService.RenameConversation(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*ListConversationsResponse)
+				typedResp, ok := resp.(*RenameConversationResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*ListConversationsResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*RenameConversationResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -1052,7 +2728,7 @@ func (s *chatServiceServer) serveListConversationsJSON(ctx context.Context, resp
 	}
 
 	// Call service method
-	var respContent *ListConversationsResponse
+	var respContent *RenameConversationResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -1063,7 +2739,7 @@ func (s *chatServiceServer) serveListConversationsJSON(ctx context.Context, resp
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListConversationsResponse and nil error while calling ListConversations. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RenameConversationResponse and nil error while calling RenameConversation. nil responses are not supported"))
 		return
 	}
 
@@ -1089,9 +2765,9 @@ func (s *chatServiceServer) serveListConversationsJSON(ctx context.Context, resp
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *chatServiceServer) serveListConversationsProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *chatServiceServer) serveRenameConversationProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "ListConversations")
+	ctx = ctxsetters.WithMethodName(ctx, "RenameConversation")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -1103,28 +2779,28 @@ func (s *chatServiceServer) serveListConversationsProtobuf(ctx context.Context,
 		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
 	}
-	reqContent := new(ListConversationsRequest)
+	reqContent := new(RenameConversationRequest)
 	if err = proto.Unmarshal(buf, reqContent); err != nil {
 		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
 	}
 
-	handler := s.ChatService.ListConversations
+	handler := s.ChatService.RenameConversation
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *ListConversationsRequest) (*ListConversationsResponse, error) {
+		handler = func(ctx context.Context, req *RenameConversationRequest) (*RenameConversationResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*ListConversationsRequest)
+					typedReq, ok := req.(*RenameConversationRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*ListConversationsRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*RenameConversationRequest) when calling interceptor")
 					}
-					return s.ChatService.ListConversations(ctx, typedReq)
+					return s.ChatService.RenameConversation(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*ListConversationsResponse)
+				typedResp, ok := resp.(*RenameConversationResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*ListConversationsResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*RenameConversationResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -1133,7 +2809,7 @@ func (s *chatServiceServer) serveListConversationsProtobuf(ctx context.Context,
 	}
 
 	// Call service method
-	var respContent *ListConversationsResponse
+	var respContent *RenameConversationResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -1144,7 +2820,7 @@ func (s *chatServiceServer) serveListConversationsProtobuf(ctx context.Context,
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *ListConversationsResponse and nil error while calling ListConversations. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RenameConversationResponse and nil error while calling RenameConversation. nil responses are not supported"))
 		return
 	}
 
@@ -1168,7 +2844,7 @@ func (s *chatServiceServer) serveListConversationsProtobuf(ctx context.Context,
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *chatServiceServer) serveDescribeConversation(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *chatServiceServer) serveRegenerateTitle(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	header := req.Header.Get("Content-Type")
 	i := strings.Index(header, ";")
 	if i == -1 {
@@ -1176,9 +2852,9 @@ func (s *chatServiceServer) serveDescribeConversation(ctx context.Context, resp
 	}
 	switch strings.TrimSpace(strings.ToLower(header[:i])) {
 	case "application/json":
-		s.serveDescribeConversationJSON(ctx, resp, req)
+		s.serveRegenerateTitleJSON(ctx, resp, req)
 	case "application/protobuf":
-		s.serveDescribeConversationProtobuf(ctx, resp, req)
+		s.serveRegenerateTitleProtobuf(ctx, resp, req)
 	default:
 		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
 		twerr := badRouteError(msg, req.Method, req.URL.Path)
@@ -1186,9 +2862,9 @@ func (s *chatServiceServer) serveDescribeConversation(ctx context.Context, resp
 	}
 }
 
-func (s *chatServiceServer) serveDescribeConversationJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *chatServiceServer) serveRegenerateTitleJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "DescribeConversation")
+	ctx = ctxsetters.WithMethodName(ctx, "RegenerateTitle")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -1201,29 +2877,29 @@ func (s *chatServiceServer) serveDescribeConversationJSON(ctx context.Context, r
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-	reqContent := new(DescribeConversationRequest)
+	reqContent := new(RegenerateTitleRequest)
 	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
 	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
 
-	handler := s.ChatService.DescribeConversation
+	handler := s.ChatService.RegenerateTitle
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *DescribeConversationRequest) (*DescribeConversationResponse, error) {
+		handler = func(ctx context.Context, req *RegenerateTitleRequest) (*RegenerateTitleResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*DescribeConversationRequest)
+					typedReq, ok := req.(*RegenerateTitleRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*DescribeConversationRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*RegenerateTitleRequest) when calling interceptor")
 					}
-					return s.ChatService.DescribeConversation(ctx, typedReq)
+					return s.ChatService.RegenerateTitle(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*DescribeConversationResponse)
+				typedResp, ok := resp.(*RegenerateTitleResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*DescribeConversationResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*RegenerateTitleResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -1232,7 +2908,7 @@ func (s *chatServiceServer) serveDescribeConversationJSON(ctx context.Context, r
 	}
 
 	// Call service method
-	var respContent *DescribeConversationResponse
+	var respContent *RegenerateTitleResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -1243,7 +2919,7 @@ func (s *chatServiceServer) serveDescribeConversationJSON(ctx context.Context, r
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *DescribeConversationResponse and nil error while calling DescribeConversation. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RegenerateTitleResponse and nil error while calling RegenerateTitle. nil responses are not supported"))
 		return
 	}
 
@@ -1269,9 +2945,9 @@ func (s *chatServiceServer) serveDescribeConversationJSON(ctx context.Context, r
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *chatServiceServer) serveDescribeConversationProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *chatServiceServer) serveRegenerateTitleProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "DescribeConversation")
+	ctx = ctxsetters.WithMethodName(ctx, "RegenerateTitle")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -1283,28 +2959,28 @@ func (s *chatServiceServer) serveDescribeConversationProtobuf(ctx context.Contex
 		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
 	}
-	reqContent := new(DescribeConversationRequest)
+	reqContent := new(RegenerateTitleRequest)
 	if err = proto.Unmarshal(buf, reqContent); err != nil {
 		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
 	}
 
-	handler := s.ChatService.DescribeConversation
+	handler := s.ChatService.RegenerateTitle
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *DescribeConversationRequest) (*DescribeConversationResponse, error) {
+		handler = func(ctx context.Context, req *RegenerateTitleRequest) (*RegenerateTitleResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*DescribeConversationRequest)
+					typedReq, ok := req.(*RegenerateTitleRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*DescribeConversationRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*RegenerateTitleRequest) when calling interceptor")
 					}
-					return s.ChatService.DescribeConversation(ctx, typedReq)
+					return s.ChatService.RegenerateTitle(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*DescribeConversationResponse)
+				typedResp, ok := resp.(*RegenerateTitleResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*DescribeConversationResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*RegenerateTitleResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -1313,7 +2989,7 @@ func (s *chatServiceServer) serveDescribeConversationProtobuf(ctx context.Contex
 	}
 
 	// Call service method
-	var respContent *DescribeConversationResponse
+	var respContent *RegenerateTitleResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -1324,7 +3000,7 @@ func (s *chatServiceServer) serveDescribeConversationProtobuf(ctx context.Contex
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *DescribeConversationResponse and nil error while calling DescribeConversation. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *RegenerateTitleResponse and nil error while calling RegenerateTitle. nil responses are not supported"))
 		return
 	}
 
@@ -1349,7 +3025,7 @@ func (s *chatServiceServer) serveDescribeConversationProtobuf(ctx context.Contex
 }
 
 func (s *chatServiceServer) ServiceDescriptor() ([]byte, int) {
-	return twirpFileDescriptor0, 0
+	return twirpFileDescriptor1, 0
 }
 
 func (s *chatServiceServer) ProtocGenTwirpVersion() string {
@@ -1363,605 +3039,97 @@ func (s *chatServiceServer) PathPrefix() string {
 	return baseServicePath(s.pathPrefix, "acai.chat", "ChatService")
 }
 
-// =====
-// Utils
-// =====
-
-// HTTPClient is the interface used by generated clients to send HTTP requests.
-// It is fulfilled by *(net/http).Client, which is sufficient for most users.
-// Users can provide their own implementation for special retry policies.
-//
-// HTTPClient implementations should not follow redirects. Redirects are
-// automatically disabled if *(net/http).Client is passed to client
-// constructors. See the withoutRedirects function in this file for more
-// details.
-type HTTPClient interface {
-	Do(req *http.Request) (*http.Response, error)
-}
-
-// TwirpServer is the interface generated server structs will support: they're
-// HTTP handlers with additional methods for accessing metadata about the
-// service. Those accessors are a low-level API for building reflection tools.
-// Most people can think of TwirpServers as just http.Handlers.
-type TwirpServer interface {
-	http.Handler
-
-	// ServiceDescriptor returns gzipped bytes describing the .proto file that
-	// this service was generated from. Once unzipped, the bytes can be
-	// unmarshalled as a
-	// google.golang.org/protobuf/types/descriptorpb.FileDescriptorProto.
-	//
-	// The returned integer is the index of this particular service within that
-	// FileDescriptorProto's 'Service' slice of ServiceDescriptorProtos. This is a
-	// low-level field, expected to be used for reflection.
-	ServiceDescriptor() ([]byte, int)
-
-	// ProtocGenTwirpVersion is the semantic version string of the version of
-	// twirp used to generate this file.
-	ProtocGenTwirpVersion() string
-
-	// PathPrefix returns the HTTP URL path prefix for all methods handled by this
-	// service. This can be used with an HTTP mux to route Twirp requests.
-	// The path prefix is in the form: "/<prefix>/<package>.<Service>/"
-	// that is, everything in a Twirp route except for the <Method> at the end.
-	PathPrefix() string
-}
-
-func newServerOpts(opts []interface{}) *twirp.ServerOptions {
-	serverOpts := &twirp.ServerOptions{}
-	for _, opt := range opts {
-		switch o := opt.(type) {
-		case twirp.ServerOption:
-			o(serverOpts)
-		case *twirp.ServerHooks: // backwards compatibility, allow to specify hooks as an argument
-			twirp.WithServerHooks(o)(serverOpts)
-		case nil: // backwards compatibility, allow nil value for the argument
-			continue
-		default:
-			panic(fmt.Sprintf("Invalid option type %T, please use a twirp.ServerOption", o))
-		}
-	}
-	return serverOpts
-}
-
-// WriteError writes an HTTP response with a valid Twirp error format (code, msg, meta).
-// Useful outside of the Twirp server (e.g. http middleware), but does not trigger hooks.
-// If err is not a twirp.Error, it will get wrapped with twirp.InternalErrorWith(err)
-func WriteError(resp http.ResponseWriter, err error) {
-	writeError(context.Background(), resp, err, nil)
-}
-
-// writeError writes Twirp errors in the response and triggers hooks.
-func writeError(ctx context.Context, resp http.ResponseWriter, err error, hooks *twirp.ServerHooks) {
-	// Convert to a twirp.Error. Non-twirp errors are converted to internal errors.
-	var twerr twirp.Error
-	if !errors.As(err, &twerr) {
-		twerr = twirp.InternalErrorWith(err)
-	}
-
-	statusCode := twirp.ServerHTTPStatusFromErrorCode(twerr.Code())
-	ctx = ctxsetters.WithStatusCode(ctx, statusCode)
-	ctx = callError(ctx, hooks, twerr)
-
-	respBody := marshalErrorToJSON(twerr)
-
-	resp.Header().Set("Content-Type", "application/json") // Error responses are always JSON
-	resp.Header().Set("Content-Length", strconv.Itoa(len(respBody)))
-	resp.WriteHeader(statusCode) // set HTTP status code and send response
-
-	_, writeErr := resp.Write(respBody)
-	if writeErr != nil {
-		// We have three options here. We could log the error, call the Error
-		// hook, or just silently ignore the error.
-		//
-		// Logging is unacceptable because we don't have a user-controlled
-		// logger; writing out to stderr without permission is too rude.
-		//
-		// Calling the Error hook would confuse users: it would mean the Error
-		// hook got called twice for one request, which is likely to lead to
-		// duplicated log messages and metrics, no matter how well we document
-		// the behavior.
-		//
-		// Silently ignoring the error is our least-bad option. It's highly
-		// likely that the connection is broken and the original 'err' says
-		// so anyway.
-		_ = writeErr
-	}
-
-	callResponseSent(ctx, hooks)
-}
-
-// sanitizeBaseURL parses the the baseURL, and adds the "http" scheme if needed.
-// If the URL is unparsable, the baseURL is returned unchanged.
-func sanitizeBaseURL(baseURL string) string {
-	u, err := url.Parse(baseURL)
-	if err != nil {
-		return baseURL // invalid URL will fail later when making requests
-	}
-	if u.Scheme == "" {
-		u.Scheme = "http"
-	}
-	return u.String()
-}
-
-// baseServicePath composes the path prefix for the service (without <Method>).
-// e.g.: baseServicePath("/twirp", "my.pkg", "MyService")
-//
-//	returns => "/twirp/my.pkg.MyService/"
-//
-// e.g.: baseServicePath("", "", "MyService")
-//
-//	returns => "/MyService/"
-func baseServicePath(prefix, pkg, service string) string {
-	fullServiceName := service
-	if pkg != "" {
-		fullServiceName = pkg + "." + service
-	}
-	return path.Join("/", prefix, fullServiceName) + "/"
-}
-
-// parseTwirpPath extracts path components form a valid Twirp route.
-// Expected format: "[<prefix>]/<package>.<Service>/<Method>"
-// e.g.: prefix, pkgService, method := parseTwirpPath("/twirp/pkg.Svc/MakeHat")
-func parseTwirpPath(path string) (string, string, string) {
-	parts := strings.Split(path, "/")
-	if len(parts) < 2 {
-		return "", "", ""
-	}
-	method := parts[len(parts)-1]
-	pkgService := parts[len(parts)-2]
-	prefix := strings.Join(parts[0:len(parts)-2], "/")
-	return prefix, pkgService, method
-}
-
-// getCustomHTTPReqHeaders retrieves a copy of any headers that are set in
-// a context through the twirp.WithHTTPRequestHeaders function.
-// If there are no headers set, or if they have the wrong type, nil is returned.
-func getCustomHTTPReqHeaders(ctx context.Context) http.Header {
-	header, ok := twirp.HTTPRequestHeaders(ctx)
-	if !ok || header == nil {
-		return nil
-	}
-	copied := make(http.Header)
-	for k, vv := range header {
-		if vv == nil {
-			copied[k] = nil
-			continue
-		}
-		copied[k] = make([]string, len(vv))
-		copy(copied[k], vv)
-	}
-	return copied
-}
-
-// newRequest makes an http.Request from a client, adding common headers.
-func newRequest(ctx context.Context, url string, reqBody io.Reader, contentType string) (*http.Request, error) {
-	req, err := http.NewRequest("POST", url, reqBody)
-	if err != nil {
-		return nil, err
-	}
-	req = req.WithContext(ctx)
-	if customHeader := getCustomHTTPReqHeaders(ctx); customHeader != nil {
-		req.Header = customHeader
-	}
-	req.Header.Set("Accept", contentType)
-	req.Header.Set("Content-Type", contentType)
-	req.Header.Set("Twirp-Version", "v8.1.3")
-	return req, nil
-}
-
-// JSON serialization for errors
-type twerrJSON struct {
-	Code string            `json:"code"`
-	Msg  string            `json:"msg"`
-	Meta map[string]string `json:"meta,omitempty"`
-}
-
-// marshalErrorToJSON returns JSON from a twirp.Error, that can be used as HTTP error response body.
-// If serialization fails, it will use a descriptive Internal error instead.
-func marshalErrorToJSON(twerr twirp.Error) []byte {
-	// make sure that msg is not too large
-	msg := twerr.Msg()
-	if len(msg) > 1e6 {
-		msg = msg[:1e6]
-	}
-
-	tj := twerrJSON{
-		Code: string(twerr.Code()),
-		Msg:  msg,
-		Meta: twerr.MetaMap(),
-	}
-
-	buf, err := json.Marshal(&tj)
-	if err != nil {
-		buf = []byte("{\"type\": \"" + twirp.Internal + "\", \"msg\": \"There was an error but it could not be serialized into JSON\"}") // fallback
-	}
-
-	return buf
-}
-
-// errorFromResponse builds a twirp.Error from a non-200 HTTP response.
-// If the response has a valid serialized Twirp error, then it's returned.
-// If not, the response status code is used to generate a similar twirp
-// error. See twirpErrorFromIntermediary for more info on intermediary errors.
-func errorFromResponse(resp *http.Response) twirp.Error {
-	statusCode := resp.StatusCode
-	statusText := http.StatusText(statusCode)
-
-	if isHTTPRedirect(statusCode) {
-		// Unexpected redirect: it must be an error from an intermediary.
-		// Twirp clients don't follow redirects automatically, Twirp only handles
-		// POST requests, redirects should only happen on GET and HEAD requests.
-		location := resp.Header.Get("Location")
-		msg := fmt.Sprintf("unexpected HTTP status code %d %q received, Location=%q", statusCode, statusText, location)
-		return twirpErrorFromIntermediary(statusCode, msg, location)
-	}
-
-	respBodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return wrapInternal(err, "failed to read server error response body")
-	}
-
-	var tj twerrJSON
-	dec := json.NewDecoder(bytes.NewReader(respBodyBytes))
-	dec.DisallowUnknownFields()
-	if err := dec.Decode(&tj); err != nil || tj.Code == "" {
-		// Invalid JSON response; it must be an error from an intermediary.
-		msg := fmt.Sprintf("Error from intermediary with HTTP status code %d %q", statusCode, statusText)
-		return twirpErrorFromIntermediary(statusCode, msg, string(respBodyBytes))
-	}
-
-	errorCode := twirp.ErrorCode(tj.Code)
-	if !twirp.IsValidErrorCode(errorCode) {
-		msg := "invalid type returned from server error response: " + tj.Code
-		return twirp.InternalError(msg).WithMeta("body", string(respBodyBytes))
-	}
-
-	twerr := twirp.NewError(errorCode, tj.Msg)
-	for k, v := range tj.Meta {
-		twerr = twerr.WithMeta(k, v)
-	}
-	return twerr
-}
-
-// twirpErrorFromIntermediary maps HTTP errors from non-twirp sources to twirp errors.
-// The mapping is similar to gRPC: https://github.com/grpc/grpc/blob/master/doc/http-grpc-status-mapping.md.
-// Returned twirp Errors have some additional metadata for inspection.
-func twirpErrorFromIntermediary(status int, msg string, bodyOrLocation string) twirp.Error {
-	var code twirp.ErrorCode
-	if isHTTPRedirect(status) { // 3xx
-		code = twirp.Internal
-	} else {
-		switch status {
-		case 400: // Bad Request
-			code = twirp.Internal
-		case 401: // Unauthorized
-			code = twirp.Unauthenticated
-		case 403: // Forbidden
-			code = twirp.PermissionDenied
-		case 404: // Not Found
-			code = twirp.BadRoute
-		case 429: // Too Many Requests
-			code = twirp.ResourceExhausted
-		case 502, 503, 504: // Bad Gateway, Service Unavailable, Gateway Timeout
-			code = twirp.Unavailable
-		default: // All other codes
-			code = twirp.Unknown
-		}
-	}
-
-	twerr := twirp.NewError(code, msg)
-	twerr = twerr.WithMeta("http_error_from_intermediary", "true") // to easily know if this error was from intermediary
-	twerr = twerr.WithMeta("status_code", strconv.Itoa(status))
-	if isHTTPRedirect(status) {
-		twerr = twerr.WithMeta("location", bodyOrLocation)
-	} else {
-		twerr = twerr.WithMeta("body", bodyOrLocation)
-	}
-	return twerr
-}
-
-func isHTTPRedirect(status int) bool {
-	return status >= 300 && status <= 399
-}
-
-// wrapInternal wraps an error with a prefix as an Internal error.
-// The original error cause is accessible by github.com/pkg/errors.Cause.
-func wrapInternal(err error, prefix string) twirp.Error {
-	return twirp.InternalErrorWith(&wrappedError{prefix: prefix, cause: err})
-}
-
-type wrappedError struct {
-	prefix string
-	cause  error
-}
-
-func (e *wrappedError) Error() string { return e.prefix + ": " + e.cause.Error() }
-func (e *wrappedError) Unwrap() error { return e.cause } // for go1.13 + errors.Is/As
-func (e *wrappedError) Cause() error  { return e.cause } // for github.com/pkg/errors
-
-// ensurePanicResponses makes sure that rpc methods causing a panic still result in a Twirp Internal
-// error response (status 500), and error hooks are properly called with the panic wrapped as an error.
-// The panic is re-raised so it can be handled normally with middleware.
-func ensurePanicResponses(ctx context.Context, resp http.ResponseWriter, hooks *twirp.ServerHooks) {
-	if r := recover(); r != nil {
-		// Wrap the panic as an error so it can be passed to error hooks.
-		// The original error is accessible from error hooks, but not visible in the response.
-		err := errFromPanic(r)
-		twerr := &internalWithCause{msg: "Internal service panic", cause: err}
-		// Actually write the error
-		writeError(ctx, resp, twerr, hooks)
-		// If possible, flush the error to the wire.
-		f, ok := resp.(http.Flusher)
-		if ok {
-			f.Flush()
-		}
-
-		panic(r)
-	}
-}
-
-// errFromPanic returns the typed error if the recovered panic is an error, otherwise formats as error.
-func errFromPanic(p interface{}) error {
-	if err, ok := p.(error); ok {
-		return err
-	}
-	return fmt.Errorf("panic: %v", p)
-}
-
-// internalWithCause is a Twirp Internal error wrapping an original error cause,
-// but the original error message is not exposed on Msg(). The original error
-// can be checked with go1.13+ errors.Is/As, and also by (github.com/pkg/errors).Unwrap
-type internalWithCause struct {
-	msg   string
-	cause error
-}
-
-func (e *internalWithCause) Unwrap() error                               { return e.cause } // for go1.13 + errors.Is/As
-func (e *internalWithCause) Cause() error                                { return e.cause } // for github.com/pkg/errors
-func (e *internalWithCause) Error() string                               { return e.msg + ": " + e.cause.Error() }
-func (e *internalWithCause) Code() twirp.ErrorCode                       { return twirp.Internal }
-func (e *internalWithCause) Msg() string                                 { return e.msg }
-func (e *internalWithCause) Meta(key string) string                      { return "" }
-func (e *internalWithCause) MetaMap() map[string]string                  { return nil }
-func (e *internalWithCause) WithMeta(key string, val string) twirp.Error { return e }
-
-// malformedRequestError is used when the twirp server cannot unmarshal a request
-func malformedRequestError(msg string) twirp.Error {
-	return twirp.NewError(twirp.Malformed, msg)
-}
-
-// badRouteError is used when the twirp server cannot route a request
-func badRouteError(msg string, method, url string) twirp.Error {
-	err := twirp.NewError(twirp.BadRoute, msg)
-	err = err.WithMeta("twirp_invalid_route", method+" "+url)
-	return err
-}
-
-// withoutRedirects makes sure that the POST request can not be redirected.
-// The standard library will, by default, redirect requests (including POSTs) if it gets a 302 or
-// 303 response, and also 301s in go1.8. It redirects by making a second request, changing the
-// method to GET and removing the body. This produces very confusing error messages, so instead we
-// set a redirect policy that always errors. This stops Go from executing the redirect.
-//
-// We have to be a little careful in case the user-provided http.Client has its own CheckRedirect
-// policy - if so, we'll run through that policy first.
-//
-// Because this requires modifying the http.Client, we make a new copy of the client and return it.
-func withoutRedirects(in *http.Client) *http.Client {
-	copy := *in
-	copy.CheckRedirect = func(req *http.Request, via []*http.Request) error {
-		if in.CheckRedirect != nil {
-			// Run the input's redirect if it exists, in case it has side effects, but ignore any error it
-			// returns, since we want to use ErrUseLastResponse.
-			err := in.CheckRedirect(req, via)
-			_ = err // Silly, but this makes sure generated code passes errcheck -blank, which some people use.
-		}
-		return http.ErrUseLastResponse
-	}
-	return &copy
-}
-
-// doProtobufRequest makes a Protobuf request to the remote Twirp service.
-func doProtobufRequest(ctx context.Context, client HTTPClient, hooks *twirp.ClientHooks, url string, in, out proto.Message) (_ context.Context, err error) {
-	reqBodyBytes, err := proto.Marshal(in)
-	if err != nil {
-		return ctx, wrapInternal(err, "failed to marshal proto request")
-	}
-	reqBody := bytes.NewBuffer(reqBodyBytes)
-	if err = ctx.Err(); err != nil {
-		return ctx, wrapInternal(err, "aborted because context was done")
-	}
-
-	req, err := newRequest(ctx, url, reqBody, "application/protobuf")
-	if err != nil {
-		return ctx, wrapInternal(err, "could not build request")
-	}
-	ctx, err = callClientRequestPrepared(ctx, hooks, req)
-	if err != nil {
-		return ctx, err
-	}
-
-	req = req.WithContext(ctx)
-	resp, err := client.Do(req)
-	if err != nil {
-		return ctx, wrapInternal(err, "failed to do request")
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if err = ctx.Err(); err != nil {
-		return ctx, wrapInternal(err, "aborted because context was done")
-	}
-
-	if resp.StatusCode != 200 {
-		return ctx, errorFromResponse(resp)
-	}
-
-	respBodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return ctx, wrapInternal(err, "failed to read response body")
-	}
-	if err = ctx.Err(); err != nil {
-		return ctx, wrapInternal(err, "aborted because context was done")
-	}
-
-	if err = proto.Unmarshal(respBodyBytes, out); err != nil {
-		return ctx, wrapInternal(err, "failed to unmarshal proto response")
-	}
-	return ctx, nil
-}
-
-// doJSONRequest makes a JSON request to the remote Twirp service.
-func doJSONRequest(ctx context.Context, client HTTPClient, hooks *twirp.ClientHooks, url string, in, out proto.Message) (_ context.Context, err error) {
-	marshaler := &protojson.MarshalOptions{UseProtoNames: true}
-	reqBytes, err := marshaler.Marshal(in)
-	if err != nil {
-		return ctx, wrapInternal(err, "failed to marshal json request")
-	}
-	if err = ctx.Err(); err != nil {
-		return ctx, wrapInternal(err, "aborted because context was done")
-	}
-
-	req, err := newRequest(ctx, url, bytes.NewReader(reqBytes), "application/json")
-	if err != nil {
-		return ctx, wrapInternal(err, "could not build request")
-	}
-	ctx, err = callClientRequestPrepared(ctx, hooks, req)
-	if err != nil {
-		return ctx, err
-	}
-
-	req = req.WithContext(ctx)
-	resp, err := client.Do(req)
-	if err != nil {
-		return ctx, wrapInternal(err, "failed to do request")
-	}
-
-	defer func() {
-		cerr := resp.Body.Close()
-		if err == nil && cerr != nil {
-			err = wrapInternal(cerr, "failed to close response body")
-		}
-	}()
-
-	if err = ctx.Err(); err != nil {
-		return ctx, wrapInternal(err, "aborted because context was done")
-	}
-
-	if resp.StatusCode != 200 {
-		return ctx, errorFromResponse(resp)
-	}
-
-	d := json.NewDecoder(resp.Body)
-	rawRespBody := json.RawMessage{}
-	if err := d.Decode(&rawRespBody); err != nil {
-		return ctx, wrapInternal(err, "failed to unmarshal json response")
-	}
-	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
-	if err = unmarshaler.Unmarshal(rawRespBody, out); err != nil {
-		return ctx, wrapInternal(err, "failed to unmarshal json response")
-	}
-	if err = ctx.Err(); err != nil {
-		return ctx, wrapInternal(err, "aborted because context was done")
-	}
-	return ctx, nil
-}
-
-// Call twirp.ServerHooks.RequestReceived if the hook is available
-func callRequestReceived(ctx context.Context, h *twirp.ServerHooks) (context.Context, error) {
-	if h == nil || h.RequestReceived == nil {
-		return ctx, nil
-	}
-	return h.RequestReceived(ctx)
-}
-
-// Call twirp.ServerHooks.RequestRouted if the hook is available
-func callRequestRouted(ctx context.Context, h *twirp.ServerHooks) (context.Context, error) {
-	if h == nil || h.RequestRouted == nil {
-		return ctx, nil
-	}
-	return h.RequestRouted(ctx)
-}
-
-// Call twirp.ServerHooks.ResponsePrepared if the hook is available
-func callResponsePrepared(ctx context.Context, h *twirp.ServerHooks) context.Context {
-	if h == nil || h.ResponsePrepared == nil {
-		return ctx
-	}
-	return h.ResponsePrepared(ctx)
-}
-
-// Call twirp.ServerHooks.ResponseSent if the hook is available
-func callResponseSent(ctx context.Context, h *twirp.ServerHooks) {
-	if h == nil || h.ResponseSent == nil {
-		return
-	}
-	h.ResponseSent(ctx)
-}
-
-// Call twirp.ServerHooks.Error if the hook is available
-func callError(ctx context.Context, h *twirp.ServerHooks, err twirp.Error) context.Context {
-	if h == nil || h.Error == nil {
-		return ctx
-	}
-	return h.Error(ctx, err)
-}
-
-func callClientResponseReceived(ctx context.Context, h *twirp.ClientHooks) {
-	if h == nil || h.ResponseReceived == nil {
-		return
-	}
-	h.ResponseReceived(ctx)
-}
-
-func callClientRequestPrepared(ctx context.Context, h *twirp.ClientHooks, req *http.Request) (context.Context, error) {
-	if h == nil || h.RequestPrepared == nil {
-		return ctx, nil
-	}
-	return h.RequestPrepared(ctx, req)
-}
-
-func callClientError(ctx context.Context, h *twirp.ClientHooks, err twirp.Error) {
-	if h == nil || h.Error == nil {
-		return
-	}
-	h.Error(ctx, err)
-}
-
-var twirpFileDescriptor0 = []byte{
-	// 529 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x94, 0x51, 0x6b, 0xd3, 0x50,
-	0x14, 0xc7, 0x4d, 0xda, 0xd9, 0xe5, 0x64, 0xad, 0xdd, 0xa5, 0x60, 0x96, 0x15, 0x56, 0xa2, 0xb8,
-	0x3e, 0x48, 0x2a, 0x75, 0x0f, 0xc2, 0xf0, 0x61, 0x56, 0x85, 0xa1, 0x56, 0x48, 0x3a, 0x84, 0x09,
-	0xd3, 0x34, 0xbb, 0x66, 0x17, 0xd2, 0xdc, 0x98, 0x7b, 0x3b, 0xf0, 0xd3, 0xec, 0x83, 0xfa, 0x22,
-	0x4d, 0x6e, 0xe3, 0xbd, 0x34, 0xa9, 0x8a, 0x8f, 0xe7, 0xe4, 0x7f, 0xcf, 0xf9, 0xfd, 0xcf, 0x39,
-	0x04, 0x3a, 0x59, 0x1a, 0x8e, 0xc2, 0x9b, 0x80, 0xbb, 0x69, 0x46, 0x39, 0x45, 0x46, 0x10, 0x06,
-	0xc4, 0x5d, 0x25, 0xec, 0xa3, 0x88, 0xd2, 0x28, 0xc6, 0xa3, 0xfc, 0xc3, 0x7c, 0xf9, 0x6d, 0xc4,
-	0xc9, 0x02, 0x33, 0x1e, 0x2c, 0xd2, 0x42, 0xeb, 0xfc, 0xd4, 0x61, 0x6f, 0x42, 0x93, 0x5b, 0x9c,
-	0xb1, 0x80, 0x13, 0x9a, 0xa0, 0x0e, 0xe8, 0xe4, 0xda, 0xd2, 0x06, 0xda, 0xd0, 0xf0, 0x74, 0x72,
-	0x8d, 0x7a, 0xb0, 0xc3, 0x09, 0x8f, 0xb1, 0xa5, 0xe7, 0xa9, 0x22, 0x40, 0x2f, 0xc0, 0x28, 0x2b,
-	0x59, 0x8d, 0x81, 0x36, 0x34, 0xc7, 0xb6, 0x5b, 0xf4, 0x72, 0xd7, 0xbd, 0xdc, 0xd9, 0x5a, 0xe1,
-	0xfd, 0x16, 0xa3, 0x53, 0xd8, 0x5d, 0x60, 0xc6, 0x82, 0x08, 0x33, 0xab, 0x39, 0x68, 0x0c, 0xcd,
-	0xf1, 0x91, 0x5b, 0xf2, 0xba, 0x32, 0x8a, 0xfb, 0xa1, 0xd0, 0x79, 0xe5, 0x03, 0xfb, 0x4e, 0x83,
-	0x96, 0xc8, 0x6e, 0x80, 0x3e, 0x83, 0x66, 0x46, 0x05, 0x67, 0x67, 0xdc, 0xaf, 0x2b, 0xea, 0xd1,
-	0x18, 0x7b, 0xb9, 0x12, 0x59, 0xd0, 0x0a, 0x69, 0xc2, 0x71, 0xc2, 0x73, 0x0b, 0x86, 0xb7, 0x0e,
-	0x55, 0x7b, 0xcd, 0x7f, 0xb0, 0xe7, 0x3c, 0x85, 0xe6, 0xaa, 0x03, 0x32, 0xa1, 0x75, 0x31, 0x7d,
-	0x37, 0xfd, 0xf8, 0x69, 0xda, 0xbd, 0x87, 0x76, 0xa1, 0x79, 0xe1, 0xbf, 0xf1, 0xba, 0x1a, 0x6a,
-	0x83, 0x71, 0xe6, 0xfb, 0xe7, 0xfe, 0xec, 0x6c, 0x3a, 0xeb, 0xea, 0xce, 0x09, 0x58, 0x3e, 0x0f,
-	0x32, 0x2e, 0x13, 0x7a, 0xf8, 0xfb, 0x12, 0x33, 0xbe, 0xa2, 0x13, 0xbe, 0x85, 0xc9, 0x75, 0xe8,
-	0xa4, 0x70, 0x50, 0xf1, 0x8a, 0xa5, 0x34, 0x61, 0x18, 0x1d, 0xc3, 0x83, 0x50, 0xca, 0x7f, 0x29,
-	0x67, 0xd4, 0x91, 0xd3, 0xe7, 0x75, 0x8b, 0xed, 0xc1, 0x4e, 0x86, 0xd3, 0xf8, 0x87, 0x98, 0x48,
-	0x11, 0x38, 0x5f, 0xe1, 0x70, 0x42, 0x13, 0x4e, 0x92, 0x25, 0xae, 0x42, 0xfd, 0xeb, 0x9e, 0x92,
-	0x27, 0x5d, 0xf5, 0x74, 0x02, 0xfd, 0xea, 0x0e, 0xc2, 0x56, 0xc9, 0xa5, 0xc9, 0x5c, 0x36, 0x58,
-	0xef, 0x09, 0x53, 0x06, 0xc1, 0x04, 0x94, 0x73, 0x09, 0x07, 0x15, 0xdf, 0x44, 0xb9, 0x97, 0xd0,
-	0x96, 0xd1, 0x98, 0xa5, 0xe5, 0xa7, 0xf8, 0xb0, 0xe6, 0x6a, 0x3c, 0x55, 0xed, 0xbc, 0x85, 0xc3,
-	0xd7, 0x98, 0x85, 0x19, 0x99, 0xff, 0xd7, 0x3c, 0x9c, 0xcf, 0xd0, 0xaf, 0xae, 0x23, 0x30, 0x4f,
-	0x61, 0x4f, 0x7e, 0x91, 0x57, 0xd9, 0x42, 0xa9, 0x88, 0xc7, 0x77, 0x0d, 0x30, 0x27, 0x37, 0x01,
-	0xf7, 0x71, 0x76, 0x4b, 0x42, 0x8c, 0xae, 0x60, 0x7f, 0xe3, 0x6c, 0xd0, 0x23, 0xa9, 0x56, 0xdd,
-	0x29, 0xda, 0x8f, 0xb7, 0x8b, 0x04, 0x6c, 0x04, 0xbd, 0xaa, 0x15, 0xa2, 0x27, 0x2a, 0x6e, 0xdd,
-	0x15, 0xd9, 0xc7, 0x7f, 0xd4, 0x89, 0x46, 0x57, 0xb0, 0xbf, 0xb1, 0x59, 0xc5, 0x48, 0xdd, 0x4d,
-	0x28, 0x46, 0xea, 0x8f, 0x23, 0x82, 0x5e, 0xd5, 0x56, 0x14, 0x23, 0x5b, 0xd6, 0xaf, 0x18, 0xd9,
-	0xb6, 0xde, 0x57, 0xed, 0x4b, 0x93, 0x24, 0x1c, 0x67, 0x49, 0x10, 0x8f, 0xd2, 0xf9, 0xfc, 0x7e,
-	0xfe, 0x6b, 0x79, 0xfe, 0x2b, 0x00, 0x00, 0xff, 0xff, 0x59, 0x83, 0x17, 0xf4, 0xd0, 0x05, 0x00,
-	0x00,
+var twirpFileDescriptor1 = []byte{
+	// 1455 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x57, 0xcd, 0x6e, 0xdb, 0x46,
+	0x10, 0x0e, 0xf5, 0xaf, 0x91, 0x23, 0xd3, 0x5b, 0xd7, 0x61, 0x18, 0xb7, 0x76, 0x18, 0xa7, 0x31,
+	0x8a, 0x40, 0x2e, 0x54, 0x20, 0x48, 0x11, 0xf4, 0x20, 0xcb, 0x94, 0x2d, 0x44, 0x16, 0x93, 0x95,
+	0xd4, 0x14, 0x09, 0x1a, 0x81, 0x96, 0x36, 0x32, 0x11, 0x89, 0x54, 0xc9, 0x55, 0x10, 0xbf, 0x41,
+	0x5f, 0xa4, 0xc7, 0xde, 0x7b, 0xed, 0xa1, 0xcf, 0xd0, 0x73, 0x2f, 0x45, 0x7b, 0xeb, 0x23, 0x14,
+	0xfb, 0x23, 0x9b, 0xb4, 0x28, 0x39, 0x8e, 0x0d, 0xf4, 0x22, 0x60, 0x86, 0xdf, 0xce, 0xce, 0xcf,
+	0xce, 0xcc, 0x27, 0x28, 0xfa, 0xe3, 0xde, 0x4e, 0xef, 0xd8, 0xa6, 0xa5, 0xb1, 0xef, 0x51, 0x0f,
+	0xe5, 0xed, 0x9e, 0xed, 0x94, 0x98, 0x42, 0xdf, 0x18, 0x78, 0xde, 0x60, 0x48, 0x76, 0xf8, 0x87,
+	0xa3, 0xc9, 0x9b, 0x1d, 0xea, 0x8c, 0x48, 0x40, 0xed, 0xd1, 0x58, 0x60, 0x8d, 0xbf, 0x52, 0xb0,
+	0x54, 0xf5, 0xdc, 0x77, 0xc4, 0x0f, 0x6c, 0xea, 0x78, 0x2e, 0x2a, 0x42, 0xc2, 0xe9, 0x6b, 0xca,
+	0xa6, 0xb2, 0x9d, 0xc7, 0x09, 0xa7, 0x8f, 0x56, 0x21, 0x4d, 0x1d, 0x3a, 0x24, 0x5a, 0x82, 0xab,
+	0x84, 0x80, 0x1e, 0x43, 0xfe, 0xd4, 0x92, 0x96, 0xdc, 0x54, 0xb6, 0x0b, 0x65, 0xbd, 0x24, 0xee,
+	0x2a, 0x4d, 0xef, 0x2a, 0xb5, 0xa7, 0x08, 0x7c, 0x06, 0x46, 0x4f, 0x20, 0x37, 0x22, 0x41, 0x60,
+	0x0f, 0x48, 0xa0, 0xa5, 0x36, 0x93, 0xdb, 0x85, 0xf2, 0x46, 0xe9, 0xd4, 0xdf, 0x52, 0xd8, 0x95,
+	0xd2, 0xa1, 0xc0, 0xe1, 0xd3, 0x03, 0x48, 0x83, 0xec, 0x98, 0xf8, 0x81, 0xe7, 0xda, 0x5a, 0x9a,
+	0xbb, 0x33, 0x15, 0xd9, 0x17, 0x9f, 0xb8, 0xf6, 0x88, 0xf4, 0xb5, 0xcc, 0xa6, 0xb2, 0x9d, 0xc3,
+	0x53, 0x51, 0xff, 0x39, 0x01, 0x59, 0x69, 0x69, 0x26, 0xb8, 0xaf, 0x20, 0xe5, 0x7b, 0x32, 0xb6,
+	0x62, 0x79, 0x7d, 0x9e, 0x23, 0xd8, 0x1b, 0x12, 0xcc, 0x91, 0xec, 0x9e, 0x9e, 0xe7, 0x52, 0xe2,
+	0x52, 0x1e, 0x76, 0x1e, 0x4f, 0xc5, 0x68, 0x4a, 0x52, 0x97, 0x49, 0xc9, 0x23, 0xc8, 0x04, 0xd4,
+	0xa6, 0x93, 0x80, 0x07, 0x55, 0x2c, 0x7f, 0x3e, 0xcf, 0x8f, 0x16, 0x47, 0x61, 0x89, 0x66, 0xa5,
+	0x21, 0xbe, 0xef, 0xf9, 0x3c, 0xe2, 0x3c, 0x16, 0x02, 0xd3, 0x52, 0xcf, 0x1b, 0x06, 0x5a, 0x76,
+	0x33, 0xc9, 0xb4, 0x5c, 0x40, 0x9b, 0x50, 0x08, 0x26, 0x83, 0x01, 0x09, 0x98, 0xa1, 0x40, 0xcb,
+	0xf1, 0x6f, 0x61, 0x95, 0xf1, 0x10, 0x52, 0x2c, 0x4e, 0x54, 0x80, 0x6c, 0xa7, 0xf9, 0xb4, 0x69,
+	0xbd, 0x68, 0xaa, 0x37, 0x50, 0x0e, 0x52, 0x9d, 0x96, 0x89, 0x55, 0x05, 0xdd, 0x84, 0x7c, 0xa5,
+	0xd5, 0xaa, 0xb7, 0xda, 0x95, 0x66, 0x5b, 0x4d, 0x18, 0x3b, 0x90, 0x11, 0xde, 0xa0, 0x25, 0xc8,
+	0x55, 0xad, 0xc3, 0x67, 0x0d, 0xb3, 0x6d, 0xaa, 0x37, 0xd8, 0xe9, 0x67, 0x66, 0x73, 0xaf, 0xde,
+	0xdc, 0x57, 0x15, 0x04, 0x90, 0xa9, 0x55, 0xea, 0x0d, 0x73, 0x4f, 0x4d, 0x18, 0xbf, 0x26, 0x60,
+	0x65, 0x9f, 0xb8, 0xc4, 0xe7, 0xa1, 0x58, 0x63, 0xf6, 0xcb, 0x43, 0x18, 0x79, 0x7d, 0x32, 0x94,
+	0x35, 0x11, 0x02, 0xba, 0x0f, 0x05, 0x4a, 0x46, 0x63, 0x86, 0x9d, 0xf8, 0xa2, 0x3a, 0xca, 0xc1,
+	0x0d, 0x1c, 0x56, 0xfe, 0xa4, 0x28, 0xe8, 0x4b, 0x58, 0x19, 0xd9, 0xef, 0xbb, 0xde, 0x84, 0x8e,
+	0x27, 0xb4, 0x4b, 0xbd, 0xb7, 0xc4, 0x0d, 0x78, 0x55, 0xd2, 0x78, 0x79, 0x64, 0xbf, 0xb7, 0xb8,
+	0xbe, 0xcd, 0xd5, 0xe8, 0x05, 0xa8, 0x3e, 0xb1, 0x03, 0xcf, 0x75, 0xdc, 0x41, 0x97, 0xbc, 0x79,
+	0xe3, 0xf9, 0x94, 0x17, 0xa9, 0x58, 0x7e, 0x18, 0xca, 0xf6, 0x8c, 0x83, 0x25, 0x3c, 0x3d, 0x64,
+	0xf2, 0x33, 0x78, 0xd9, 0x8f, 0x2a, 0x8c, 0xe7, 0xb0, 0x7c, 0x0e, 0x83, 0x10, 0x14, 0xf7, 0xcc,
+	0x5a, 0xa5, 0xd3, 0x68, 0x77, 0xcd, 0x5a, 0xcd, 0xc2, 0x6d, 0x91, 0x97, 0xc3, 0x7a, 0xb3, 0x7e,
+	0x58, 0x69, 0xa8, 0x0a, 0xca, 0x42, 0xb2, 0x61, 0xbd, 0x50, 0x13, 0x2c, 0x41, 0x87, 0xe6, 0x5e,
+	0xbd, 0x73, 0xa8, 0x26, 0x59, 0xaa, 0x0f, 0xea, 0xfb, 0x07, 0x6a, 0x6a, 0xb7, 0x08, 0x4b, 0xdd,
+	0x50, 0xa8, 0xc6, 0xef, 0x0a, 0x68, 0x2d, 0x6a, 0xfb, 0x34, 0xfc, 0x18, 0x30, 0xf9, 0x71, 0x42,
+	0x02, 0xca, 0x1e, 0xa4, 0x6c, 0x0f, 0x99, 0xc3, 0xa9, 0x88, 0x1e, 0xc0, 0xb2, 0xd3, 0x27, 0xa3,
+	0xb1, 0x47, 0x89, 0xdb, 0x3b, 0xe9, 0xbe, 0x25, 0x27, 0xb2, 0x87, 0x8b, 0x21, 0xf5, 0x53, 0x72,
+	0xc2, 0x8a, 0x60, 0x07, 0x27, 0x6e, 0x8f, 0xe7, 0x2e, 0x87, 0x85, 0x10, 0xee, 0xb5, 0x54, 0xb4,
+	0xd7, 0x1e, 0x41, 0xd6, 0x13, 0xe9, 0xe1, 0x0f, 0xb6, 0x50, 0x5e, 0x5f, 0x94, 0x42, 0x3c, 0x05,
+	0x1b, 0xff, 0x28, 0x70, 0x3b, 0x26, 0x8e, 0x60, 0xec, 0xb9, 0x01, 0x77, 0xb7, 0x17, 0xd2, 0x77,
+	0x4f, 0x1b, 0xb5, 0x18, 0x56, 0xd7, 0xe7, 0x4d, 0xa4, 0x55, 0x48, 0xfb, 0x64, 0x3c, 0x3c, 0x91,
+	0x6d, 0x29, 0x04, 0xf4, 0x19, 0x80, 0x4c, 0x07, 0xb3, 0x27, 0xe2, 0xc8, 0x4b, 0x4d, 0xbd, 0xff,
+	0xd1, 0x9d, 0x77, 0xae, 0x9b, 0x32, 0xb3, 0xdd, 0xf4, 0x87, 0x02, 0x77, 0xaa, 0x9e, 0x4b, 0x1d,
+	0x77, 0x42, 0xe2, 0xca, 0xf6, 0xc1, 0xd1, 0x86, 0xea, 0x9b, 0xb8, 0xb0, 0xbe, 0xc9, 0xc5, 0xf5,
+	0x4d, 0x85, 0xeb, 0xfb, 0xb1, 0x55, 0xfc, 0x45, 0x81, 0xf5, 0xf8, 0xc8, 0x64, 0x21, 0x4f, 0x2b,
+	0xa1, 0xcc, 0xaf, 0x44, 0x62, 0x7e, 0x25, 0x92, 0x57, 0xa9, 0x44, 0x6a, 0xb6, 0x12, 0x3a, 0x68,
+	0x0d, 0x27, 0x88, 0xbc, 0xb9, 0x40, 0x56, 0xc1, 0x78, 0x09, 0xb7, 0x63, 0xbe, 0xc9, 0x38, 0xbe,
+	0x85, 0x9b, 0xe1, 0x5a, 0x04, 0x9a, 0xc2, 0xd7, 0xd5, 0xad, 0x39, 0x9e, 0xe1, 0x28, 0xda, 0xa8,
+	0xc1, 0x9d, 0x3d, 0x12, 0xf4, 0x7c, 0xe7, 0xe8, 0x4a, 0x0f, 0xc0, 0x78, 0x05, 0xeb, 0xf1, 0x76,
+	0xa4, 0x9b, 0x4f, 0x60, 0x29, 0x7c, 0x82, 0x5b, 0x59, 0xe0, 0x65, 0x04, 0x6c, 0xd8, 0x70, 0x6b,
+	0x9f, 0x50, 0xb9, 0x1e, 0x65, 0x6a, 0x2f, 0xfb, 0x42, 0x17, 0x57, 0xd6, 0xe8, 0x80, 0x36, 0x7b,
+	0x85, 0xf4, 0xfd, 0x9b, 0xe8, 0xf0, 0xfa, 0x00, 0x2e, 0x30, 0xc5, 0x1b, 0x3f, 0xc0, 0x27, 0x98,
+	0x50, 0xff, 0x64, 0xfa, 0xe1, 0x9a, 0xbd, 0x7e, 0x0e, 0xab, 0x51, 0xf3, 0x57, 0xf7, 0xf8, 0x00,
+	0xb4, 0xd6, 0x89, 0xdb, 0x8b, 0x7b, 0x88, 0x68, 0x0d, 0x32, 0xbd, 0x89, 0x1f, 0x78, 0xbe, 0xf4,
+	0x56, 0x4a, 0xac, 0x97, 0x86, 0xce, 0xc8, 0xa1, 0xdc, 0xc1, 0x34, 0x16, 0x82, 0xf1, 0x37, 0x1b,
+	0xa4, 0xb3, 0xa6, 0xae, 0xe5, 0xdd, 0xa2, 0xc7, 0xa0, 0xf5, 0xc9, 0x90, 0x50, 0xd2, 0xef, 0x9e,
+	0xcb, 0x64, 0xa0, 0x25, 0x78, 0x7b, 0xad, 0xc9, 0xef, 0xd5, 0x48, 0x46, 0x03, 0xb4, 0x01, 0x05,
+	0x97, 0xbc, 0xa7, 0x5d, 0x19, 0x89, 0x18, 0x46, 0xc0, 0x54, 0x55, 0x11, 0xcd, 0x6d, 0xc8, 0x1d,
+	0xdb, 0x41, 0x77, 0xe4, 0xf9, 0x44, 0xce, 0xa2, 0xec, 0xb1, 0x1d, 0x1c, 0x7a, 0x3e, 0x61, 0x09,
+	0xf0, 0x09, 0x1f, 0x52, 0x69, 0xfe, 0x41, 0x4a, 0xc6, 0xbf, 0x49, 0xc8, 0xd5, 0x08, 0xe9, 0x1f,
+	0xd9, 0xbd, 0xb7, 0xd7, 0x55, 0x5c, 0x54, 0x86, 0x0c, 0x1b, 0x6e, 0xee, 0x40, 0x0e, 0x1b, 0x3d,
+	0x94, 0x9a, 0xe9, 0x65, 0x25, 0xcc, 0x11, 0x58, 0x22, 0xf9, 0x19, 0xbe, 0xe7, 0xb5, 0xd4, 0x82,
+	0x33, 0x1c, 0x81, 0x25, 0x52, 0x90, 0xc5, 0xd1, 0x88, 0x91, 0xc5, 0xf4, 0x94, 0x2c, 0x72, 0xf1,
+	0x8c, 0xa4, 0x65, 0xc2, 0x24, 0x4d, 0x87, 0x1c, 0x7f, 0x0e, 0xac, 0x8d, 0xb3, 0xfc, 0xc0, 0xa9,
+	0x7c, 0x36, 0x55, 0x73, 0xe1, 0xa9, 0x1a, 0x21, 0x9d, 0xf9, 0x4b, 0x90, 0x4e, 0x63, 0x1b, 0x32,
+	0x22, 0x42, 0x41, 0xf8, 0x70, 0xa5, 0x6d, 0xee, 0x09, 0xc2, 0xb7, 0x6f, 0x59, 0x7b, 0x82, 0xa4,
+	0xec, 0x56, 0x18, 0x73, 0x73, 0x21, 0x23, 0xe2, 0x62, 0x1c, 0xb0, 0x69, 0x75, 0xb1, 0x59, 0x69,
+	0x59, 0x8c, 0x1c, 0x16, 0x01, 0xea, 0xcd, 0x4a, 0xb5, 0xda, 0x61, 0x87, 0x05, 0x45, 0xec, 0x34,
+	0x0f, 0xcc, 0xc6, 0xb3, 0x5a, 0xa7, 0xa1, 0x26, 0xc4, 0xe7, 0x53, 0x6a, 0x98, 0x44, 0xcb, 0x50,
+	0x68, 0x5b, 0x56, 0xf7, 0x3b, 0x13, 0xef, 0x5a, 0x2d, 0x53, 0x4d, 0x31, 0xf6, 0xd3, 0x69, 0xb6,
+	0x2a, 0x35, 0x53, 0x4d, 0xa3, 0x3c, 0xa4, 0xad, 0xf6, 0x81, 0x89, 0xd5, 0x8c, 0xf1, 0xa7, 0x02,
+	0x9f, 0xb6, 0x26, 0x47, 0x23, 0x87, 0x4e, 0xf3, 0x7a, 0xcd, 0xcd, 0xfd, 0xff, 0xd7, 0xdf, 0xd0,
+	0x60, 0xed, 0x7c, 0x88, 0xa2, 0x7b, 0xd9, 0x4a, 0xc2, 0xfc, 0x9f, 0xcb, 0x95, 0x58, 0x43, 0x2c,
+	0x47, 0x32, 0xd6, 0x41, 0x8f, 0xb3, 0x2d, 0x6f, 0xae, 0xc0, 0x1a, 0x26, 0x03, 0xb1, 0xf8, 0x49,
+	0x9b, 0x1d, 0xb8, 0xf4, 0xae, 0xda, 0x81, 0x5b, 0x33, 0x26, 0xce, 0x58, 0x81, 0xf0, 0x48, 0x09,
+	0x79, 0x54, 0xfe, 0x2d, 0x0b, 0x85, 0xea, 0xb1, 0x4d, 0x5b, 0xc4, 0x7f, 0xe7, 0xf4, 0x08, 0x7a,
+	0x0d, 0x2b, 0x33, 0x0c, 0x11, 0xdd, 0x0b, 0xa5, 0x7a, 0x1e, 0x0f, 0xd6, 0xb7, 0x16, 0x83, 0xa4,
+	0x17, 0x03, 0x58, 0x8d, 0xe3, 0x2e, 0xe8, 0x8b, 0xe8, 0x70, 0x9c, 0x47, 0xdb, 0xf4, 0x07, 0x17,
+	0xe2, 0xe4, 0x45, 0xaf, 0x61, 0x65, 0x86, 0x59, 0x44, 0x02, 0x99, 0xc7, 0x49, 0xf4, 0xad, 0xc5,
+	0xa0, 0xb3, 0x40, 0xe2, 0x58, 0x41, 0x24, 0x90, 0x05, 0xf4, 0x43, 0x7f, 0x70, 0x21, 0x4e, 0x5e,
+	0xf4, 0x0a, 0xd4, 0xf3, 0xeb, 0x1b, 0x19, 0x11, 0xa6, 0x18, 0x4b, 0x1f, 0xf4, 0x7b, 0x0b, 0x31,
+	0xd2, 0xb8, 0x05, 0x4b, 0xe1, 0x2d, 0x8b, 0xc2, 0xac, 0x2f, 0x66, 0xbb, 0xeb, 0x1b, 0x73, 0xbf,
+	0x9f, 0xa5, 0x7d, 0x66, 0x31, 0x46, 0xdf, 0xcf, 0x9c, 0x0d, 0xac, 0x6f, 0x2d, 0x06, 0x49, 0xfb,
+	0x1d, 0x28, 0x46, 0xfb, 0x16, 0x6d, 0x86, 0xcf, 0xc5, 0x4d, 0x2d, 0xfd, 0xee, 0x02, 0x84, 0x34,
+	0x6b, 0x03, 0x9a, 0x6d, 0x4c, 0xb4, 0x15, 0x89, 0x76, 0xce, 0x4c, 0xd0, 0xef, 0x5f, 0x80, 0x92,
+	0x57, 0x7c, 0x0f, 0xcb, 0xe7, 0x5a, 0x13, 0xdd, 0x8d, 0x9c, 0x8c, 0xeb, 0x7c, 0xdd, 0x58, 0x04,
+	0x11, 0x96, 0x77, 0x6f, 0xbe, 0x2c, 0x38, 0x2e, 0x25, 0xbe, 0x6b, 0x0f, 0x77, 0xc6, 0x47, 0x47,
+	0x19, 0xbe, 0x77, 0xbe, 0xfe, 0x6f, 0x00, 0x77, 0xdc, 0xcc, 0x57, 0x96, 0x12, 0x00, 0x00,
 }
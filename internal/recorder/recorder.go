@@ -0,0 +1,237 @@
+// Package recorder captures outgoing HTTP interactions (OpenAI, WeatherAPI,
+// Finnhub, ICS feeds...) into cassette files and replays them later, so that
+// code depending on third-party APIs can be tested deterministically and
+// without credentials.
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Mode selects whether a Recorder talks to the real upstream or to a cassette.
+type Mode int
+
+const (
+	// ModeReplay serves responses from the cassette and never hits the network.
+	ModeReplay Mode = iota
+	// ModeRecord forwards requests upstream and saves the interactions on Stop.
+	ModeRecord
+)
+
+const redacted = "REDACTED"
+
+// Headers and query parameters that carry credentials. They are never written
+// to cassettes, and are ignored when matching requests during replay.
+var (
+	secretHeaders = []string{"Authorization", "Api-Key", "X-Api-Key", "Cookie", "Set-Cookie"}
+	secretParams  = []string{"key", "token", "api_key", "apikey", "access_token"}
+)
+
+// Cassette is the on-disk format of recorded interactions.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type Response struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body"`
+}
+
+// Recorder is an http.RoundTripper recording to or replaying from a cassette.
+//
+// Requests are matched by method and URL (with credentials redacted). When the
+// same endpoint is called several times, as the OpenAI chat completions
+// endpoint is during a tool loop, recorded interactions are served in order.
+// Request bodies are stored for readability but not compared, since they often
+// contain timestamps or other non-deterministic data.
+type Recorder struct {
+	path string
+	mode Mode
+	next http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// New creates a recorder backed by the cassette at path. In replay mode the
+// cassette must exist; in record mode it is overwritten when Stop is called.
+func New(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{path: path, mode: mode, next: http.DefaultTransport}
+
+	if mode == ModeReplay {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cassette: %w", err)
+		}
+		if err := json.Unmarshal(b, &r.cassette); err != nil {
+			return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+
+	return r, nil
+}
+
+// Client returns an HTTP client using the recorder as transport.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Stop saves the recorded interactions. It is a no-op in replay mode.
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, b, 0o644)
+}
+
+// Unused returns how many recorded interactions have not been replayed yet.
+func (r *Recorder) Unused() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	for _, u := range r.used {
+		if !u {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		_ = req.Body.Close()
+		body = b
+		req.Body = io.NopCloser(bytes.NewReader(b))
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req)
+	}
+
+	res, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, &Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    redactURL(req.URL),
+			Header: redactHeader(req.Header),
+			Body:   string(body),
+		},
+		Response: Response{
+			Status: res.StatusCode,
+			Header: redactHeader(res.Header),
+			Body:   string(resBody),
+		},
+	})
+	r.mu.Unlock()
+
+	res.Body = io.NopCloser(bytes.NewReader(resBody))
+	return res, nil
+}
+
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u := redactURL(req.URL)
+	for i, in := range r.cassette.Interactions {
+		if r.used[i] || in.Request.Method != req.Method || in.Request.URL != u {
+			continue
+		}
+		r.used[i] = true
+
+		header := in.Response.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		header.Del("Content-Length")
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.Status, http.StatusText(in.Response.Status)),
+			StatusCode:    in.Response.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(strings.NewReader(in.Response.Body)),
+			ContentLength: int64(len(in.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, errors.New("recorder: no recorded interaction left for " + req.Method + " " + u)
+}
+
+func redactURL(u *url.URL) string {
+	c := *u
+	q := c.Query()
+	for _, p := range secretParams {
+		if q.Has(p) {
+			q.Set(p, redacted)
+		}
+	}
+	c.RawQuery = q.Encode()
+	return c.String()
+}
+
+func redactHeader(h http.Header) http.Header {
+	c := h.Clone()
+	for _, k := range secretHeaders {
+		if c.Get(k) != "" {
+			c.Set(k, redacted)
+		}
+	}
+	return c
+}
@@ -0,0 +1,67 @@
+package recorder
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecorder_RecordThenReplay(t *testing.T) {
+	calls := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_, _ = io.WriteString(w, "response "+r.URL.Query().Get("q"))
+	}))
+	defer upstream.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	rec, err := New(path, ModeRecord)
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	for _, q := range []string{"a", "b"} {
+		req, _ := http.NewRequest(http.MethodGet, upstream.URL+"/search?q="+q+"&token=secret", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		res, err := rec.Client().Do(req)
+		if err != nil {
+			t.Fatalf("record request error: %v", err)
+		}
+		_ = res.Body.Close()
+	}
+	if err := rec.Stop(); err != nil {
+		t.Fatalf("Stop error: %v", err)
+	}
+
+	b, _ := os.ReadFile(path)
+	if strings.Contains(string(b), "secret") {
+		t.Fatalf("cassette leaks credentials:\n%s", b)
+	}
+
+	rep, err := New(path, ModeReplay)
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	res, err := rep.Client().Get(upstream.URL + "/search?q=b&token=other")
+	if err != nil {
+		t.Fatalf("replay request error: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	if got, want := string(body), "response b"; got != want {
+		t.Fatalf("body: got %q, want %q", got, want)
+	}
+	if calls != 2 {
+		t.Fatalf("replay should not hit upstream, got %d calls", calls)
+	}
+	if got, want := rep.Unused(), 1; got != want {
+		t.Fatalf("unused: got %d, want %d", got, want)
+	}
+
+	if _, err := rep.Client().Get(upstream.URL + "/search?q=b&token=other"); err == nil {
+		t.Fatal("expected error once the interaction has been replayed")
+	}
+}
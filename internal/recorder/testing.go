@@ -0,0 +1,37 @@
+package recorder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// ForTest returns a recorder for the cassette testdata/cassettes/<name>.json.
+// Cassettes are replayed unless RECORD_CASSETTES=1 is set, in which case real
+// requests are made (credentials must be in the environment) and the cassette
+// is rewritten when the test ends. When replaying, the test fails if some of
+// the recorded interactions were never requested.
+func ForTest(t *testing.T, name string) *Recorder {
+	t.Helper()
+
+	mode := ModeReplay
+	if os.Getenv("RECORD_CASSETTES") == "1" {
+		mode = ModeRecord
+	}
+
+	r, err := New(filepath.Join("testdata", "cassettes", name+".json"), mode)
+	if err != nil {
+		t.Fatalf("failed to create recorder: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := r.Stop(); err != nil {
+			t.Errorf("failed to save cassette: %v", err)
+		}
+		if n := r.Unused(); n > 0 {
+			t.Errorf("cassette %s: %d recorded interactions were not replayed", name, n)
+		}
+	})
+
+	return r
+}
@@ -2,11 +2,24 @@ package telemetry
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
 	"os"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	otelprom "go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
@@ -15,36 +28,213 @@ import (
 
 type Shutdown func(context.Context) error
 
-// Init configures OpenTelemetry with simple stdout exporters for both traces and metrics.
-// For production you'd swap stdout exporters with OTLP (Prometheus/Tempo/etc.),
-func Init(ctx context.Context) (Shutdown, error) {
-	res, _ := resource.New(ctx,
-		resource.WithAttributes(semconv.ServiceName("acai-chat")),
-	)
-	// traces -> stdout
-	tExp, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+// Exporter names accepted in Config.
+const (
+	ExporterNone       = "none"
+	ExporterStdout     = "stdout"
+	ExporterOTLP       = "otlp"
+	ExporterPrometheus = "prometheus" // metrics only
+)
+
+// OTLP protocols accepted in Config.
+const (
+	ProtocolGRPC = "grpc"
+	ProtocolHTTP = "http/protobuf"
+)
+
+// Samplers accepted in Config, as named by OTEL_TRACES_SAMPLER.
+const (
+	SamplerAlwaysOn                = "always_on"
+	SamplerAlwaysOff               = "always_off"
+	SamplerTraceIDRatio            = "traceidratio"
+	SamplerParentBasedAlwaysOn     = "parentbased_always_on"
+	SamplerParentBasedAlwaysOff    = "parentbased_always_off"
+	SamplerParentBasedTraceIDRatio = "parentbased_traceidratio"
+)
+
+// Config selects where traces and metrics are exported. OTLP exporters also
+// honor the standard OTEL_EXPORTER_OTLP_* variables (headers, TLS, timeouts...).
+type Config struct {
+	ServiceName    string
+	ServiceVersion string
+	Environment    string
+
+	TracesExporter  string
+	MetricsExporter string
+
+	// OTLPProtocol is either grpc or http/protobuf.
+	OTLPProtocol string
+	// OTLPEndpoint overrides the collector URL, e.g. http://localhost:4318.
+	OTLPEndpoint string
+
+	// Sampler decides which traces are sampled, parentbased_traceidratio when
+	// empty: spans follow their parent's decision, and SampleRatio of new
+	// traces are sampled.
+	Sampler string
+	// SampleRatio is the fraction of traces sampled by the traceidratio
+	// samplers, between 0 and 1.
+	SampleRatio float64
+}
+
+// Telemetry holds the configured providers.
+type Telemetry struct {
+	// Shutdown flushes pending telemetry and stops the exporters.
+	Shutdown Shutdown
+	// MetricsHandler serves metrics for Prometheus to scrape. It is nil unless
+	// the prometheus metrics exporter is selected.
+	MetricsHandler http.Handler
+}
+
+// Init configures OpenTelemetry tracing and metrics according to cfg and
+// installs the providers globally.
+func Init(ctx context.Context, cfg Config) (*Telemetry, error) {
+	attrs := []attribute.KeyValue{semconv.ServiceName(cfg.ServiceName)}
+	if cfg.ServiceVersion != "" {
+		attrs = append(attrs, semconv.ServiceVersion(cfg.ServiceVersion))
+	}
+	if cfg.Environment != "" {
+		attrs = append(attrs, semconv.DeploymentEnvironment(cfg.Environment))
+	}
+
+	res, err := resource.New(ctx, resource.WithFromEnv(), resource.WithAttributes(attrs...))
 	if err != nil {
 		return nil, err
 	}
-	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(tExp),
-		sdktrace.WithResource(res),
-	)
-	otel.SetTracerProvider(tp)
 
-	// metrics -> stdout
-	mExp, err := stdoutmetric.New(stdoutmetric.WithWriter(os.Stdout),
-		stdoutmetric.WithPrettyPrint(),
-	)
+	// traces
+	sampler, err := newSampler(cfg)
+	if err != nil {
+		return nil, err
+	}
+	tOpts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sampler),
+	}
+	tExp, err := newTraceExporter(ctx, cfg)
 	if err != nil {
 		return nil, err
 	}
-	mp := metric.NewMeterProvider(metric.WithReader(
-		metric.NewPeriodicReader(mExp)), metric.WithResource(res))
+	if tExp != nil {
+		tOpts = append(tOpts, sdktrace.WithBatcher(tExp))
+	}
+	tp := sdktrace.NewTracerProvider(tOpts...)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	// metrics
+	t := &Telemetry{}
+	mOpts := []metric.Option{metric.WithResource(res)}
+	switch cfg.MetricsExporter {
+	case ExporterNone:
+	case ExporterPrometheus:
+		reg := prometheus.NewRegistry()
+		exp, err := otelprom.New(otelprom.WithRegisterer(reg))
+		if err != nil {
+			_ = tp.Shutdown(ctx)
+			return nil, err
+		}
+		mOpts = append(mOpts, metric.WithReader(exp))
+		t.MetricsHandler = promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
+	default:
+		exp, err := newMetricExporter(ctx, cfg)
+		if err != nil {
+			_ = tp.Shutdown(ctx)
+			return nil, err
+		}
+		mOpts = append(mOpts, metric.WithReader(metric.NewPeriodicReader(exp)))
+	}
+	mp := metric.NewMeterProvider(mOpts...)
 	otel.SetMeterProvider(mp)
 
-	return func(ctx context.Context) error {
-		_ = mp.Shutdown(ctx)
-		return tp.Shutdown(ctx)
-	}, nil
+	t.Shutdown = func(ctx context.Context) error {
+		return errors.Join(mp.Shutdown(ctx), tp.Shutdown(ctx))
+	}
+
+	return t, nil
+}
+
+func newSampler(cfg Config) (sdktrace.Sampler, error) {
+	switch cfg.Sampler {
+	case SamplerAlwaysOn:
+		return sdktrace.AlwaysSample(), nil
+	case SamplerAlwaysOff:
+		return sdktrace.NeverSample(), nil
+	case SamplerTraceIDRatio:
+		return sdktrace.TraceIDRatioBased(cfg.SampleRatio), nil
+	case SamplerParentBasedAlwaysOn:
+		return sdktrace.ParentBased(sdktrace.AlwaysSample()), nil
+	case SamplerParentBasedAlwaysOff:
+		return sdktrace.ParentBased(sdktrace.NeverSample()), nil
+	case SamplerParentBasedTraceIDRatio, "":
+		return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio)), nil
+	}
+	return nil, fmt.Errorf("unknown traces sampler %q", cfg.Sampler)
+}
+
+// signalURL returns the URL of the OTLP/HTTP endpoint of a signal, e.g.
+// v1/traces, under the collector URL.
+func signalURL(endpoint, signal string) (string, error) {
+	u, err := url.JoinPath(endpoint, signal)
+	if err != nil {
+		return "", fmt.Errorf("invalid OTLP endpoint %q: %w", endpoint, err)
+	}
+	return u, nil
+}
+
+func newTraceExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, error) {
+	switch cfg.TracesExporter {
+	case ExporterNone:
+		return nil, nil
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterOTLP:
+		switch cfg.OTLPProtocol {
+		case ProtocolGRPC:
+			var opts []otlptracegrpc.Option
+			if cfg.OTLPEndpoint != "" {
+				opts = append(opts, otlptracegrpc.WithEndpointURL(cfg.OTLPEndpoint))
+			}
+			return otlptracegrpc.New(ctx, opts...)
+		case ProtocolHTTP:
+			var opts []otlptracehttp.Option
+			if cfg.OTLPEndpoint != "" {
+				u, err := signalURL(cfg.OTLPEndpoint, "v1/traces")
+				if err != nil {
+					return nil, err
+				}
+				opts = append(opts, otlptracehttp.WithEndpointURL(u))
+			}
+			return otlptracehttp.New(ctx, opts...)
+		}
+		return nil, fmt.Errorf("unknown OTLP protocol %q", cfg.OTLPProtocol)
+	}
+	return nil, fmt.Errorf("unknown traces exporter %q", cfg.TracesExporter)
+}
+
+func newMetricExporter(ctx context.Context, cfg Config) (metric.Exporter, error) {
+	switch cfg.MetricsExporter {
+	case ExporterStdout:
+		return stdoutmetric.New(stdoutmetric.WithWriter(os.Stdout), stdoutmetric.WithPrettyPrint())
+	case ExporterOTLP:
+		switch cfg.OTLPProtocol {
+		case ProtocolGRPC:
+			var opts []otlpmetricgrpc.Option
+			if cfg.OTLPEndpoint != "" {
+				opts = append(opts, otlpmetricgrpc.WithEndpointURL(cfg.OTLPEndpoint))
+			}
+			return otlpmetricgrpc.New(ctx, opts...)
+		case ProtocolHTTP:
+			var opts []otlpmetrichttp.Option
+			if cfg.OTLPEndpoint != "" {
+				u, err := signalURL(cfg.OTLPEndpoint, "v1/metrics")
+				if err != nil {
+					return nil, err
+				}
+				opts = append(opts, otlpmetrichttp.WithEndpointURL(u))
+			}
+			return otlpmetrichttp.New(ctx, opts...)
+		}
+		return nil, fmt.Errorf("unknown OTLP protocol %q", cfg.OTLPProtocol)
+	}
+	return nil, fmt.Errorf("unknown metrics exporter %q", cfg.MetricsExporter)
 }
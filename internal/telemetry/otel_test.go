@@ -0,0 +1,178 @@
+package telemetry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/acai-travel/tech-challenge/internal/pb"
+	"github.com/twitchtv/twirp"
+	"go.opentelemetry.io/otel"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// collector is a minimal stand-in for an OTLP/HTTP collector.
+type collector struct {
+	mu     sync.Mutex
+	paths  []string
+	traces []*coltracepb.ExportTraceServiceRequest
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b, _ := io.ReadAll(r.Body)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.paths = append(c.paths, r.URL.Path)
+
+	if r.URL.Path == "/v1/traces" {
+		var req coltracepb.ExportTraceServiceRequest
+		if err := proto.Unmarshal(b, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		c.traces = append(c.traces, &req)
+	}
+
+	w.Header().Set("Content-Type", "application/x-protobuf")
+}
+
+func TestInit_OTLPHTTP(t *testing.T) {
+	ctx := context.Background()
+	col := &collector{}
+	srv := httptest.NewServer(col)
+	defer srv.Close()
+
+	tel, err := Init(ctx, Config{
+		ServiceName:     "acai-chat-test",
+		ServiceVersion:  "1.2.3",
+		Environment:     "test",
+		TracesExporter:  ExporterOTLP,
+		MetricsExporter: ExporterOTLP,
+		OTLPProtocol:    ProtocolHTTP,
+		OTLPEndpoint:    srv.URL + "/", // joined with the signal paths
+		Sampler:         SamplerParentBasedAlwaysOn,
+	})
+	if err != nil {
+		t.Fatalf("Init error: %v", err)
+	}
+
+	_, span := otel.Tracer("test").Start(ctx, "test-span")
+	span.End()
+
+	counter, _ := otel.Meter("test").Int64Counter("test.counter")
+	counter.Add(ctx, 1)
+
+	if err := tel.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown error: %v", err)
+	}
+
+	col.mu.Lock()
+	defer col.mu.Unlock()
+
+	if !slices.Contains(col.paths, "/v1/traces") || !slices.Contains(col.paths, "/v1/metrics") {
+		t.Fatalf("expected traces and metrics exports, got %q", strings.Join(col.paths, ","))
+	}
+
+	attrs := map[string]string{}
+	for _, kv := range col.traces[0].GetResourceSpans()[0].GetResource().GetAttributes() {
+		attrs[kv.GetKey()] = kv.GetValue().GetStringValue()
+	}
+	for k, want := range map[string]string{
+		"service.name":           "acai-chat-test",
+		"service.version":        "1.2.3",
+		"deployment.environment": "test",
+	} {
+		if attrs[k] != want {
+			t.Errorf("resource attribute %s: got %q, want %q", k, attrs[k], want)
+		}
+	}
+
+	spans := col.traces[0].GetResourceSpans()[0].GetScopeSpans()[0].GetSpans()
+	if len(spans) != 1 || spans[0].GetName() != "test-span" {
+		t.Fatalf("unexpected spans: %v", spans)
+	}
+}
+
+func TestInit_Prometheus(t *testing.T) {
+	ctx := context.Background()
+
+	tel, err := Init(ctx, Config{
+		ServiceName:     "acai-chat-test",
+		TracesExporter:  ExporterNone,
+		MetricsExporter: ExporterPrometheus,
+		SampleRatio:     0,
+	})
+	if err != nil {
+		t.Fatalf("Init error: %v", err)
+	}
+	defer tel.Shutdown(ctx)
+
+	counter, _ := otel.Meter("test").Int64Counter("test.requests")
+	counter.Add(ctx, 3)
+
+	rec := httptest.NewRecorder()
+	tel.MetricsHandler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if body := rec.Body.String(); !strings.Contains(body, "test_requests_total") {
+		t.Fatalf("expected counter in /metrics output, got:\n%s", body)
+	}
+}
+
+// chatStub implements the two RPCs exercised by TestTwirpHooks.
+type chatStub struct{ pb.ChatService }
+
+func (chatStub) ListConversations(context.Context, *pb.ListConversationsRequest) (*pb.ListConversationsResponse, error) {
+	return &pb.ListConversationsResponse{}, nil
+}
+
+func (chatStub) DescribeConversation(context.Context, *pb.DescribeConversationRequest) (*pb.DescribeConversationResponse, error) {
+	return nil, twirp.NotFoundError("conversation not found")
+}
+
+func TestTwirpHooks(t *testing.T) {
+	ctx := context.Background()
+
+	tel, err := Init(ctx, Config{
+		ServiceName:     "acai-chat-test",
+		TracesExporter:  ExporterNone,
+		MetricsExporter: ExporterPrometheus,
+	})
+	if err != nil {
+		t.Fatalf("Init error: %v", err)
+	}
+	defer tel.Shutdown(ctx)
+
+	srv := httptest.NewServer(pb.NewChatServiceServer(chatStub{}, twirp.WithServerHooks(TwirpHooks())))
+	defer srv.Close()
+
+	client := pb.NewChatServiceJSONClient(srv.URL, srv.Client())
+	if _, err := client.ListConversations(ctx, &pb.ListConversationsRequest{}); err != nil {
+		t.Fatalf("ListConversations error: %v", err)
+	}
+	if _, err := client.DescribeConversation(ctx, &pb.DescribeConversationRequest{}); err == nil {
+		t.Fatal("expected DescribeConversation to fail")
+	}
+
+	rec := httptest.NewRecorder()
+	tel.MetricsHandler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		`rpc_method="ListConversations"`,
+		`rpc_method="DescribeConversation"`,
+		`rpc_twirp_error_code="not_found"`,
+		`rpc_twirp_error_code="ok"`,
+		"rpc_server_duration_seconds_bucket",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %s in /metrics output, got:\n%s", want, body)
+		}
+	}
+}
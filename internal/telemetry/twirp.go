@@ -0,0 +1,89 @@
+package telemetry
+
+import (
+	"context"
+	"time"
+
+	"github.com/twitchtv/twirp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/acai-travel/tech-challenge/internal/telemetry"
+
+type rpcKey struct{}
+
+// rpcState is carried in the request context from the first hook to the last.
+type rpcState struct {
+	start time.Time
+	code  twirp.ErrorCode
+}
+
+// TwirpHooks records the number, latency and error codes of Twirp requests per
+// RPC method, and annotates the HTTP server span with the method and error.
+// Instruments are created from the global meter provider, so call it after Init.
+func TwirpHooks() *twirp.ServerHooks {
+	meter := otel.Meter(instrumentationName)
+	rpcRequests, _ := meter.Int64Counter("rpc.server.requests",
+		metric.WithDescription("Twirp requests handled, by method and error code"),
+		metric.WithUnit("{request}"))
+	rpcDuration, _ := meter.Float64Histogram("rpc.server.duration",
+		metric.WithDescription("Duration of Twirp requests"),
+		metric.WithUnit("s"))
+
+	return &twirp.ServerHooks{
+		RequestReceived: func(ctx context.Context) (context.Context, error) {
+			return context.WithValue(ctx, rpcKey{}, &rpcState{start: time.Now()}), nil
+		},
+		RequestRouted: func(ctx context.Context) (context.Context, error) {
+			trace.SpanFromContext(ctx).SetAttributes(rpcAttributes(ctx)...)
+			return ctx, nil
+		},
+		Error: func(ctx context.Context, err twirp.Error) context.Context {
+			if s, ok := ctx.Value(rpcKey{}).(*rpcState); ok {
+				s.code = err.Code()
+			}
+
+			span := trace.SpanFromContext(ctx)
+			span.SetAttributes(attribute.String("rpc.twirp.error_code", string(err.Code())))
+			if isServerError(err.Code()) {
+				span.SetStatus(codes.Error, err.Msg())
+			}
+			return ctx
+		},
+		ResponseSent: func(ctx context.Context) {
+			s, ok := ctx.Value(rpcKey{}).(*rpcState)
+			if !ok {
+				return
+			}
+
+			code := "ok"
+			if s.code != twirp.NoError {
+				code = string(s.code)
+			}
+			attrs := append(rpcAttributes(ctx), attribute.String("rpc.twirp.error_code", code))
+
+			rpcRequests.Add(ctx, 1, metric.WithAttributes(attrs...))
+			rpcDuration.Record(ctx, time.Since(s.start).Seconds(), metric.WithAttributes(attrs...))
+		},
+	}
+}
+
+func rpcAttributes(ctx context.Context) []attribute.KeyValue {
+	service, _ := twirp.ServiceName(ctx)
+	method, _ := twirp.MethodName(ctx)
+	return []attribute.KeyValue{
+		attribute.String("rpc.system", "twirp"),
+		attribute.String("rpc.service", service),
+		attribute.String("rpc.method", method),
+	}
+}
+
+// isServerError reports whether a Twirp error code denotes a failure of the
+// server rather than of the request, i.e. one that maps to a 5xx status.
+func isServerError(code twirp.ErrorCode) bool {
+	return twirp.ServerHTTPStatusFromErrorCode(code) >= 500
+}
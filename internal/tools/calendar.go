@@ -3,10 +3,9 @@ package tools
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log/slog"
-	"os"
+	"net/http"
 	"strings"
 	"time"
 
@@ -16,7 +15,12 @@ import (
 
 // CalendarTool provides functionality to retrieve local bank and public holidays
 // from an ICS calendar feed. It supports filtering by date ranges and limiting results.
-type CalendarTool struct{}
+type CalendarTool struct {
+	// URL of the ICS feed, the Catalonia holidays when empty.
+	URL string
+	// HTTPClient is used to download the feed, a traced default client when nil.
+	HTTPClient *http.Client
+}
 
 func (CalendarTool) Name() string { return "get_holidays" }
 func (CalendarTool) Schema() openai.FunctionDefinitionParam {
@@ -43,15 +47,19 @@ func (CalendarTool) Schema() openai.FunctionDefinitionParam {
 	}
 }
 
-func (CalendarTool) Handle(ctx context.Context, args json.RawMessage) (string, error) {
-	link := "https://www.officeholidays.com/ics/spain/catalonia"
-	if v := os.Getenv("HOLIDAY_CALENDAR_LINK"); v != "" {
-		link = v
-	}
+func (t CalendarTool) link() string {
+	return orDefault(t.URL, "https://www.officeholidays.com/ics/spain/catalonia")
+}
+
+// Ping checks that the calendar feed is reachable.
+func (t CalendarTool) Ping(ctx context.Context) error {
+	return ping(ctx, clientOrDefault(t.HTTPClient), t.link())
+}
 
-	events, err := loadCalendar(ctx, link)
+func (t CalendarTool) Handle(ctx context.Context, args json.RawMessage) (string, error) {
+	events, err := loadCalendar(ctx, clientOrDefault(t.HTTPClient), t.link())
 	if err != nil {
-		return "", errors.New("failed to load holiday events")
+		return "", Upstream("failed to load holiday events")
 
 	}
 	var p struct {
@@ -60,7 +68,7 @@ func (CalendarTool) Handle(ctx context.Context, args json.RawMessage) (string, e
 		MaxCount   int       `json:"max_count,omitempty"`
 	}
 	if err := ParseArgs(args, &p); err != nil {
-		return "", errors.New("failed to parse tool call arguments: " + err.Error())
+		return "", BadArgs("failed to parse tool call arguments: " + err.Error())
 	}
 
 	var holidays []string
@@ -87,10 +95,10 @@ func (CalendarTool) Handle(ctx context.Context, args json.RawMessage) (string, e
 	return strings.Join(holidays, "\n"), nil
 }
 
-func loadCalendar(ctx context.Context, link string) ([]*ics.VEvent, error) {
+func loadCalendar(ctx context.Context, client *http.Client, link string) ([]*ics.VEvent, error) {
 	slog.InfoContext(ctx, "Loading calendar", "link", link)
 
-	cal, err := ics.ParseCalendarFromUrl(link, ctx)
+	cal, err := ics.ParseCalendarFromUrl(link, ctx, client)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse calendar: %w", err)
 	}
@@ -0,0 +1,26 @@
+package tools
+
+import "errors"
+
+// Error kinds returned by tools, used to classify failures in metrics. Tools
+// wrap them with BadArgs and Upstream so that the message shown to the model
+// stays human readable.
+var (
+	ErrUnknownTool = errors.New("unknown tool")
+	ErrBadArgs     = errors.New("bad arguments")
+	ErrUpstream    = errors.New("upstream error")
+)
+
+type toolError struct {
+	kind error
+	msg  string
+}
+
+func (e *toolError) Error() string { return e.msg }
+func (e *toolError) Unwrap() error { return e.kind }
+
+// BadArgs reports that the model called a tool with invalid arguments.
+func BadArgs(msg string) error { return &toolError{kind: ErrBadArgs, msg: msg} }
+
+// Upstream reports that the service backing a tool failed.
+func Upstream(msg string) error { return &toolError{kind: ErrUpstream, msg: msg} }
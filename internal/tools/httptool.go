@@ -0,0 +1,345 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/tidwall/gjson"
+	"gopkg.in/yaml.v3"
+)
+
+// HTTPToolSpec declares a tool that wraps a single REST call, so that simple
+// integrations can be added through configuration instead of Go code.
+//
+// URL, Body and Headers are Go templates executed with the tool call arguments,
+// e.g. "https://api.example.com/quote?symbol={{.symbol}}". Values are escaped
+// for where they are inserted: path-escaped in the path of the URL,
+// query-escaped in its query and escaped as JSON string content in the body,
+// unless already encoded with {{json .arg}}. References to environment
+// variables in the form ${NAME} are resolved once at load time, which is how
+// secrets are kept out of the file. Response is a gjson path
+// (https://github.com/tidwall/gjson) selecting the part of the JSON response
+// returned to the model; when empty the raw body is returned.
+type HTTPToolSpec struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Parameters  map[string]any    `json:"parameters,omitempty"`
+	Method      string            `json:"method,omitempty"`
+	URL         string            `json:"url"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Body        string            `json:"body,omitempty"`
+	Response    string            `json:"response,omitempty"`
+}
+
+// maxHTTPToolResponse caps how much of an upstream response is read.
+const maxHTTPToolResponse = 1 << 20
+
+var (
+	envRef        = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+	validToolName = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+)
+
+// LoadHTTPTools reads a JSON file of the form {"tools": [...]}, or its YAML
+// equivalent when named *.yaml or *.yml, and builds the declared tools. Any
+// invalid definition or missing secret fails the whole load.
+func LoadHTTPTools(path string) ([]Tool, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HTTP tools file: %w", err)
+	}
+
+	// YAML is converted to JSON, so that both are read with the same field names.
+	if ext := filepath.Ext(path); ext == ".yaml" || ext == ".yml" {
+		var doc any
+		if err := yaml.Unmarshal(b, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse HTTP tools file: %w", err)
+		}
+		if b, err = json.Marshal(doc); err != nil {
+			return nil, fmt.Errorf("failed to parse HTTP tools file: %w", err)
+		}
+	}
+
+	var file struct {
+		Tools []HTTPToolSpec `json:"tools"`
+	}
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse HTTP tools file: %w", err)
+	}
+
+	out := make([]Tool, 0, len(file.Tools))
+	for _, spec := range file.Tools {
+		t, err := NewHTTPTool(spec)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+
+	return out, nil
+}
+
+// HTTPTool is a Tool built from an HTTPToolSpec.
+type HTTPTool struct {
+	// HTTPClient is used for upstream calls, a traced default client when nil.
+	HTTPClient *http.Client
+
+	spec    HTTPToolSpec
+	path    *template.Template
+	query   *template.Template
+	body    *template.Template
+	headers map[string]*template.Template
+}
+
+// NewHTTPTool validates the spec, resolves its secrets and parses its templates.
+func NewHTTPTool(spec HTTPToolSpec) (*HTTPTool, error) {
+	if !validToolName.MatchString(spec.Name) {
+		return nil, fmt.Errorf("http tool %q: name must match %s", spec.Name, validToolName)
+	}
+	if spec.URL == "" {
+		return nil, fmt.Errorf("http tool %q: url is required", spec.Name)
+	}
+	if spec.Method == "" {
+		spec.Method = http.MethodGet
+	}
+	spec.Method = strings.ToUpper(spec.Method)
+	if spec.Parameters == nil {
+		spec.Parameters = map[string]any{"type": "object", "properties": map[string]any{}}
+	}
+
+	t := &HTTPTool{spec: spec, headers: make(map[string]*template.Template, len(spec.Headers))}
+
+	texts := []string{spec.URL, spec.Body}
+	for _, v := range spec.Headers {
+		texts = append(texts, v)
+	}
+	secrets, err := lookupSecrets(texts...)
+	if err != nil {
+		return nil, fmt.Errorf("http tool %q: %w", spec.Name, err)
+	}
+
+	parse := func(field, text string, escape func(string) string) (*template.Template, error) {
+		// Secrets are inserted at execution, so that their value is never parsed.
+		text = envRef.ReplaceAllString(text, `{{secret "$1"}}`)
+		tmpl, err := template.New(field).Option("missingkey=zero").Funcs(template.FuncMap{
+			"json":   toJSON,
+			"secret": func(name string) string { return secrets[name] },
+			"escape": func(v any) string {
+				if v == nil {
+					return ""
+				}
+				return escape(fmt.Sprint(v))
+			},
+		}).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("http tool %q: %s: %w", spec.Name, field, err)
+		}
+		for _, tt := range tmpl.Templates() {
+			autoescape(tt.Tree)
+		}
+		return tmpl, nil
+	}
+
+	path, query, hasQuery := strings.Cut(spec.URL, "?")
+	if t.path, err = parse("url", path, url.PathEscape); err != nil {
+		return nil, err
+	}
+	if hasQuery {
+		if t.query, err = parse("url", query, url.QueryEscape); err != nil {
+			return nil, err
+		}
+	}
+	if t.body, err = parse("body", spec.Body, jsonStringEscape); err != nil {
+		return nil, err
+	}
+	for k, v := range spec.Headers {
+		if t.headers[k], err = parse("header "+k, v, strings.Clone); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+// lookupSecrets resolves the ${NAME} references of texts, failing on variables
+// that are not set so that a missing secret is reported at startup rather than
+// on the first call.
+func lookupSecrets(texts ...string) (map[string]string, error) {
+	secrets := map[string]string{}
+	var missing []string
+	for _, text := range texts {
+		for _, ref := range envRef.FindAllStringSubmatch(text, -1) {
+			name := ref[1]
+			if _, ok := secrets[name]; ok || slices.Contains(missing, name) {
+				continue
+			}
+			if v, ok := os.LookupEnv(name); ok {
+				secrets[name] = v
+			} else {
+				missing = append(missing, name)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("environment variables not set: %s", strings.Join(missing, ", "))
+	}
+	return secrets, nil
+}
+
+// autoescape pipes the output of every action of the tree through the escape
+// function, as html/template does, but for values already encoded with json.
+func autoescape(tree *parse.Tree) {
+	var walk func(*parse.ListNode)
+	walk = func(list *parse.ListNode) {
+		if list == nil {
+			return
+		}
+		for _, n := range list.Nodes {
+			switch n := n.(type) {
+			case *parse.ActionNode:
+				// Assignments print nothing.
+				if len(n.Pipe.Decl) > 0 {
+					continue
+				}
+				last := n.Pipe.Cmds[len(n.Pipe.Cmds)-1]
+				if id, ok := last.Args[0].(*parse.IdentifierNode); ok && id.Ident == "json" {
+					continue
+				}
+				escape := parse.NewIdentifier("escape").SetTree(tree).SetPos(n.Pos)
+				n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{NodeType: parse.NodeCommand, Pos: n.Pos, Args: []parse.Node{escape}})
+			case *parse.IfNode:
+				walk(n.List)
+				walk(n.ElseList)
+			case *parse.RangeNode:
+				walk(n.List)
+				walk(n.ElseList)
+			case *parse.WithNode:
+				walk(n.List)
+				walk(n.ElseList)
+			}
+		}
+	}
+	if tree != nil {
+		walk(tree.Root)
+	}
+}
+
+// jsonStringEscape escapes s to be inserted in a JSON string, so that it cannot
+// end the string it is inserted in.
+func jsonStringEscape(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b[1 : len(b)-1])
+}
+
+func toJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+func (t *HTTPTool) Name() string { return t.spec.Name }
+
+func (t *HTTPTool) Schema() openai.FunctionDefinitionParam {
+	return openai.FunctionDefinitionParam{
+		Name:        t.spec.Name,
+		Description: openai.String(t.spec.Description),
+		Parameters:  t.spec.Parameters,
+	}
+}
+
+// Ping checks that the host serving the tool is reachable. Only the scheme and
+// host of the URL are used, as the rest depends on the call arguments.
+func (t *HTTPTool) Ping(ctx context.Context) error {
+	u, err := url.Parse(t.spec.URL)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("http tool %q: cannot derive host from url", t.spec.Name)
+	}
+	return ping(ctx, clientOrDefault(t.HTTPClient), u.Scheme+"://"+u.Host)
+}
+
+func (t *HTTPTool) Handle(ctx context.Context, args json.RawMessage) (string, error) {
+	var p map[string]any
+	if len(args) > 0 {
+		if err := ParseArgs(args, &p); err != nil {
+			return "", BadArgs("failed to parse tool call arguments: " + err.Error())
+		}
+	}
+
+	link, err := render(t.path, p)
+	if err != nil {
+		return "", err
+	}
+	if t.query != nil {
+		query, err := render(t.query, p)
+		if err != nil {
+			return "", err
+		}
+		link += "?" + query
+	}
+	body, err := render(t.body, p)
+	if err != nil {
+		return "", err
+	}
+
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, t.spec.Method, link, reader)
+	if err != nil {
+		return "", err
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, tmpl := range t.headers {
+		v, err := render(tmpl, p)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set(k, v)
+	}
+
+	res, err := clientOrDefault(t.HTTPClient).Do(req)
+	if err != nil {
+		return "", Upstream(t.spec.Name + " service unavailable")
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxHTTPToolResponse))
+	if err != nil {
+		return "", err
+	}
+	if res.StatusCode/100 != 2 {
+		return "", Upstream(t.spec.Name + " service returned " + res.Status)
+	}
+
+	if t.spec.Response == "" {
+		return string(data), nil
+	}
+
+	result := gjson.GetBytes(data, t.spec.Response)
+	if !result.Exists() {
+		return "", Upstream(t.spec.Name + " service returned no data")
+	}
+	return result.String(), nil
+}
+
+func render(tmpl *template.Template, data any) (string, error) {
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
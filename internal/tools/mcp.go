@@ -0,0 +1,179 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/openai/openai-go/v2"
+)
+
+// MCPServer describes an external Model Context Protocol server whose tools are
+// exposed to the assistant alongside the native ones. Exactly one of Command
+// (stdio subprocess) or URL (streamable HTTP) must be set.
+type MCPServer struct {
+	Name    string            `json:"name"`
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// LoadMCPServers reads a JSON file of the form {"servers": [...]} describing
+// the MCP servers to connect to at startup.
+func LoadMCPServers(path string) ([]MCPServer, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MCP servers file: %w", err)
+	}
+
+	var file struct {
+		Servers []MCPServer `json:"servers"`
+	}
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse MCP servers file: %w", err)
+	}
+
+	return file.Servers, nil
+}
+
+func (s MCPServer) transport() (mcp.Transport, error) {
+	switch {
+	case s.Command != "" && s.URL != "":
+		return nil, fmt.Errorf("mcp server %q: command and url are mutually exclusive", s.Name)
+	case s.Command != "":
+		cmd := exec.Command(s.Command, s.Args...)
+		cmd.Env = os.Environ()
+		for k, v := range s.Env {
+			cmd.Env = append(cmd.Env, k+"="+os.ExpandEnv(v))
+		}
+		return &mcp.CommandTransport{Command: cmd}, nil
+	case s.URL != "":
+		client := defaultClient
+		if len(s.Headers) > 0 {
+			client = &http.Client{Transport: headerTransport{headers: s.Headers, next: defaultClient.Transport}}
+		}
+		return &mcp.StreamableClientTransport{Endpoint: s.URL, HTTPClient: client}, nil
+	default:
+		return nil, fmt.Errorf("mcp server %q: either command or url is required", s.Name)
+	}
+}
+
+// headerTransport adds static headers (typically auth tokens) to every request
+// sent to an HTTP MCP server. Values may reference environment variables.
+type headerTransport struct {
+	headers map[string]string
+	next    http.RoundTripper
+}
+
+func (t headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range t.headers {
+		req.Header.Set(k, os.ExpandEnv(v))
+	}
+	return t.next.RoundTrip(req)
+}
+
+// connectMCP starts a session with the given server and lists its tools.
+func connectMCP(ctx context.Context, srv MCPServer) (*mcp.ClientSession, []*MCPTool, error) {
+	transport, err := srv.transport()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "acai-chat", Version: "v1.0.0"}, nil)
+	session, err := client.Connect(ctx, transport, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("mcp server %q: failed to connect: %w", srv.Name, err)
+	}
+
+	var out []*MCPTool
+	for t, err := range session.Tools(ctx, nil) {
+		if err != nil {
+			_ = session.Close()
+			return nil, nil, fmt.Errorf("mcp server %q: failed to list tools: %w", srv.Name, err)
+		}
+		out = append(out, &MCPTool{name: toolName(t.Name), server: srv.Name, remote: t, session: session})
+	}
+
+	return session, out, nil
+}
+
+// MCPTool proxies a tool exposed by a remote MCP server. Its name may differ
+// from the remote one, which OpenAI may not accept, or when it had to be
+// namespaced to avoid a collision.
+type MCPTool struct {
+	name    string
+	server  string
+	remote  *mcp.Tool
+	session *mcp.ClientSession
+}
+
+func (t *MCPTool) Name() string { return t.name }
+
+func (t *MCPTool) Schema() openai.FunctionDefinitionParam {
+	def := openai.FunctionDefinitionParam{Name: t.name}
+	if t.remote.Description != "" {
+		def.Description = openai.String(t.remote.Description)
+	}
+	if params, ok := t.remote.InputSchema.(map[string]any); ok {
+		def.Parameters = params
+	}
+	return def
+}
+
+// Ping checks that the MCP server session is alive.
+func (t *MCPTool) Ping(ctx context.Context) error {
+	return t.session.Ping(ctx, nil)
+}
+
+func (t *MCPTool) Handle(ctx context.Context, args json.RawMessage) (string, error) {
+	if len(args) == 0 {
+		args = json.RawMessage("{}")
+	}
+
+	res, err := t.session.CallTool(ctx, &mcp.CallToolParams{Name: t.remote.Name, Arguments: args})
+	if err != nil {
+		return "", Upstream(fmt.Sprintf("mcp server %q unavailable: %v", t.server, err))
+	}
+
+	var b strings.Builder
+	for _, c := range res.Content {
+		if text, ok := c.(*mcp.TextContent); ok {
+			if b.Len() > 0 {
+				b.WriteString("\n")
+			}
+			b.WriteString(text.Text)
+		}
+	}
+
+	if res.IsError {
+		return "", Upstream(b.String())
+	}
+	return b.String(), nil
+}
+
+var invalidToolNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// toolName turns the name of a remote tool into one OpenAI accepts: up to 64
+// letters, digits, underscores and dashes.
+func toolName(name string) string {
+	name = invalidToolNameChars.ReplaceAllString(name, "_")
+	if name == "" {
+		return "_"
+	}
+	return name[:min(len(name), 64)]
+}
+
+// namespacedName builds the fallback name used when an MCP tool collides with
+// an already registered one.
+func namespacedName(server, tool string) string {
+	return toolName(server + "_" + tool)
+}
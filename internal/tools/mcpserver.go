@@ -0,0 +1,40 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// NewMCPServer exposes every tool of the registry over the Model Context
+// Protocol, reusing each tool's Schema() for discovery and Dispatch() for calls.
+// The returned server can be run on any MCP transport (stdio, streamable HTTP).
+func NewMCPServer(reg *Registry, version string) *mcp.Server {
+	srv := mcp.NewServer(&mcp.Implementation{Name: "acai-tools", Version: version}, nil)
+
+	for _, t := range reg.Tools() {
+		schema := t.Schema()
+
+		var params any = schema.Parameters
+		if schema.Parameters == nil {
+			// MCP requires an object schema even for tools without parameters.
+			params = map[string]any{"type": "object", "properties": map[string]any{}}
+		}
+
+		srv.AddTool(&mcp.Tool{
+			Name:        schema.Name,
+			Description: schema.Description.Value,
+			InputSchema: params,
+		}, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			out, err := reg.Dispatch(ctx, req.Params.Name, json.RawMessage(req.Params.Arguments))
+			if err != nil {
+				// Tool failures are reported as results so the calling model can see them.
+				return &mcp.CallToolResult{IsError: true, Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}}}, nil
+			}
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: out}}}, nil
+		})
+	}
+
+	return srv
+}
@@ -4,9 +4,19 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/openai/openai-go/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Tool defines the interface that all tools must implement to be registered
@@ -21,7 +31,11 @@ type Tool interface {
 // Registry manages a collection of tools, providing registration, schema exposure,
 // and dispatch functionality for tool execution.
 type Registry struct {
-	byName map[string]Tool
+	// Timeout bounds each tool call, 5 seconds by default.
+	Timeout time.Duration
+
+	byName   map[string]Tool
+	sessions []*mcp.ClientSession
 }
 
 // NewRegistry creates a new tool registry with the provided tools.
@@ -32,30 +46,171 @@ func NewRegistry(ts ...Tool) *Registry {
 		m[t.Name()] = t
 	}
 	return &Registry{
-		byName: m,
+		Timeout: 5 * time.Second,
+		byName:  m,
+	}
+}
+
+// Register adds a tool to the registry. It fails if a tool with the same name
+// is already registered, so that one tool never silently shadows another.
+func (r *Registry) Register(t Tool) error {
+	if _, ok := r.byName[t.Name()]; ok {
+		return fmt.Errorf("tool %q is already registered", t.Name())
+	}
+	r.byName[t.Name()] = t
+	return nil
+}
+
+// AddMCPServers connects to each MCP server, discovers its tools and registers
+// them next to the native ones. Remote names are made valid for OpenAI, see
+// toolName. Native tools always win a name collision: the remote tool is then
+// registered as "<server>_<tool>" instead. A server that
+// cannot be reached is skipped and reported in the returned error, the rest
+// are still registered.
+func (r *Registry) AddMCPServers(ctx context.Context, servers []MCPServer) error {
+	var errs []error
+
+	for _, srv := range servers {
+		session, remote, err := connectMCP(ctx, srv)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		r.sessions = append(r.sessions, session)
+
+		for _, t := range remote {
+			if _, ok := r.byName[t.name]; ok {
+				t.name = namespacedName(srv.Name, t.remote.Name)
+				slog.WarnContext(ctx, "MCP tool name collision, using namespaced name", "server", srv.Name, "tool", t.remote.Name, "name", t.name)
+			}
+
+			if err := r.Register(t); err != nil {
+				errs = append(errs, fmt.Errorf("mcp server %q: %w", srv.Name, err))
+				continue
+			}
+		}
+
+		slog.InfoContext(ctx, "MCP server connected", "server", srv.Name, "tools", len(remote))
 	}
+
+	return errors.Join(errs...)
+}
+
+// Close terminates the sessions with all connected MCP servers.
+func (r *Registry) Close() error {
+	var errs []error
+	for _, s := range r.sessions {
+		errs = append(errs, s.Close())
+	}
+	r.sessions = nil
+	return errors.Join(errs...)
+}
+
+// Tools returns all registered tools sorted by name.
+func (r *Registry) Tools() []Tool {
+	out := make([]Tool, 0, len(r.byName))
+	for _, t := range r.byName {
+		out = append(out, t)
+	}
+	slices.SortFunc(out, func(a, b Tool) int { return strings.Compare(a.Name(), b.Name()) })
+	return out
 }
 
 // ToolsForOpenAI exposes the JSON Function schemas to the model.
-// Returns all registered tools formatted as OpenAI function definitions.
-func (r *Registry) ToolsForOpenAI() []openai.ChatCompletionToolUnionParam {
+// Returns the registered tools formatted as OpenAI function definitions, in a
+// stable order so that requests to the model are reproducible. When names are
+// given, only those tools are exposed.
+func (r *Registry) ToolsForOpenAI(names ...string) []openai.ChatCompletionToolUnionParam {
 	out := make([]openai.ChatCompletionToolUnionParam, 0, len(r.byName))
-	for _, t := range r.byName {
+	for _, t := range r.Tools() {
+		if len(names) > 0 && !slices.Contains(names, t.Name()) {
+			continue
+		}
 		out = append(out, openai.ChatCompletionFunctionTool(t.Schema()))
 	}
 	return out
 }
 
 // Dispatch handles a single tool call (by name) and returns the tool output string.
-// Executes the named tool with the provided arguments within a 5-second timeout.
+// Executes the named tool with the provided arguments within the registry timeout.
+// Every call is traced and counted, failures are classified by error kind.
 func (r *Registry) Dispatch(ctx context.Context, name string, args json.RawMessage) (string, error) {
-	t, ok := r.byName[name]
-	if !ok {
-		return "", errors.New("unknown tool: " + name)
-	}
-	cctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	attrs := []attribute.KeyValue{attribute.String("tool.name", name)}
+
+	ctx, span := tracer.Start(ctx, "tool "+name,
+		trace.WithAttributes(attrs...),
+		trace.WithAttributes(attribute.Int("tool.args.size", len(args))),
+	)
+	defer span.End()
+
+	cctx, cancel := context.WithTimeout(ctx, r.Timeout)
 	defer cancel()
-	return t.Handle(cctx, args)
+
+	start := time.Now()
+	var out string
+	var err error
+	if t, ok := r.byName[name]; ok {
+		recordUsage(ctx, name)
+		out, err = t.Handle(cctx, args)
+	} else {
+		err = fmt.Errorf("%w: %s", ErrUnknownTool, name)
+	}
+	elapsed := time.Since(start).Seconds()
+
+	toolCalls.Add(ctx, 1, metric.WithAttributes(attrs...))
+	if err != nil {
+		kind := errorKind(cctx, err)
+		span.SetAttributes(attribute.String("error.kind", kind))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		toolErrors.Add(ctx, 1, metric.WithAttributes(append(attrs, attribute.String("error.kind", kind))...))
+	}
+	toolDuration.Record(ctx, elapsed, metric.WithAttributes(append(attrs, attribute.Bool("error", err != nil))...))
+
+	return out, err
+}
+
+// Pinger is implemented by tools backed by an upstream service, to check that
+// the service is reachable.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Upstreams returns a reachability check for the upstream of each tool that
+// has one, keyed by "tool/<name>". Tools served by the same MCP server share a
+// single "mcp/<server>" check.
+func (r *Registry) Upstreams() map[string]func(context.Context) error {
+	out := make(map[string]func(context.Context) error)
+	for _, t := range r.Tools() {
+		p, ok := t.(Pinger)
+		if !ok {
+			continue
+		}
+		if m, ok := t.(*MCPTool); ok {
+			out["mcp/"+m.server] = p.Ping
+			continue
+		}
+		out["tool/"+t.Name()] = p.Ping
+	}
+	return out
+}
+
+// ping checks that an HTTP service answers at link. Any response below 500
+// counts, since probing without credentials typically yields a 401 or 404.
+func ping(ctx context.Context, client *http.Client, link string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, link, nil)
+	if err != nil {
+		return err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = res.Body.Close()
+	if res.StatusCode >= 500 {
+		return fmt.Errorf("%s returned %s", req.URL.Host, res.Status)
+	}
+	return nil
 }
 
 // ParseArgs is a helper function for parsing JSON arguments in tool implementations.
@@ -63,3 +218,17 @@ func (r *Registry) Dispatch(ctx context.Context, name string, args json.RawMessa
 func ParseArgs[T any](raw json.RawMessage, out *T) error {
 	return json.Unmarshal(raw, out)
 }
+
+func clientOrDefault(c *http.Client) *http.Client {
+	if c == nil {
+		return defaultClient
+	}
+	return c
+}
+
+func orDefault(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,305 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestRegistry_AddMCPServers(t *testing.T) {
+	ctx := context.Background()
+
+	remote := mcp.NewServer(&mcp.Implementation{Name: "remote", Version: "v1.0.0"}, nil)
+	for _, name := range []string{"get_today_date", "echo", "web.search", strings.Repeat("x", 70)} {
+		remote.AddTool(&mcp.Tool{Name: name, InputSchema: map[string]any{"type": "object"}},
+			func(_ context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return &mcp.CallToolResult{Content: []mcp.Content{
+					&mcp.TextContent{Text: req.Params.Name + ":" + string(req.Params.Arguments)},
+				}}, nil
+			})
+	}
+
+	srv := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(_ *http.Request) *mcp.Server { return remote }, nil))
+	defer srv.Close()
+
+	reg := NewRegistry(TodayTool{})
+	defer reg.Close()
+
+	if err := reg.AddMCPServers(ctx, []MCPServer{{Name: "remote", URL: srv.URL}}); err != nil {
+		t.Fatalf("AddMCPServers error: %v", err)
+	}
+
+	if got, want := len(reg.ToolsForOpenAI()), 5; got != want {
+		t.Fatalf("tools: got %d, want %d", got, want)
+	}
+
+	t.Run("remote tool is proxied", func(t *testing.T) {
+		out, err := reg.Dispatch(ctx, "echo", json.RawMessage(`{"x":1}`))
+		if err != nil {
+			t.Fatalf("Dispatch error: %v", err)
+		}
+		if want := `echo:{"x":1}`; out != want {
+			t.Fatalf("output: got %q, want %q", out, want)
+		}
+	})
+
+	t.Run("remote tool names are made valid", func(t *testing.T) {
+		out, err := reg.Dispatch(ctx, "web_search", nil)
+		if err != nil {
+			t.Fatalf("Dispatch error: %v", err)
+		}
+		if want := "web.search:{}"; out != want {
+			t.Fatalf("output: got %q, want %q", out, want)
+		}
+		if _, err := reg.Dispatch(ctx, strings.Repeat("x", 64), nil); err != nil {
+			t.Fatalf("Dispatch error for the truncated name: %v", err)
+		}
+	})
+
+	t.Run("colliding remote tool is namespaced", func(t *testing.T) {
+		out, err := reg.Dispatch(ctx, "remote_get_today_date", nil)
+		if err != nil {
+			t.Fatalf("Dispatch error: %v", err)
+		}
+		if want := "get_today_date:{}"; out != want {
+			t.Fatalf("output: got %q, want %q", out, want)
+		}
+	})
+}
+
+func TestRegistry_Dispatch_Metrics(t *testing.T) {
+	ctx := context.Background()
+
+	reader := sdkmetric.NewManualReader()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+
+	reg := NewRegistry(TodayTool{}, StockTool{})
+	_, _ = reg.Dispatch(ctx, "get_today_date", nil)
+	_, _ = reg.Dispatch(ctx, "get_stock_quote", json.RawMessage(`{}`))
+	_, err := reg.Dispatch(ctx, "get_lottery_numbers", nil)
+	if want := "unknown tool: get_lottery_numbers"; err == nil || err.Error() != want {
+		t.Fatalf("error: got %v, want %q", err, want)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatalf("Collect error: %v", err)
+	}
+
+	calls, errs := map[string]int64{}, map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok {
+				continue
+			}
+			for _, dp := range sum.DataPoints {
+				name, _ := dp.Attributes.Value("tool.name")
+				kind, _ := dp.Attributes.Value("error.kind")
+				switch m.Name {
+				case "tool.calls":
+					calls[name.AsString()] += dp.Value
+				case "tool.errors":
+					errs[name.AsString()+"/"+kind.AsString()] += dp.Value
+				}
+			}
+		}
+	}
+
+	if got, want := len(calls), 3; got != want {
+		t.Fatalf("tool.calls: got %v, want %d tools", calls, want)
+	}
+	if got, want := errs, map[string]int64{
+		"get_stock_quote/bad_args":         1,
+		"get_lottery_numbers/unknown_tool": 1,
+	}; !maps.Equal(got, want) {
+		t.Fatalf("tool.errors: got %v, want %v", got, want)
+	}
+}
+
+func TestTrackUsage(t *testing.T) {
+	ctx, used := TrackUsage(context.Background())
+
+	reg := NewRegistry(TodayTool{}, StockTool{})
+	_, _ = reg.Dispatch(ctx, "get_today_date", nil)
+	_, _ = reg.Dispatch(ctx, "get_stock_quote", json.RawMessage(`{}`))
+	_, _ = reg.Dispatch(ctx, "get_today_date", nil)
+	_, _ = reg.Dispatch(ctx, "get_lottery_numbers", nil)
+	_, _ = reg.Dispatch(context.Background(), "get_today_date", nil)
+
+	if got, want := used(), []string{"get_today_date", "get_stock_quote"}; !slices.Equal(got, want) {
+		t.Fatalf("used: got %v, want %v", got, want)
+	}
+}
+
+func TestNewMCPServer(t *testing.T) {
+	ctx := context.Background()
+
+	server := NewMCPServer(NewRegistry(TodayTool{}, StockTool{}), "test")
+	st, ct := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, st, nil); err != nil {
+		t.Fatalf("server Connect error: %v", err)
+	}
+
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test"}, nil).Connect(ctx, ct, nil)
+	if err != nil {
+		t.Fatalf("client Connect error: %v", err)
+	}
+	defer session.Close()
+
+	list, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTools error: %v", err)
+	}
+	var names []string
+	for _, tool := range list.Tools {
+		names = append(names, tool.Name)
+	}
+	if got, want := names, []string{"get_stock_quote", "get_today_date"}; !slices.Equal(got, want) {
+		t.Fatalf("tools: got %v, want %v", got, want)
+	}
+
+	t.Run("tool errors are reported as results", func(t *testing.T) {
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "get_stock_quote", Arguments: map[string]any{}})
+		if err != nil {
+			t.Fatalf("CallTool error: %v", err)
+		}
+		if !res.IsError {
+			t.Fatal("expected an error result for missing symbol")
+		}
+	})
+
+	t.Run("today date", func(t *testing.T) {
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "get_today_date"})
+		if err != nil {
+			t.Fatalf("CallTool error: %v", err)
+		}
+		if res.IsError || len(res.Content) != 1 {
+			t.Fatalf("unexpected result: %+v", res)
+		}
+	})
+}
+
+func TestHTTPTool(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("TEST_FX_TOKEN", "secret")
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.Header.Get("Authorization"), "Bearer secret"; got != want {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"base":"` + r.URL.Query().Get("base") + `","rates":{"USD":1.08}}`))
+	}))
+	defer upstream.Close()
+
+	tool, err := NewHTTPTool(HTTPToolSpec{
+		Name:     "get_exchange_rate",
+		URL:      upstream.URL + "/latest?base={{.base}}",
+		Headers:  map[string]string{"Authorization": "Bearer ${TEST_FX_TOKEN}"},
+		Response: "rates.USD",
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPTool error: %v", err)
+	}
+
+	out, err := tool.Handle(ctx, json.RawMessage(`{"base":"EUR"}`))
+	if err != nil {
+		t.Fatalf("Handle error: %v", err)
+	}
+	if want := "1.08"; out != want {
+		t.Fatalf("output: got %q, want %q", out, want)
+	}
+
+	t.Run("missing secret fails at load time", func(t *testing.T) {
+		_, err := NewHTTPTool(HTTPToolSpec{Name: "x", URL: "https://example.com/?token=${TEST_MISSING_TOKEN}"})
+		if err == nil {
+			t.Fatal("expected error for missing environment variable")
+		}
+	})
+
+	t.Run("values are escaped for where they are inserted", func(t *testing.T) {
+		t.Setenv("TEST_TEMPLATE_TOKEN", "{{.city}}")
+
+		var path, query, body string
+		echo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, _ := io.ReadAll(r.Body)
+			path, query, body = r.URL.EscapedPath(), r.URL.RawQuery, string(b)
+			_, _ = w.Write([]byte(`{}`))
+		}))
+		defer echo.Close()
+
+		tool, err := NewHTTPTool(HTTPToolSpec{
+			Name:   "search",
+			Method: "POST",
+			URL:    echo.URL + "/cities/{{.city}}?token=${TEST_TEMPLATE_TOKEN}&q={{.city}}",
+			Body:   `{"city": "{{.city}}", "tags": {{json .tags}}}`,
+		})
+		if err != nil {
+			t.Fatalf("NewHTTPTool error: %v", err)
+		}
+		if _, err := tool.Handle(ctx, json.RawMessage(`{"city":"San Sebastián\", \"admin\": true","tags":["a"]}`)); err != nil {
+			t.Fatalf("Handle error: %v", err)
+		}
+
+		if want := "/cities/San%20Sebasti%C3%A1n%22%2C%20%22admin%22:%20true"; path != want {
+			t.Fatalf("path: got %q, want %q", path, want)
+		}
+		if want := "token=%7B%7B.city%7D%7D&q=San+Sebasti%C3%A1n%22%2C+%22admin%22%3A+true"; query != want {
+			t.Fatalf("query: got %q, want %q", query, want)
+		}
+		var got map[string]any
+		if err := json.Unmarshal([]byte(body), &got); err != nil {
+			t.Fatalf("body is not JSON: %v: %s", err, body)
+		}
+		want := map[string]any{"city": `San Sebastián", "admin": true`, "tags": []any{"a"}}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Fatalf("body mismatch (-got +want):\n%s", diff)
+		}
+	})
+
+	t.Run("tools are loaded from YAML", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "tools.yaml")
+		spec := `tools:
+  - name: get_exchange_rate
+    description: Get the exchange rate
+    parameters:
+      type: object
+      properties:
+        base: {type: string}
+    url: "` + upstream.URL + `/latest?base={{.base}}"
+    headers:
+      Authorization: Bearer ${TEST_FX_TOKEN}
+    response: rates.USD
+`
+		if err := os.WriteFile(path, []byte(spec), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		loaded, err := LoadHTTPTools(path)
+		if err != nil {
+			t.Fatalf("LoadHTTPTools error: %v", err)
+		}
+		if len(loaded) != 1 {
+			t.Fatalf("tools: got %d, want 1", len(loaded))
+		}
+		out, err := loaded[0].Handle(ctx, json.RawMessage(`{"base":"EUR"}`))
+		if err != nil || out != "1.08" {
+			t.Fatalf("Handle: got %q, %v, want 1.08", out, err)
+		}
+	})
+}
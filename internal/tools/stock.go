@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
-	"os"
 	"strings"
 
 	"github.com/openai/openai-go/v2"
@@ -23,7 +22,14 @@ type stockResponse struct {
 
 // StockTool provides real-time stock market quotes for given ticker symbols
 // using the Finnhub API service.
-type StockTool struct{}
+type StockTool struct {
+	// Token authenticates with Finnhub.
+	Token string
+	// HTTPClient is used for upstream calls, a traced default client when nil.
+	HTTPClient *http.Client
+	// BaseURL overrides the Finnhub endpoint, e.g. to point it at a test server.
+	BaseURL string
+}
 
 func (StockTool) Name() string {
 	return "get_stock_quote"
@@ -46,36 +52,40 @@ func (StockTool) Schema() openai.FunctionDefinitionParam {
 	}
 }
 
-func (StockTool) Handle(ctx context.Context, args json.RawMessage) (string, error) {
+// Ping checks that Finnhub is reachable.
+func (t StockTool) Ping(ctx context.Context) error {
+	return ping(ctx, clientOrDefault(t.HTTPClient), orDefault(t.BaseURL, "https://finnhub.io/api/v1"))
+}
+
+func (t StockTool) Handle(ctx context.Context, args json.RawMessage) (string, error) {
 	var p struct {
 		Symbol string `json:"symbol"`
 	}
 	if err := ParseArgs(args, &p); err != nil || strings.TrimSpace(p.Symbol) == "" {
-		return "", errors.New("could not parse symbol")
+		return "", BadArgs("could not parse symbol")
 	}
 
-	data, err := fetchStock(ctx, p.Symbol)
+	data, err := fetchStock(ctx, clientOrDefault(t.HTTPClient), orDefault(t.BaseURL, "https://finnhub.io/api/v1"), t.Token, p.Symbol)
 	if err != nil {
-		return "", errors.New("stock service unavailable")
+		return "", Upstream("stock service unavailable")
 	}
 
 	return fmt.Sprintf("Current price for %s: $%.2f (high $%.2f, low $%.2f, open $%.2f, prev close $%.2f)",
 		strings.ToUpper(p.Symbol), data.Current, data.High, data.Low, data.Open, data.Prev), nil
 }
 
-func fetchStock(ctx context.Context, symbol string) (*stockResponse, error) {
-	token := os.Getenv("FINNHUB_TOKEN")
+func fetchStock(ctx context.Context, client *http.Client, baseURL, token, symbol string) (*stockResponse, error) {
 	if token == "" {
-		return nil, errors.New("FINNHUB_TOKEN not set")
+		return nil, errors.New("finnhub token not set")
 	}
-	url := fmt.Sprintf("https://finnhub.io/api/v1/quote?symbol=%s&token=%s",
-		url.QueryEscape(symbol), token)
+	url := fmt.Sprintf("%s/quote?symbol=%s&token=%s",
+		baseURL, url.QueryEscape(symbol), token)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
-	res, err := http.DefaultClient.Do(req)
+	res, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
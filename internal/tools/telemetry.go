@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const instrumentationName = "github.com/acai-travel/tech-challenge/internal/tools"
+
+var (
+	tracer = otel.Tracer(instrumentationName)
+	meter  = otel.Meter(instrumentationName)
+
+	toolCalls, _ = meter.Int64Counter("tool.calls",
+		metric.WithDescription("Tool calls dispatched"),
+		metric.WithUnit("{call}"))
+	toolErrors, _ = meter.Int64Counter("tool.errors",
+		metric.WithDescription("Tool calls that failed, by error kind"),
+		metric.WithUnit("{call}"))
+	toolDuration, _ = meter.Float64Histogram("tool.duration",
+		metric.WithDescription("Duration of tool calls"),
+		metric.WithUnit("s"))
+)
+
+// defaultClient is used by tools without an explicit HTTP client. Its requests
+// are traced as child spans of the tool call and propagate the trace context.
+var defaultClient = &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}
+
+// Error kinds reported in the error.kind attribute.
+const (
+	errorKindUnknownTool = "unknown_tool"
+	errorKindTimeout     = "timeout"
+	errorKindBadArgs     = "bad_args"
+	errorKindUpstream    = "upstream"
+)
+
+// errorKind classifies a Dispatch error. Ctx is the one the tool ran with, since
+// tools usually report a timeout as a generic upstream failure. Anything that is
+// not explicitly a bad arguments or unknown tool error is attributed upstream.
+func errorKind(ctx context.Context, err error) string {
+	switch {
+	case errors.Is(err, ErrUnknownTool):
+		return errorKindUnknownTool
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return errorKindTimeout
+	case errors.Is(err, ErrBadArgs):
+		return errorKindBadArgs
+	default:
+		return errorKindUpstream
+	}
+}
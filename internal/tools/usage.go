@@ -0,0 +1,39 @@
+package tools
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+type usageKey struct{}
+
+type usage struct {
+	mu    sync.Mutex
+	names []string
+}
+
+// TrackUsage returns a context recording the tools dispatched with it, and a
+// function returning their names, each once, in the order they were first
+// called.
+func TrackUsage(ctx context.Context) (context.Context, func() []string) {
+	u := new(usage)
+	return context.WithValue(ctx, usageKey{}, u), func() []string {
+		u.mu.Lock()
+		defer u.mu.Unlock()
+		return slices.Clone(u.names)
+	}
+}
+
+func recordUsage(ctx context.Context, name string) {
+	u, ok := ctx.Value(usageKey{}).(*usage)
+	if !ok {
+		return
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if !slices.Contains(u.names, name) {
+		u.names = append(u.names, name)
+	}
+}
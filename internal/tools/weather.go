@@ -3,11 +3,9 @@ package tools
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
-	"os"
 	"strings"
 	"time"
 
@@ -55,7 +53,14 @@ type response struct {
 
 // WeatherTool provides current weather conditions and a 3-day forecast
 // for any given location using the WeatherAPI service.
-type WeatherTool struct{}
+type WeatherTool struct {
+	// APIKey authenticates with WeatherAPI.
+	APIKey string
+	// HTTPClient is used for upstream calls, a traced default client when nil.
+	HTTPClient *http.Client
+	// BaseURL overrides the WeatherAPI endpoint, e.g. to point it at a test server.
+	BaseURL string
+}
 
 func (WeatherTool) Name() string { return "get_weather" }
 
@@ -75,16 +80,21 @@ func (WeatherTool) Schema() openai.FunctionDefinitionParam {
 	}
 }
 
-func (WeatherTool) Handle(ctx context.Context, args json.RawMessage) (string, error) {
+// Ping checks that WeatherAPI is reachable.
+func (t WeatherTool) Ping(ctx context.Context) error {
+	return ping(ctx, clientOrDefault(t.HTTPClient), orDefault(t.BaseURL, "https://api.weatherapi.com/v1"))
+}
+
+func (t WeatherTool) Handle(ctx context.Context, args json.RawMessage) (string, error) {
 	var p struct {
 		Location string `json:"location"`
 	}
 	if err := ParseArgs(args, &p); err != nil {
-		return "", errors.New("could not parse location")
+		return "", BadArgs("could not parse location")
 	}
-	w, err := fetchWeather(ctx, p.Location)
+	w, err := fetchWeather(ctx, clientOrDefault(t.HTTPClient), orDefault(t.BaseURL, "https://api.weatherapi.com/v1"), t.APIKey, p.Location)
 	if err != nil {
-		return "", errors.New("weather service unavailable")
+		return "", Upstream("weather service unavailable")
 	}
 	var b strings.Builder
 	fmt.Fprintf(&b, "%s: %.1f°C, %s, wind %.1f km/h\n", w.Location, w.TempC, w.Condition, w.WindKph)
@@ -97,13 +107,12 @@ func (WeatherTool) Handle(ctx context.Context, args json.RawMessage) (string, er
 }
 
 // fetchWeather fetches current conditions and a 3-day forecast for a given location.
-func fetchWeather(ctx context.Context, location string) (*weather, error) {
-	apiKey := os.Getenv("WEATHER_API_KEY")
-	url := fmt.Sprintf("https://api.weatherapi.com/v1/forecast.json?key=%s&q=%s&days=3",
-		apiKey, url.QueryEscape(location))
+func fetchWeather(ctx context.Context, client *http.Client, baseURL, apiKey, location string) (*weather, error) {
+	url := fmt.Sprintf("%s/forecast.json?key=%s&q=%s&days=3",
+		baseURL, apiKey, url.QueryEscape(location))
 
 	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	res, err := http.DefaultClient.Do(req)
+	res, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
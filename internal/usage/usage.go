@@ -0,0 +1,93 @@
+// Package usage accounts for the OpenAI tokens consumed on behalf of a request,
+// including by the work it leaves running in the background, so that they can
+// be charged to the client that made it.
+package usage
+
+import (
+	"context"
+	"sync"
+)
+
+type tokensKey struct{}
+
+// Tokens accumulates the tokens consumed for a request until it is settled:
+// once the request is done and the work it left in the background released
+// its holds.
+type Tokens struct {
+	mu      sync.Mutex
+	tokens  int64
+	holds   int
+	closed  bool
+	settled bool
+	charge  func(tokens int64)
+}
+
+// WithTokens returns a context accumulating the tokens reported with AddTokens.
+func WithTokens(ctx context.Context) (context.Context, *Tokens) {
+	t := new(Tokens)
+	return context.WithValue(ctx, tokensKey{}, t), t
+}
+
+// AddTokens records tokens consumed for the request in ctx. It does nothing
+// outside of WithTokens.
+func AddTokens(ctx context.Context, tokens int64) {
+	t, ok := ctx.Value(tokensKey{}).(*Tokens)
+	if !ok {
+		return
+	}
+
+	t.mu.Lock()
+	if !t.settled {
+		t.tokens += tokens
+		t.mu.Unlock()
+		return
+	}
+	t.mu.Unlock()
+	t.charge(tokens)
+}
+
+// Hold keeps the request in ctx from being settled until release is called, for
+// work it leaves running in the background. It must be called before the
+// request is done.
+func Hold(ctx context.Context) (release func()) {
+	t, ok := ctx.Value(tokensKey{}).(*Tokens)
+	if !ok {
+		return func() {}
+	}
+
+	t.mu.Lock()
+	t.holds++
+	t.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			t.mu.Lock()
+			t.holds--
+			t.settle()
+		})
+	}
+}
+
+// Close marks the request as done. Charge is called with the tokens it consumed
+// once no hold is left, then with any reported afterwards.
+func (t *Tokens) Close(charge func(tokens int64)) {
+	t.mu.Lock()
+	t.closed = true
+	t.charge = charge
+	t.settle()
+}
+
+// settle charges the tokens once the request is done and no longer held. It is
+// called with mu held, and releases it.
+func (t *Tokens) settle() {
+	if !t.closed || t.holds > 0 || t.settled {
+		t.mu.Unlock()
+		return
+	}
+	t.settled = true
+	tokens := t.tokens
+	t.mu.Unlock()
+
+	t.charge(tokens)
+}
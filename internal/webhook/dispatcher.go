@@ -0,0 +1,313 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// Store keeps the deliveries that failed, while they are retried and once they
+// failed every attempt, until replayed.
+type Store interface {
+	AddFailedDelivery(ctx context.Context, d *model.FailedDelivery) error
+	GetFailedDelivery(ctx context.Context, id string) (*model.FailedDelivery, error)
+	ListFailedDeliveries(ctx context.Context, subscriptionID string, limit int) ([]*model.FailedDelivery, error)
+	UpdateFailedDelivery(ctx context.Context, d *model.FailedDelivery) error
+	DeleteFailedDelivery(ctx context.Context, id primitive.ObjectID) error
+}
+
+var defaultClient = &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}
+
+// Dispatcher delivers events to the subscriptions wanting them. A delivery is
+// attempted up to MaxAttempts times, waiting Backoff after the first failure
+// and twice as long after each next one, up to MaxBackoff. Deliveries are stored once they fail,
+// to be retried in the background, and kept if they fail every attempt so that
+// they can be replayed.
+type Dispatcher struct {
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+	// Timeout bounds each attempt.
+	Timeout time.Duration
+	// HTTPClient sends the deliveries, a traced default client when nil.
+	HTTPClient *http.Client
+
+	subs  []Subscription
+	store Store
+
+	deliveries sync.WaitGroup
+	stop       chan struct{}
+	stopOnce   sync.Once
+}
+
+func NewDispatcher(store Store, subs ...Subscription) *Dispatcher {
+	return &Dispatcher{
+		MaxAttempts: 5,
+		Backoff:     time.Second,
+		MaxBackoff:  time.Hour,
+		Timeout:     10 * time.Second,
+		subs:        subs,
+		store:       store,
+		stop:        make(chan struct{}),
+	}
+}
+
+// Subscriptions returns the subscriptions notified of events.
+func (d *Dispatcher) Subscriptions() []Subscription {
+	return d.subs
+}
+
+// Deliver makes the first attempt at delivering e to each subscription wanting
+// it. Deliveries that fail are stored, then retried in the background. It
+// returns once every delivery either succeeded or was stored, so that none is
+// lost if the server stops meanwhile, and fails if one could not be stored.
+func (d *Dispatcher) Deliver(ctx context.Context, e Event) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encoding webhook event: %w", err)
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, sub := range d.subs {
+		if !sub.Wants(e.Type) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := d.deliver(ctx, sub, e, payload); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// deliver attempts to deliver e to sub once. On failure, the delivery is stored
+// before being retried in the background.
+func (d *Dispatcher) deliver(ctx context.Context, sub Subscription, e Event, payload []byte) error {
+	err := d.send(ctx, sub, e.ID, e.Type, payload)
+	if err == nil {
+		return nil
+	}
+	slog.WarnContext(ctx, "Webhook delivery failed", "error", err, "subscription", sub.ID, "event_type", e.Type, "attempt", 1)
+
+	now := time.Now()
+	failed := &model.FailedDelivery{
+		SubscriptionID: sub.ID,
+		EventID:        e.ID,
+		EventType:      string(e.Type),
+		Payload:        payload,
+		Attempts:       1,
+		LastError:      err.Error(),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	retry := failed.Attempts < d.MaxAttempts
+	var wait time.Duration
+	if retry {
+		wait = d.backoff(failed.Attempts)
+		failed.RetryingUntil = d.retryingUntil(wait)
+	}
+	if err := d.store.AddFailedDelivery(ctx, failed); err != nil {
+		return fmt.Errorf("storing failed delivery to %s: %w", sub.ID, err)
+	}
+
+	if retry {
+		// Retries outlive the caller, but keep its trace and log attributes.
+		ctx = context.WithoutCancel(ctx)
+
+		d.deliveries.Add(1)
+		go func() {
+			defer d.deliveries.Done()
+			d.retry(ctx, sub, failed, wait)
+		}()
+	}
+	return nil
+}
+
+// retry attempts a stored delivery after waiting wait, and again until it
+// succeeds, and is then forgotten, or fails MaxAttempts times.
+func (d *Dispatcher) retry(ctx context.Context, sub Subscription, failed *model.FailedDelivery, wait time.Duration) {
+	for {
+		if !d.sleep(wait) {
+			failed.LastError = "stopped before retrying: " + failed.LastError
+			break
+		}
+
+		failed.Attempts++
+		err := d.send(ctx, sub, failed.EventID, EventType(failed.EventType), failed.Payload)
+		if err == nil {
+			if err := d.store.DeleteFailedDelivery(ctx, failed.ID); err != nil {
+				slog.ErrorContext(ctx, "Failed to forget delivered webhook", "error", err, "subscription", sub.ID, "event_id", failed.EventID)
+			}
+			return
+		}
+		slog.WarnContext(ctx, "Webhook delivery failed", "error", err, "subscription", sub.ID, "event_type", failed.EventType, "attempt", failed.Attempts)
+		failed.LastError = err.Error()
+		if failed.Attempts >= d.MaxAttempts {
+			break
+		}
+
+		wait = d.backoff(failed.Attempts)
+		failed.RetryingUntil = d.retryingUntil(wait)
+		failed.UpdatedAt = time.Now()
+		if err := d.store.UpdateFailedDelivery(ctx, failed); err != nil {
+			slog.ErrorContext(ctx, "Failed to update failed webhook delivery", "error", err, "subscription", sub.ID, "event_id", failed.EventID)
+		}
+	}
+
+	failed.RetryingUntil = time.Time{}
+	failed.UpdatedAt = time.Now()
+	if err := d.store.UpdateFailedDelivery(ctx, failed); err != nil {
+		slog.ErrorContext(ctx, "Failed to update failed webhook delivery", "error", err, "subscription", sub.ID, "event_id", failed.EventID)
+	}
+}
+
+// retryingUntil returns until when a delivery retried after wait is reserved for
+// the retry: long enough for the attempt to be made and recorded. A delivery
+// left by a server that stopped can be replayed past it.
+func (d *Dispatcher) retryingUntil(wait time.Duration) time.Time {
+	return time.Now().Add(wait + 2*d.Timeout)
+}
+
+// backoff returns the wait after the given failed attempt: exponential up to
+// MaxBackoff, with jitter so that retries to a recovering endpoint are spread
+// out.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	wait := d.Backoff
+	for range attempts - 1 {
+		if wait > d.MaxBackoff/2 {
+			wait = d.MaxBackoff
+			break
+		}
+		wait *= 2
+	}
+	wait = min(wait, d.MaxBackoff)
+	return wait + rand.N(wait/2+1)
+}
+
+// sleep waits for the given duration, or returns false once Close was called.
+func (d *Dispatcher) sleep(wait time.Duration) bool {
+	t := time.NewTimer(wait)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return true
+	case <-d.stop:
+		return false
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, sub Subscription, eventID string, eventType EventType, payload []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(eventType))
+	req.Header.Set(DeliveryHeader, eventID)
+	req.Header.Set(SignatureHeader, Sign(sub.Secret, time.Now(), payload))
+
+	client := d.HTTPClient
+	if client == nil {
+		client = defaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", res.Status)
+	}
+	return nil
+}
+
+// FailedDeliveries lists the most recent deliveries that failed every attempt,
+// to the given subscription if not empty. Deliveries still being retried are
+// left out.
+func (d *Dispatcher) FailedDeliveries(ctx context.Context, subscriptionID string, limit int) ([]*model.FailedDelivery, error) {
+	return d.store.ListFailedDeliveries(ctx, subscriptionID, limit)
+}
+
+// Replay attempts a failed delivery once more. It is forgotten on success.
+// Deliveries still being retried cannot be replayed.
+func (d *Dispatcher) Replay(ctx context.Context, id string) error {
+	failed, err := d.store.GetFailedDelivery(ctx, id)
+	if err != nil {
+		return err
+	}
+	if time.Now().Before(failed.RetryingUntil) {
+		return twirp.NewError(twirp.FailedPrecondition, "delivery is still being retried")
+	}
+
+	var sub *Subscription
+	for i := range d.subs {
+		if d.subs[i].ID == failed.SubscriptionID {
+			sub = &d.subs[i]
+		}
+	}
+	if sub == nil {
+		return twirp.NewError(twirp.FailedPrecondition, "subscription no longer exists")
+	}
+
+	if err := d.send(ctx, *sub, failed.EventID, EventType(failed.EventType), failed.Payload); err != nil {
+		failed.Attempts++
+		failed.LastError = err.Error()
+		failed.UpdatedAt = time.Now()
+		if uerr := d.store.UpdateFailedDelivery(context.WithoutCancel(ctx), failed); uerr != nil {
+			slog.ErrorContext(ctx, "Failed to update failed webhook delivery", "error", uerr)
+		}
+		return twirp.NewError(twirp.Unavailable, "delivery failed: "+err.Error())
+	}
+
+	return d.store.DeleteFailedDelivery(context.WithoutCancel(ctx), failed.ID)
+}
+
+// Close waits for the retries in progress until ctx is done. Deliveries still
+// waiting to be retried then are left stored as failed.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		d.deliveries.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	d.stopOnce.Do(func() { close(d.stop) })
+	<-done
+	return ctx.Err()
+}
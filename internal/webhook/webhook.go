@@ -0,0 +1,136 @@
+// Package webhook notifies external systems of conversation events, by POSTing
+// signed JSON payloads to the subscribed endpoints.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers sent with every delivery.
+const (
+	EventHeader     = "X-Acai-Event"
+	DeliveryHeader  = "X-Acai-Delivery"
+	SignatureHeader = "X-Acai-Signature"
+)
+
+type EventType string
+
+const (
+	ConversationCreated EventType = "conversation.created"
+	TitleChanged        EventType = "conversation.title_changed"
+	ConversationDeleted EventType = "conversation.deleted"
+	ReplyCreated        EventType = "reply.created"
+	ReplyFailed         EventType = "reply.failed"
+)
+
+// Event is the payload delivered to subscriptions. Data holds the conversation,
+// or the message for reply events, as returned by the API.
+type Event struct {
+	ID             string          `json:"id"`
+	Type           EventType       `json:"type"`
+	Time           time.Time       `json:"time"`
+	ConversationID string          `json:"conversation_id"`
+	Data           json.RawMessage `json:"data"`
+}
+
+// Subscription is an endpoint notified of events of the given types, or of all
+// of them when Events is empty. Payloads are signed with Secret.
+type Subscription struct {
+	ID     string      `json:"id"`
+	URL    string      `json:"url"`
+	Secret string      `json:"secret"`
+	Events []EventType `json:"events,omitempty"`
+}
+
+// Wants reports whether the subscription is notified of events of type t.
+func (s Subscription) Wants(t EventType) bool {
+	return len(s.Events) == 0 || slices.Contains(s.Events, t)
+}
+
+// LoadSubscriptions reads subscriptions from a JSON file. Secrets may reference
+// environment variables, e.g. "${CRM_WEBHOOK_SECRET}".
+func LoadSubscriptions(path string) ([]Subscription, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhooks file: %w", err)
+	}
+
+	var file struct {
+		Subscriptions []Subscription `json:"subscriptions"`
+	}
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse webhooks file: %w", err)
+	}
+
+	seen := map[string]bool{}
+	for i := range file.Subscriptions {
+		s := &file.Subscriptions[i]
+		s.Secret = os.ExpandEnv(s.Secret)
+
+		switch {
+		case s.ID == "":
+			return nil, fmt.Errorf("webhook subscription %d: id is required", i)
+		case seen[s.ID]:
+			return nil, fmt.Errorf("webhook subscription %q: duplicate id", s.ID)
+		case !strings.HasPrefix(s.URL, "http://") && !strings.HasPrefix(s.URL, "https://"):
+			return nil, fmt.Errorf("webhook subscription %q: url must be http or https", s.ID)
+		case s.Secret == "":
+			return nil, fmt.Errorf("webhook subscription %q: secret is required", s.ID)
+		}
+		seen[s.ID] = true
+	}
+
+	return file.Subscriptions, nil
+}
+
+// Sign returns the signature header of a payload sent at t, in the form
+// "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<unix seconds>.<payload>">".
+func Sign(secret string, t time.Time, payload []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + mac(secret, ts, payload)
+}
+
+// Verify checks a signature header produced by Sign, rejecting signatures older
+// than tolerance to prevent replays. Receivers can use it as a reference.
+func Verify(secret, header string, payload []byte, tolerance time.Duration) error {
+	var ts, sig string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(part, "=")
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			sig = v
+		}
+	}
+
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || sig == "" {
+		return errors.New("malformed signature")
+	}
+	if !hmac.Equal([]byte(sig), []byte(mac(secret, ts, payload))) {
+		return errors.New("signature mismatch")
+	}
+	if age := time.Since(time.Unix(sec, 0)); age > tolerance || age < -tolerance {
+		return errors.New("signature expired")
+	}
+	return nil
+}
+
+func mac(secret, ts string, payload []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(ts))
+	h.Write([]byte("."))
+	h.Write(payload)
+	return hex.EncodeToString(h.Sum(nil))
+}
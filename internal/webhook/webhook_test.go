@@ -0,0 +1,241 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/acai-travel/tech-challenge/internal/chat/model"
+	"github.com/twitchtv/twirp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryStore struct {
+	mu     sync.Mutex
+	failed map[string]*model.FailedDelivery
+	err    error
+}
+
+func (m *memoryStore) AddFailedDelivery(_ context.Context, d *model.FailedDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	d.ID = primitive.NewObjectID()
+	m.failed[d.ID.Hex()] = d
+	return nil
+}
+
+func (m *memoryStore) GetFailedDelivery(_ context.Context, id string) (*model.FailedDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if d, ok := m.failed[id]; ok {
+		return d, nil
+	}
+	return nil, twirp.NotFoundError("delivery not found")
+}
+
+func (m *memoryStore) ListFailedDeliveries(_ context.Context, _ string, _ int) ([]*model.FailedDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []*model.FailedDelivery
+	for _, d := range m.failed {
+		if !time.Now().Before(d.RetryingUntil) {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+func (m *memoryStore) UpdateFailedDelivery(_ context.Context, d *model.FailedDelivery) error {
+	return nil
+}
+
+func (m *memoryStore) DeleteFailedDelivery(_ context.Context, id primitive.ObjectID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.failed, id.Hex())
+	return nil
+}
+
+// endpoint serves a webhook receiver answering with the status returned by fn,
+// after checking the signature.
+func endpoint(t *testing.T, secret string, fn func(e Event) int) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, _ := io.ReadAll(r.Body)
+		if err := Verify(secret, r.Header.Get(SignatureHeader), payload, time.Minute); err != nil {
+			t.Errorf("signature: %v", err)
+		}
+
+		var e Event
+		if err := json.Unmarshal(payload, &e); err != nil {
+			t.Errorf("payload: %v", err)
+		}
+		if got, want := r.Header.Get(EventHeader), string(e.Type); got != want {
+			t.Errorf("event header: got %q, want %q", got, want)
+		}
+		w.WriteHeader(fn(e))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestDispatcher_Retries(t *testing.T) {
+	var calls atomic.Int32
+	srv := endpoint(t, "s3cret", func(Event) int {
+		if calls.Add(1) < 3 {
+			return http.StatusServiceUnavailable
+		}
+		return http.StatusNoContent
+	})
+
+	store := &memoryStore{failed: map[string]*model.FailedDelivery{}}
+	d := NewDispatcher(store,
+		Subscription{ID: "crm", URL: srv.URL, Secret: "s3cret"},
+		Subscription{ID: "analytics", URL: srv.URL, Secret: "s3cret", Events: []EventType{TitleChanged}},
+	)
+	d.Backoff = time.Millisecond
+
+	if err := d.Deliver(context.Background(), Event{ID: "1", Type: ReplyCreated, Data: json.RawMessage(`{}`)}); err != nil {
+		t.Fatalf("Deliver error: %v", err)
+	}
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+
+	if got, want := calls.Load(), int32(3); got != want {
+		t.Fatalf("calls: got %d, want %d", got, want)
+	}
+	if got := len(store.failed); got != 0 {
+		t.Fatalf("failed deliveries: got %d, want 0", got)
+	}
+}
+
+func TestDispatcher_Unstored(t *testing.T) {
+	srv := endpoint(t, "s3cret", func(Event) int { return http.StatusServiceUnavailable })
+
+	store := &memoryStore{failed: map[string]*model.FailedDelivery{}, err: errors.New("database down")}
+	d := NewDispatcher(store, Subscription{ID: "crm", URL: srv.URL, Secret: "s3cret"})
+
+	// The event must not be acknowledged, as nothing would retry the delivery.
+	if err := d.Deliver(context.Background(), Event{ID: "1", Type: ReplyCreated, Data: json.RawMessage(`{}`)}); err == nil {
+		t.Fatal("expected error when the failed delivery cannot be stored")
+	}
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+}
+
+func TestDispatcher_Backoff(t *testing.T) {
+	d := NewDispatcher(&memoryStore{})
+	d.Backoff, d.MaxBackoff = time.Second, time.Minute
+
+	for _, attempts := range []int{1, 7, 64, 100} {
+		want := min(time.Second<<min(attempts-1, 6), time.Minute)
+		if got := d.backoff(attempts); got < want || got > want+want/2 {
+			t.Fatalf("backoff(%d): got %s, want %s plus up to half of it", attempts, got, want)
+		}
+	}
+}
+
+func TestDispatcher_Replay(t *testing.T) {
+	var up atomic.Bool
+	srv := endpoint(t, "s3cret", func(Event) int {
+		if up.Load() {
+			return http.StatusOK
+		}
+		return http.StatusInternalServerError
+	})
+
+	store := &memoryStore{failed: map[string]*model.FailedDelivery{}}
+	d := NewDispatcher(store, Subscription{ID: "crm", URL: srv.URL, Secret: "s3cret"})
+	d.MaxAttempts = 2
+	d.Backoff = time.Millisecond
+
+	if err := d.Deliver(context.Background(), Event{ID: "1", Type: ConversationCreated, Data: json.RawMessage(`{}`)}); err != nil {
+		t.Fatalf("Deliver error: %v", err)
+	}
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+
+	failed, _ := d.FailedDeliveries(context.Background(), "", 10)
+	if len(failed) != 1 {
+		t.Fatalf("failed deliveries: got %d, want 1", len(failed))
+	}
+	if got, want := failed[0].Attempts, 2; got != want {
+		t.Fatalf("attempts: got %d, want %d", got, want)
+	}
+
+	id := failed[0].ID.Hex()
+	if err := d.Replay(context.Background(), id); err == nil {
+		t.Fatal("expected replay to fail while the endpoint is down")
+	}
+
+	up.Store(true)
+	if err := d.Replay(context.Background(), id); err != nil {
+		t.Fatalf("Replay error: %v", err)
+	}
+	if got := len(store.failed); got != 0 {
+		t.Fatalf("failed deliveries after replay: got %d, want 0", got)
+	}
+}
+
+func TestDispatcher_Replay_Retrying(t *testing.T) {
+	srv := endpoint(t, "s3cret", func(Event) int { return http.StatusInternalServerError })
+
+	store := &memoryStore{failed: map[string]*model.FailedDelivery{}}
+	d := NewDispatcher(store, Subscription{ID: "crm", URL: srv.URL, Secret: "s3cret"})
+	d.Backoff = time.Hour
+
+	if err := d.Deliver(context.Background(), Event{ID: "1", Type: ConversationCreated, Data: json.RawMessage(`{}`)}); err != nil {
+		t.Fatalf("Deliver error: %v", err)
+	}
+
+	// The delivery is left to the retry waiting in the background.
+	if failed, _ := d.FailedDeliveries(context.Background(), "", 10); len(failed) != 0 {
+		t.Fatalf("failed deliveries while retrying: got %d, want 0", len(failed))
+	}
+	var id string
+	for key := range store.failed {
+		id = key
+	}
+	err := d.Replay(context.Background(), id)
+	if te, ok := err.(twirp.Error); !ok || te.Code() != twirp.FailedPrecondition {
+		t.Fatalf("replay while retrying: got %v, want FailedPrecondition", err)
+	}
+
+	// Retries given up when stopping can be replayed.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = d.Close(ctx)
+	if failed, _ := d.FailedDeliveries(context.Background(), "", 10); len(failed) != 1 {
+		t.Fatalf("failed deliveries once stopped: got %d, want 1", len(failed))
+	}
+}
+
+func TestVerify(t *testing.T) {
+	payload := []byte(`{"id":"1"}`)
+	header := Sign("s3cret", time.Now(), payload)
+
+	if err := Verify("s3cret", header, payload, time.Minute); err != nil {
+		t.Fatalf("valid signature: %v", err)
+	}
+	if err := Verify("other", header, payload, time.Minute); err == nil {
+		t.Fatal("expected error with the wrong secret")
+	}
+	if err := Verify("s3cret", header, []byte(`{"id":"2"}`), time.Minute); err == nil {
+		t.Fatal("expected error with a tampered payload")
+	}
+	if err := Verify("s3cret", Sign("s3cret", time.Now().Add(-time.Hour), payload), payload, time.Minute); err == nil {
+		t.Fatal("expected error with an old signature")
+	}
+}